@@ -0,0 +1,200 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/selectel/craas-go/pkg/config"
+	"github.com/selectel/craas-go/pkg/metrics"
+)
+
+// opts represents command-line options of the exporter.
+type opts struct {
+	listen           string
+	interval         time.Duration
+	profile          string
+	config           string
+	token            string
+	endpoint         string
+	timeout          time.Duration
+	skipGarbageSize  bool
+	skipRepositories bool
+	textfile         string
+}
+
+func parseOpts(args []string) (*opts, error) {
+	o := &opts{}
+	fs := flag.NewFlagSet("craas-exporter", flag.ContinueOnError)
+	fs.StringVar(&o.listen, "listen", ":9701", "address to serve metrics on")
+	fs.DurationVar(&o.interval, "interval", time.Minute, "interval between collections")
+	fs.StringVar(&o.profile, "profile", "", "profile of the configuration file (env CRAAS_PROFILE)")
+	fs.StringVar(&o.config, "config", "", "path of the configuration file (env CRAAS_CONFIG)")
+	fs.StringVar(&o.token, "token", "", "project token used to access the API (env CRAAS_TOKEN)")
+	fs.StringVar(&o.endpoint, "endpoint", "", "API endpoint without a version (env CRAAS_ENDPOINT)")
+	fs.DurationVar(&o.timeout, "timeout", 0, "HTTP request timeout")
+	fs.BoolVar(&o.skipGarbageSize, "skip-garbage-size", false, "don't request garbage sizes")
+	fs.BoolVar(&o.skipRepositories, "skip-repositories", false, "don't request repositories")
+	fs.StringVar(&o.textfile, "textfile", "", "collect once, write metrics to the node-exporter textfile and exit")
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	if fs.NArg() > 0 {
+		return nil, errors.New("unexpected arguments")
+	}
+	if o.interval <= 0 {
+		return nil, errors.New("interval must be positive")
+	}
+
+	return o, nil
+}
+
+// newCollector builds a collector of the selected profile.
+// The client is shared by all collections, it requests the token from
+// the profile source for every request, so reissued tokens are picked up.
+func newCollector(ctx context.Context, o *opts) (*metrics.Collector, error) {
+	var cfg *config.Config
+	var err error
+	if o.config != "" {
+		cfg, err = config.Load(o.config)
+	} else {
+		cfg, err = config.LoadDefault()
+	}
+	if err != nil {
+		return nil, err
+	}
+	session, err := cfg.Select(o.profile, &config.Overrides{
+		Token:    o.token,
+		Endpoint: o.endpoint,
+		Timeout:  o.timeout,
+	})
+	if err != nil {
+		return nil, err
+	}
+	client, err := session.ClientV1(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return metrics.NewCollector(client, &metrics.CollectorOpts{
+		SkipGarbageSize:  o.skipGarbageSize,
+		SkipRepositories: o.skipRepositories,
+	}), nil
+}
+
+func run(ctx context.Context, o *opts) error {
+	collector, err := newCollector(ctx, o)
+	if err != nil {
+		return err
+	}
+
+	if o.textfile != "" {
+		return writeTextfile(ctx, collector, o.textfile)
+	}
+
+	e := &exporter{collector: collector}
+	e.collect(ctx)
+	go e.loop(ctx, o.interval)
+
+	server := &http.Server{
+		Addr:              o.listen,
+		Handler:           e.handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = server.Shutdown(shutdownCtx)
+	}()
+
+	log.Printf("serving metrics on %s", o.listen)
+	if err := server.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+
+	return nil
+}
+
+// writeTextfile collects metrics once and writes them to the textfile.
+// The file is written even if registries can't be listed, so that craas_up
+// and error counters are exposed, but the error is reported to the caller.
+func writeTextfile(ctx context.Context, collector *metrics.Collector, path string) error {
+	snapshot := collector.Collect(ctx)
+	if err := snapshot.WriteTextFile(path); err != nil {
+		return err
+	}
+	if !snapshot.Up {
+		return errors.New("unable to list registries")
+	}
+
+	return nil
+}
+
+// exporter serves the latest rendered collection.
+type exporter struct {
+	collector *metrics.Collector
+
+	mu   sync.RWMutex
+	body []byte
+}
+
+func (e *exporter) loop(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			e.collect(ctx)
+		}
+	}
+}
+
+func (e *exporter) collect(ctx context.Context) {
+	snapshot := e.collector.Collect(ctx)
+	var buf bytes.Buffer
+	if err := snapshot.WriteText(&buf); err != nil {
+		log.Printf("unable to render metrics: %v", err)
+
+		return
+	}
+	if !snapshot.Up {
+		log.Print("unable to list registries")
+	}
+
+	e.mu.Lock()
+	e.body = buf.Bytes()
+	e.mu.Unlock()
+}
+
+func (e *exporter) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, _ *http.Request) {
+		e.mu.RLock()
+		body := e.body
+		e.mu.RUnlock()
+
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		_, _ = w.Write(body)
+	})
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+
+			return
+		}
+		_, _ = w.Write([]byte("<html><body><a href=\"/metrics\">Metrics</a></body></html>\n"))
+	})
+
+	return mux
+}
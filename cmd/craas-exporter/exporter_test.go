@@ -0,0 +1,148 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/selectel/craas-go/pkg/testutils"
+)
+
+func TestParseOpts(t *testing.T) {
+	o, err := parseOpts([]string{"--listen", ":9999", "--interval", "5m", "--skip-repositories"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if o.listen != ":9999" || o.interval.Minutes() != 5 || !o.skipRepositories {
+		t.Fatalf("unexpected options: %#v", o)
+	}
+	if _, err := parseOpts([]string{"--interval", "0s"}); err == nil {
+		t.Fatal("expected an error for a zero interval")
+	}
+}
+
+func TestExporterServesMetrics(t *testing.T) {
+	t.Setenv("CRAAS_CONFIG", "")
+	t.Setenv("CRAAS_PROFILE", "")
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	testEnv := testutils.SetupTestEnv()
+	defer testEnv.TearDownTestEnv()
+	testEnv.Mux.HandleFunc("/api/v1/registries", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Content-Type", "application/json")
+		fmt.Fprint(w, `[{"id": "888af692-c646-4b76-a234-81ca9b5bcafe", "name": "test-registry", "size": 1024, "status": "ACTIVE"}]`)
+	})
+
+	o, err := parseOpts([]string{
+		"--token", testutils.TokenID,
+		"--endpoint", testEnv.Server.URL + "/api",
+		"--skip-garbage-size", "--skip-repositories",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	collector, err := newCollector(context.Background(), o)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := &exporter{collector: collector}
+	e.collect(context.Background())
+
+	server := httptest.NewServer(e.handler())
+	defer server.Close()
+	resp, err := http.Get(server.URL + "/metrics")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `craas_registry_size_bytes{id="888af692-c646-4b76-a234-81ca9b5bcafe",registry="test-registry"} 1024`
+	if !strings.Contains(string(body), expected) {
+		t.Fatalf("expected %s in %s", expected, body)
+	}
+}
+
+func TestExporterReissuedToken(t *testing.T) {
+	t.Setenv("CRAAS_TOKEN", "")
+	t.Setenv("CRAAS_PROFILE", "")
+
+	var tokens []string
+	testEnv := testutils.SetupTestEnv()
+	defer testEnv.TearDownTestEnv()
+	testEnv.Mux.HandleFunc("/api/v1/registries", func(w http.ResponseWriter, r *http.Request) {
+		tokens = append(tokens, r.Header.Get("X-Auth-Token"))
+		w.Header().Add("Content-Type", "application/json")
+		fmt.Fprint(w, `[]`)
+	})
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	data := "profiles:\n  default:\n    token:\n      env: CRAAS_TEST_TOKEN\n"
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	o, err := parseOpts([]string{
+		"--config", path,
+		"--endpoint", testEnv.Server.URL + "/api",
+		"--skip-garbage-size", "--skip-repositories",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("CRAAS_TEST_TOKEN", "first-token")
+	collector, err := newCollector(context.Background(), o)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := &exporter{collector: collector}
+	for _, token := range []string{"first-token", "second-token"} {
+		t.Setenv("CRAAS_TEST_TOKEN", token)
+		e.collect(context.Background())
+	}
+
+	if len(tokens) != 2 || tokens[0] != "first-token" || tokens[1] != "second-token" {
+		t.Fatalf("expected the reissued token in the second collection, but got %#v", tokens)
+	}
+}
+
+func TestTextfileMode(t *testing.T) {
+	t.Setenv("CRAAS_CONFIG", "")
+	t.Setenv("CRAAS_PROFILE", "")
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	testEnv := testutils.SetupTestEnv()
+	defer testEnv.TearDownTestEnv()
+	testEnv.Mux.HandleFunc("/api/v1/registries", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+
+	path := filepath.Join(t.TempDir(), "craas.prom")
+	o, err := parseOpts([]string{
+		"--token", testutils.TokenID,
+		"--endpoint", testEnv.Server.URL + "/api",
+		"--textfile", path,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := run(context.Background(), o); err == nil {
+		t.Fatal("expected an error when registries can't be listed")
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "craas_up 0\n") {
+		t.Fatalf("expected craas_up 0 in %s", data)
+	}
+}
@@ -0,0 +1,45 @@
+// Command craas-exporter exposes registry usage of a CRaaS project as
+// Prometheus metrics.
+//
+// The exporter periodically lists registries, their garbage sizes and
+// repositories and serves the latest collection at /metrics:
+//
+//	craas-exporter --listen :9701 --interval 5m --profile prod
+//
+// Hosts without an extra HTTP listener can run it from cron in the one-shot
+// mode, which writes the node-exporter textfile collector format atomically:
+//
+//	craas-exporter --textfile /var/lib/node_exporter/textfile/craas.prom
+//
+// Connection settings are read from flags, the CRAAS_TOKEN, CRAAS_ENDPOINT
+// and CRAAS_PROFILE environment variables and the configuration file of
+// the config package.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	opts, err := parseOpts(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err == nil {
+		err = run(ctx, opts)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "craas-exporter:", err)
+		stop()
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"io"
+	"time"
+
+	"github.com/selectel/craas-go/pkg/config"
+	"github.com/selectel/craas-go/pkg/format"
+	clientv1 "github.com/selectel/craas-go/pkg/v1/client"
+	clientv2 "github.com/selectel/craas-go/pkg/v2/client"
+)
+
+// globalOpts represents settings shared by all commands.
+type globalOpts struct {
+	token    string
+	endpoint string
+	profile  string
+	config   string
+	timeout  time.Duration
+
+	output    string
+	template  string
+	columns   string
+	sortBy    string
+	noHeaders bool
+}
+
+// app holds the command tree, global settings and API clients.
+type app struct {
+	root   *command
+	opts   globalOpts
+	out    io.Writer
+	errOut io.Writer
+
+	session  *config.Session
+	clientV1 *clientv1.ServiceClient
+	clientV2 *clientv2.ServiceClient
+}
+
+func newApp(out, errOut io.Writer) *app {
+	a := &app{
+		out:    out,
+		errOut: errOut,
+	}
+	a.root = &command{
+		name: "craas",
+		subcommands: []*command{
+			registriesCommand(),
+			reposCommand(),
+			imagesCommand(),
+			gcCommand(),
+			tokensCommand(),
+			planCommand(),
+			applyCommand(),
+			snapshotCommand(),
+			historyCommand(),
+			eventsCommand(),
+			quotaCommand(),
+			capacityCommand(),
+			completionCommand(),
+		},
+	}
+
+	return a
+}
+
+// run executes the command line.
+func (a *app) run(ctx context.Context, args []string) error {
+	if len(args) > 0 && args[0] == completeCommandName {
+		a.printCandidates(ctx, args[1:])
+
+		return nil
+	}
+
+	return a.root.execute(ctx, a, a.root.name, args)
+}
+
+// registerFlags registers global flags on the leaf command flag set.
+func (a *app) registerFlags(fs *flag.FlagSet) {
+	fs.StringVar(&a.opts.token, "token", "", "project token used to access the API (env CRAAS_TOKEN)")
+	fs.StringVar(&a.opts.endpoint, "endpoint", "", "API endpoint without a version (env CRAAS_ENDPOINT)")
+	fs.StringVar(&a.opts.profile, "profile", "", "profile of the configuration file (env CRAAS_PROFILE)")
+	fs.StringVar(&a.opts.config, "config", "", "path of the configuration file (env CRAAS_CONFIG)")
+	fs.DurationVar(&a.opts.timeout, "timeout", 0, "HTTP request timeout")
+	fs.StringVar(&a.opts.output, "o", "table", "output format: table, json, yaml, csv or template")
+	fs.StringVar(&a.opts.template, "template", "", "Go template rendered for every item with -o template")
+	fs.StringVar(&a.opts.columns, "columns", "", "comma-separated columns to render")
+	fs.StringVar(&a.opts.sortBy, "sort-by", "", "column to sort by, prefix it with - for descending order")
+	fs.BoolVar(&a.opts.noHeaders, "no-headers", false, "don't print table and CSV headers")
+}
+
+// init selects the profile of the configuration file, flags take precedence
+// over the environment variables and the profile.
+func (a *app) init() error {
+	if _, err := format.ParseFormat(a.opts.output); err != nil {
+		return err
+	}
+
+	var cfg *config.Config
+	var err error
+	if a.opts.config != "" {
+		cfg, err = config.Load(a.opts.config)
+	} else {
+		cfg, err = config.LoadDefault()
+	}
+	if err != nil {
+		return err
+	}
+	a.session, err = cfg.Select(a.opts.profile, &config.Overrides{
+		Token:    a.opts.token,
+		Endpoint: a.opts.endpoint,
+		Timeout:  a.opts.timeout,
+	})
+
+	return err
+}
+
+// v1 returns the v1 API client.
+func (a *app) v1(ctx context.Context) (*clientv1.ServiceClient, error) {
+	if a.clientV1 == nil {
+		c, err := a.session.ClientV1(ctx)
+		if err != nil {
+			return nil, err
+		}
+		a.clientV1 = c
+	}
+
+	return a.clientV1, nil
+}
+
+// v2 returns the v2 API client.
+func (a *app) v2(ctx context.Context) (*clientv2.ServiceClient, error) {
+	if a.clientV2 == nil {
+		c, err := a.session.ClientV2(ctx)
+		if err != nil {
+			return nil, err
+		}
+		a.clientV2 = c
+	}
+
+	return a.clientV2, nil
+}
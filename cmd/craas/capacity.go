@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+
+	"github.com/selectel/craas-go/pkg/v1/capacity"
+)
+
+// capacityColumns are default columns of capacity checks.
+var capacityColumns = []string{"registry", "repository", "imageSize", "existingSize", "uploadSize", "free", "fits"}
+
+func capacityCommand() *command {
+	return &command{
+		name:    "capacity",
+		summary: "check whether images fit into registries",
+		subcommands: []*command{
+			{name: "check", summary: "check that an image fits before pushing it", args: "REGISTRY REPOSITORY", setup: capacityCheck, complete: completeRepositories},
+		},
+	}
+}
+
+func capacityCheck(fs *flag.FlagSet) runFunc {
+	imagePath := fs.String("image", "", "OCI layout directory or docker save tarball of the image")
+	var size, margin sizeValue
+	fs.Var(&size, "size", "image size, for example 1.5GiB, if there is no local image")
+	fs.Var(&margin, "margin", "space that must stay free after the push, for example 100MiB")
+
+	return func(ctx context.Context, a *app, args []string) error {
+		if err := expectArgs(args, "REGISTRY", "REPOSITORY"); err != nil {
+			return err
+		}
+
+		var image *capacity.Image
+		switch {
+		case *imagePath != "" && size != 0:
+			return errors.New("--image and --size can't be used together")
+		case *imagePath != "":
+			loaded, err := capacity.Load(*imagePath)
+			if err != nil {
+				return err
+			}
+			image = loaded
+		case size != 0:
+			image = capacity.ImageOfSize(int64(size))
+		default:
+			return errors.New("either --image or --size is required")
+		}
+
+		r, err := a.resolveRegistry(ctx, args[0])
+		if err != nil {
+			return err
+		}
+		c, err := a.v1(ctx)
+		if err != nil {
+			return err
+		}
+		result, err := capacity.Check(ctx, c, r.ID, args[1], image, &capacity.Opts{Margin: int64(margin)})
+		if result != nil {
+			if printErr := a.print(result, capacityColumns...); printErr != nil {
+				return printErr
+			}
+		}
+
+		return err
+	}
+}
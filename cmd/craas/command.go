@@ -0,0 +1,205 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/selectel/craas-go/pkg/format"
+)
+
+// errUsage is returned when the usage has already been printed.
+var errUsage = errors.New("invalid usage")
+
+// exitError sets a specific exit code of the command.
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string {
+	return e.err.Error()
+}
+
+func (e *exitError) Unwrap() error {
+	return e.err
+}
+
+// runFunc runs a leaf command with positional arguments.
+type runFunc func(ctx context.Context, a *app, args []string) error
+
+// command represents a node of the command tree.
+type command struct {
+	name    string
+	summary string
+
+	// args describes positional arguments of a leaf command.
+	args string
+
+	// setup registers flags of a leaf command and returns its runner.
+	setup func(fs *flag.FlagSet) runFunc
+
+	// local commands don't call the API and skip the profile selection.
+	local bool
+
+	// complete returns completion candidates of the next positional argument
+	// after the arguments already typed.
+	complete func(ctx context.Context, a *app, args []string) []string
+
+	subcommands []*command
+}
+
+// find returns the subcommand by name.
+func (c *command) find(name string) *command {
+	for _, sub := range c.subcommands {
+		if sub.name == name {
+			return sub
+		}
+	}
+
+	return nil
+}
+
+// printUsage prints subcommands of the node.
+func (c *command) printUsage(w io.Writer, path string) {
+	if c.setup != nil {
+		fmt.Fprintf(w, "Usage: %s [flags] %s\n", path, c.args)
+
+		return
+	}
+	fmt.Fprintf(w, "Usage: %s <command>\n\nCommands:\n", path)
+	subs := make([]*command, len(c.subcommands))
+	copy(subs, c.subcommands)
+	sort.Slice(subs, func(i, j int) bool { return subs[i].name < subs[j].name })
+	for _, sub := range subs {
+		fmt.Fprintf(w, "  %-12s %s\n", sub.name, sub.summary)
+	}
+}
+
+// execute walks the command tree and runs the leaf command.
+func (c *command) execute(ctx context.Context, a *app, path string, args []string) error {
+	if c.setup != nil {
+		fs := flag.NewFlagSet(path, flag.ContinueOnError)
+		fs.SetOutput(a.errOut)
+		a.registerFlags(fs)
+		run := c.setup(fs)
+		fs.Usage = func() {
+			c.printUsage(a.errOut, path)
+			fs.PrintDefaults()
+		}
+		if err := fs.Parse(interleaveFlags(fs, args)); err != nil {
+			if errors.Is(err, flag.ErrHelp) {
+				return nil
+			}
+
+			return errUsage
+		}
+		if !c.local {
+			if err := a.init(); err != nil {
+				return err
+			}
+		}
+
+		return run(ctx, a, fs.Args())
+	}
+
+	if len(args) == 0 || args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		c.printUsage(a.errOut, path)
+		if len(args) == 0 {
+			return errUsage
+		}
+
+		return nil
+	}
+	sub := c.find(args[0])
+	if sub == nil {
+		fmt.Fprintf(a.errOut, "unknown command %q\n", args[0])
+		c.printUsage(a.errOut, path)
+
+		return errUsage
+	}
+
+	return sub.execute(ctx, a, path+" "+sub.name, args[1:])
+}
+
+// interleaveFlags moves flags after positional arguments to the front,
+// so that "craas repos list my-registry -o json" works as expected.
+func interleaveFlags(fs *flag.FlagSet, args []string) []string {
+	flags := make([]string, 0, len(args))
+	positional := make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			positional = append(positional, args[i+1:]...)
+
+			break
+		}
+		if !strings.HasPrefix(arg, "-") || arg == "-" {
+			positional = append(positional, arg)
+
+			continue
+		}
+		flags = append(flags, arg)
+		name := strings.TrimLeft(arg, "-")
+		if strings.Contains(name, "=") {
+			continue
+		}
+		// Non-boolean flags consume the next argument as their value.
+		if f := fs.Lookup(name); f != nil && !isBoolFlag(f) && i+1 < len(args) {
+			i++
+			flags = append(flags, args[i])
+		}
+	}
+
+	return append(flags, positional...)
+}
+
+func isBoolFlag(f *flag.Flag) bool {
+	b, ok := f.Value.(interface{ IsBoolFlag() bool })
+
+	return ok && b.IsBoolFlag()
+}
+
+// stringList is a repeatable string flag.
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(v string) error {
+	*l = append(*l, v)
+
+	return nil
+}
+
+// sizeValue is a size flag accepting units like "5GiB".
+type sizeValue int64
+
+func (s *sizeValue) String() string {
+	return strconv.FormatInt(int64(*s), 10)
+}
+
+func (s *sizeValue) Set(v string) error {
+	size, err := format.ParseSize(v)
+	if err != nil {
+		return err
+	}
+	*s = sizeValue(size)
+
+	return nil
+}
+
+// expectArgs checks the number of positional arguments.
+func expectArgs(args []string, names ...string) error {
+	if len(args) != len(names) {
+		return fmt.Errorf("expected arguments: %s", strings.Join(names, " "))
+	}
+
+	return nil
+}
@@ -0,0 +1,338 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/selectel/craas-go/pkg/config"
+	"github.com/selectel/craas-go/pkg/format"
+	"github.com/selectel/craas-go/pkg/v1/registry"
+	"github.com/selectel/craas-go/pkg/v1/repository"
+)
+
+const (
+	// completeCommandName is a hidden command the completion scripts call.
+	completeCommandName = "__complete"
+
+	// completionCacheTTL is a lifetime of cached resource names.
+	completionCacheTTL = 30 * time.Second
+)
+
+const bashCompletion = `# bash completion for %[1]s
+_%[1]s_completion() {
+    local IFS=$'\n'
+    COMPREPLY=($(%[1]s %[2]s "${COMP_WORDS[@]:1:COMP_CWORD}" 2>/dev/null))
+}
+complete -o default -F _%[1]s_completion %[1]s
+`
+
+const zshCompletion = `#compdef %[1]s
+_%[1]s() {
+    local -a candidates
+    candidates=(${(f)"$(%[1]s %[2]s "${(@)words[2,$CURRENT]}" 2>/dev/null)"})
+    compadd -- "${candidates[@]}"
+}
+compdef _%[1]s %[1]s
+`
+
+const fishCompletion = `# fish completion for %[1]s
+function __%[1]s_complete
+    set -l tokens (commandline -opc) (commandline -ct)
+    %[1]s %[2]s $tokens[2..-1] 2>/dev/null
+end
+complete -c %[1]s -f -a '(__%[1]s_complete)'
+`
+
+func completionCommand() *command {
+	return &command{
+		name:     "completion",
+		summary:  "print a shell completion script",
+		args:     "bash|zsh|fish",
+		local:    true,
+		setup:    completionScript,
+		complete: completeShells,
+	}
+}
+
+func completionScript(_ *flag.FlagSet) runFunc {
+	return func(_ context.Context, a *app, args []string) error {
+		if err := expectArgs(args, "SHELL"); err != nil {
+			return err
+		}
+		var script string
+		switch args[0] {
+		case "bash":
+			script = bashCompletion
+		case "zsh":
+			script = zshCompletion
+		case "fish":
+			script = fishCompletion
+		default:
+			return fmt.Errorf("unsupported shell %q, use bash, zsh or fish", args[0])
+		}
+		_, err := fmt.Fprintf(a.out, script, a.root.name, completeCommandName)
+
+		return err
+	}
+}
+
+func completeShells(_ context.Context, _ *app, args []string) []string {
+	if len(args) > 0 {
+		return nil
+	}
+
+	return []string{"bash", "fish", "zsh"}
+}
+
+// printCandidates prints completion candidates of the last word, one per line.
+// Errors are never reported, so that the shell isn't cluttered.
+func (a *app) printCandidates(ctx context.Context, words []string) {
+	for _, candidate := range a.candidates(ctx, words) {
+		fmt.Fprintln(a.out, candidate)
+	}
+}
+
+// candidates returns completion candidates of the last word.
+func (a *app) candidates(ctx context.Context, words []string) []string {
+	current := ""
+	if len(words) > 0 {
+		current = words[len(words)-1]
+		words = words[:len(words)-1]
+	}
+
+	c := a.root
+	for len(words) > 0 && c.setup == nil {
+		sub := c.find(words[0])
+		if sub == nil {
+			return nil
+		}
+		c = sub
+		words = words[1:]
+	}
+	if c.setup == nil {
+		names := make([]string, 0, len(c.subcommands))
+		for _, sub := range c.subcommands {
+			names = append(names, sub.name)
+		}
+
+		return filterPrefix(names, current)
+	}
+
+	fs := flag.NewFlagSet(c.name, flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	a.registerFlags(fs)
+	c.setup(fs)
+
+	if strings.HasPrefix(current, "-") {
+		var names []string
+		fs.VisitAll(func(f *flag.Flag) {
+			names = append(names, "--"+f.Name)
+		})
+
+		return filterPrefix(names, current)
+	}
+	if len(words) > 0 {
+		if values, ok := a.flagValues(fs, words[len(words)-1]); ok {
+			return filterPrefix(values, current)
+		}
+	}
+
+	if c.complete == nil || fs.Parse(interleaveFlags(fs, words)) != nil {
+		return nil
+	}
+	if !c.local {
+		if err := a.init(); err != nil {
+			return nil
+		}
+	}
+
+	return filterPrefix(c.complete(ctx, a, fs.Args()), current)
+}
+
+// flagValues returns candidates of a flag value when the word is a flag
+// waiting for its value.
+func (a *app) flagValues(fs *flag.FlagSet, word string) ([]string, bool) {
+	if !strings.HasPrefix(word, "-") || strings.Contains(word, "=") {
+		return nil, false
+	}
+	f := fs.Lookup(strings.TrimLeft(word, "-"))
+	if f == nil || isBoolFlag(f) {
+		return nil, false
+	}
+
+	switch f.Name {
+	case "o":
+		formats := format.Formats()
+		values := make([]string, 0, len(formats))
+		for _, f := range formats {
+			values = append(values, string(f))
+		}
+
+		return values, true
+	case "profile":
+		cfg, err := config.LoadDefault()
+		if err != nil {
+			return nil, true
+		}
+
+		return cfg.ProfileNames(), true
+	default:
+		return nil, true
+	}
+}
+
+func filterPrefix(values []string, prefix string) []string {
+	filtered := make([]string, 0, len(values))
+	for _, v := range values {
+		if strings.HasPrefix(v, prefix) {
+			filtered = append(filtered, v)
+		}
+	}
+	sort.Strings(filtered)
+
+	return filtered
+}
+
+func completeRegistries(ctx context.Context, a *app, args []string) []string {
+	if len(args) > 0 {
+		return nil
+	}
+
+	return a.registryNames(ctx)
+}
+
+func completeRepositories(ctx context.Context, a *app, args []string) []string {
+	switch len(args) {
+	case 0:
+		return a.registryNames(ctx)
+	case 1:
+		return a.repositoryNames(ctx, args[0])
+	default:
+		return nil
+	}
+}
+
+func completeImages(ctx context.Context, a *app, args []string) []string {
+	switch len(args) {
+	case 0:
+		return a.registryNames(ctx)
+	case 1:
+		return a.repositoryNames(ctx, args[0])
+	case 2:
+		return a.tagNames(ctx, args[0], args[1])
+	default:
+		return nil
+	}
+}
+
+func (a *app) registryNames(ctx context.Context) []string {
+	return a.cachedNames([]string{"registries"}, func() ([]string, error) {
+		c, err := a.v1(ctx)
+		if err != nil {
+			return nil, err
+		}
+		registries, _, err := registry.List(ctx, c)
+		if err != nil {
+			return nil, err
+		}
+		names := make([]string, 0, len(registries))
+		for _, r := range registries {
+			names = append(names, r.Name)
+		}
+
+		return names, nil
+	})
+}
+
+func (a *app) repositoryNames(ctx context.Context, registryName string) []string {
+	return a.cachedNames([]string{"repositories", registryName}, func() ([]string, error) {
+		r, err := a.resolveRegistry(ctx, registryName)
+		if err != nil {
+			return nil, err
+		}
+		c, err := a.v1(ctx)
+		if err != nil {
+			return nil, err
+		}
+		repositories, _, err := repository.ListRepositories(ctx, c, r.ID)
+		if err != nil {
+			return nil, err
+		}
+		names := make([]string, 0, len(repositories))
+		for _, repo := range repositories {
+			names = append(names, repo.Name)
+		}
+
+		return names, nil
+	})
+}
+
+func (a *app) tagNames(ctx context.Context, registryName, repositoryName string) []string {
+	return a.cachedNames([]string{"tags", registryName, repositoryName}, func() ([]string, error) {
+		r, err := a.resolveRegistry(ctx, registryName)
+		if err != nil {
+			return nil, err
+		}
+		c, err := a.v1(ctx)
+		if err != nil {
+			return nil, err
+		}
+		tags, _, err := repository.ListTags(ctx, c, r.ID, repositoryName)
+
+		return tags, err
+	})
+}
+
+// cacheEntry represents a cached list of resource names.
+type cacheEntry struct {
+	ExpiresAt time.Time `json:"expiresAt"`
+	Names     []string  `json:"names"`
+}
+
+// cachedNames returns names from the local cache or fetches and caches them.
+// The cache is keyed by the session, so that profiles and projects don't mix.
+func (a *app) cachedNames(key []string, fetch func() ([]string, error)) []string {
+	path := a.cachePath(key)
+	if path != "" {
+		if data, err := os.ReadFile(path); err == nil {
+			var entry cacheEntry
+			if json.Unmarshal(data, &entry) == nil && time.Now().Before(entry.ExpiresAt) {
+				return entry.Names
+			}
+		}
+	}
+
+	names, err := fetch()
+	if err != nil {
+		return nil
+	}
+	if path != "" {
+		data, err := json.Marshal(&cacheEntry{ExpiresAt: time.Now().Add(completionCacheTTL), Names: names})
+		if err == nil && os.MkdirAll(filepath.Dir(path), 0o700) == nil {
+			_ = os.WriteFile(path, data, 0o600)
+		}
+	}
+
+	return names
+}
+
+// cachePath returns a path of the cache file, it's empty when caching isn't possible.
+func (a *app) cachePath(key []string) string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(strings.Join(append([]string{a.session.CacheKey()}, key...), "\x00")))
+
+	return filepath.Join(dir, "craas", "completion", hex.EncodeToString(sum[:])+".json")
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/selectel/craas-go/pkg/testutils"
+)
+
+func completeTestApp(t *testing.T, words ...string) []string {
+	t.Helper()
+
+	var out bytes.Buffer
+	a := newApp(&out, &bytes.Buffer{})
+	if err := a.run(context.Background(), append([]string{completeCommandName}, words...)); err != nil {
+		t.Fatal(err)
+	}
+
+	return strings.Fields(out.String())
+}
+
+func TestCompleteCommands(t *testing.T) {
+	expected := []string{"registries", "repos"}
+	if actual := completeTestApp(t, "re"); !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("expected %#v, but got %#v", expected, actual)
+	}
+	expected = []string{"--sort-by"}
+	if actual := completeTestApp(t, "images", "list", "--sort"); !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("expected %#v, but got %#v", expected, actual)
+	}
+	expected = []string{"yaml"}
+	if actual := completeTestApp(t, "registries", "list", "-o", "y"); !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("expected %#v, but got %#v", expected, actual)
+	}
+}
+
+func TestCompleteResourcesWithCache(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Setenv("CRAAS_CONFIG", "")
+	t.Setenv("CRAAS_PROFILE", "")
+
+	registriesCalls, tagsCalls := 0, 0
+	testEnv := testutils.SetupTestEnv()
+	defer testEnv.TearDownTestEnv()
+
+	testEnv.Mux.HandleFunc("/api/v1/registries", func(w http.ResponseWriter, r *http.Request) {
+		registriesCalls++
+		w.Header().Add("Content-Type", "application/json")
+		fmt.Fprint(w, testRegistries)
+	})
+	testEnv.Mux.HandleFunc("/api/v1/registries/888af692-c646-4b76-a234-81ca9b5bcafe/repositories/alpine/tags", func(w http.ResponseWriter, r *http.Request) {
+		tagsCalls++
+		w.Header().Add("Content-Type", "application/json")
+		fmt.Fprint(w, `["3.15", "3.16", "latest"]`)
+	})
+	t.Setenv("CRAAS_TOKEN", testutils.TokenID)
+	t.Setenv("CRAAS_ENDPOINT", testEnv.Server.URL+"/api")
+
+	expected := []string{"test-registry"}
+	if actual := completeTestApp(t, "images", "delete", "t"); !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("expected %#v, but got %#v", expected, actual)
+	}
+
+	expected = []string{"3.15", "3.16"}
+	for i := 0; i < 2; i++ {
+		if actual := completeTestApp(t, "images", "delete", "test-registry", "alpine", "3."); !reflect.DeepEqual(expected, actual) {
+			t.Fatalf("expected %#v, but got %#v", expected, actual)
+		}
+	}
+	if registriesCalls != 2 || tagsCalls != 1 {
+		t.Fatalf("expected cached tags, but got %d registries and %d tags calls", registriesCalls, tagsCalls)
+	}
+}
+
+func TestCompletionScript(t *testing.T) {
+	var out bytes.Buffer
+	a := newApp(&out, &bytes.Buffer{})
+	if err := a.run(context.Background(), []string{"completion", "bash"}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "craas __complete") {
+		t.Fatalf("unexpected bash script: %s", out.String())
+	}
+	if err := a.run(context.Background(), []string{"completion", "tcsh"}); err == nil {
+		t.Fatal("expected an error for an unsupported shell")
+	}
+}
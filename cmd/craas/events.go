@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+
+	clientv2 "github.com/selectel/craas-go/pkg/v2/client"
+	"github.com/selectel/craas-go/pkg/watch"
+	"github.com/selectel/craas-go/pkg/webhook"
+)
+
+func eventsCommand() *command {
+	return &command{name: "events", summary: "stream changes of registries, images and tokens", setup: eventsRun}
+}
+
+func eventsRun(fs *flag.FlagSet) runFunc {
+	opts := &watch.Opts{}
+	fs.DurationVar(&opts.Interval, "interval", watch.DefaultInterval, "interval between polls")
+	fs.StringVar(&opts.StatePath, "state", "", "file to resume watching from")
+	fs.BoolVar(&opts.SkipImages, "skip-images", false, "don't list images")
+	fs.DurationVar(&opts.ExpiryWarning, "expiry-warning", watch.DefaultExpiryWarning, "report tokens expiring within this time")
+	skipTokens := fs.Bool("skip-tokens", false, "don't list tokens")
+	hooks := webhookFlags(fs)
+
+	return func(ctx context.Context, a *app, args []string) error {
+		if err := expectArgs(args); err != nil {
+			return err
+		}
+		c1, err := a.v1(ctx)
+		if err != nil {
+			return err
+		}
+		var c2 *clientv2.ServiceClient
+		if !*skipTokens {
+			if c2, err = a.v2(ctx); err != nil {
+				return err
+			}
+		}
+		opts.OnError = func(err error) {
+			fmt.Fprintln(a.errOut, "craas:", err)
+		}
+		watcher, err := watch.NewWatcher(c1, c2, opts)
+		if err != nil {
+			return err
+		}
+
+		encoder := json.NewEncoder(a.out)
+		for event := range watcher.Watch(ctx) {
+			if err := hooks.notify(ctx, webhook.FromEvent(event)); err != nil {
+				opts.OnError(err)
+			}
+			if a.opts.output == "json" {
+				if err := encoder.Encode(event); err != nil {
+					return err
+				}
+
+				continue
+			}
+			fmt.Fprintln(a.out, eventLine(event))
+		}
+
+		return nil
+	}
+}
+
+// eventLine formats the event as a single line.
+func eventLine(event *watch.Event) string {
+	fields := []string{event.Time.Format(time.RFC3339), string(event.Type)}
+	if event.RegistryName != "" {
+		subject := event.RegistryName
+		if event.Repository != "" {
+			subject += "/" + event.Repository
+		}
+		if event.Tag != "" {
+			subject += ":" + event.Tag
+		}
+		fields = append(fields, subject)
+	}
+	switch event.Type {
+	case watch.EventRegistryStatusChanged:
+		fields = append(fields, string(event.PreviousStatus)+" -> "+string(event.Status))
+	case watch.EventTagMoved:
+		fields = append(fields, event.PreviousDigest+" -> "+event.Digest)
+	case watch.EventImagePushed, watch.EventImageDeleted:
+		fields = append(fields, event.Digest)
+		if len(event.Tags) > 0 {
+			fields = append(fields, strings.Join(event.Tags, ","))
+		}
+	case watch.EventTokenExpiringSoon:
+		fields = append(fields, event.TokenName, event.ExpiresAt.Format(time.RFC3339))
+	}
+
+	return strings.Join(fields, " ")
+}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"time"
+
+	"github.com/selectel/craas-go/pkg/v1/gc"
+	"github.com/selectel/craas-go/pkg/v1/registry"
+	"github.com/selectel/craas-go/pkg/webhook"
+)
+
+func gcCommand() *command {
+	return &command{
+		name:    "gc",
+		summary: "manage garbage collection",
+		subcommands: []*command{
+			{name: "start", summary: "start a garbage collection", args: "REGISTRY", setup: gcStart, complete: completeRegistries},
+			{name: "size", summary: "show the garbage size", args: "REGISTRY", setup: gcSize, complete: completeRegistries},
+			{name: "preview", summary: "list untagged images a garbage collection would delete", args: "REGISTRY", setup: gcPreview, complete: completeRegistries},
+			{name: "run", summary: "start a garbage collection and wait for it to finish", args: "REGISTRY", setup: gcRun, complete: completeRegistries},
+		},
+	}
+}
+
+// untaggedImage represents a row of the "gc preview" command.
+type untaggedImage struct {
+	Repository string    `json:"repository"`
+	Digest     string    `json:"digest"`
+	Size       int64     `json:"size"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+func gcStart(fs *flag.FlagSet) runFunc {
+	deleteUntagged := fs.Bool("delete-untagged", false, "delete untagged images")
+
+	return func(ctx context.Context, a *app, args []string) error {
+		if err := expectArgs(args, "REGISTRY"); err != nil {
+			return err
+		}
+		r, err := a.resolveRegistry(ctx, args[0])
+		if err != nil {
+			return err
+		}
+		c, err := a.v1(ctx)
+		if err != nil {
+			return err
+		}
+		if _, err := gc.StartGarbageCollection(ctx, c, r.ID, &gc.StartGCOpts{DeleteUntagged: *deleteUntagged}); err != nil {
+			return err
+		}
+		a.printDone("garbage collection of registry %s started", r.Name)
+
+		return nil
+	}
+}
+
+func gcSize(_ *flag.FlagSet) runFunc {
+	return func(ctx context.Context, a *app, args []string) error {
+		if err := expectArgs(args, "REGISTRY"); err != nil {
+			return err
+		}
+		r, err := a.resolveRegistry(ctx, args[0])
+		if err != nil {
+			return err
+		}
+		c, err := a.v1(ctx)
+		if err != nil {
+			return err
+		}
+		size, _, err := gc.GetGarbageSize(ctx, c, r.ID)
+		if err != nil {
+			return err
+		}
+
+		return a.print(size)
+	}
+}
+
+func gcPreview(_ *flag.FlagSet) runFunc {
+	return func(ctx context.Context, a *app, args []string) error {
+		if err := expectArgs(args, "REGISTRY"); err != nil {
+			return err
+		}
+		r, err := a.resolveRegistry(ctx, args[0])
+		if err != nil {
+			return err
+		}
+		c, err := a.v1(ctx)
+		if err != nil {
+			return err
+		}
+		preview, err := gc.PreviewUntagged(ctx, c, r.ID)
+		if err != nil {
+			return err
+		}
+		images := make([]untaggedImage, 0, preview.ImagesCount)
+		for _, repo := range preview.Repositories {
+			for _, image := range repo.Images {
+				images = append(images, untaggedImage{
+					Repository: repo.Name,
+					Digest:     image.Digest,
+					Size:       image.Size,
+					CreatedAt:  image.CreatedAt,
+				})
+			}
+		}
+
+		return a.print(images)
+	}
+}
+
+// gcResult represents a result of the "gc run" command.
+type gcResult struct {
+	Registry   string          `json:"registry"`
+	Status     registry.Status `json:"status"`
+	SizeBefore int64           `json:"sizeBefore"`
+	SizeAfter  int64           `json:"sizeAfter"`
+	Freed      int64           `json:"freed"`
+}
+
+func gcRun(fs *flag.FlagSet) runFunc {
+	deleteUntagged := fs.Bool("delete-untagged", false, "delete untagged images")
+	interval := fs.Duration("interval", 5*time.Second, "polling interval")
+	timeout := fs.Duration("wait-timeout", 30*time.Minute, "maximum time to wait")
+	hooks := webhookFlags(fs)
+
+	return func(ctx context.Context, a *app, args []string) error {
+		if err := expectArgs(args, "REGISTRY"); err != nil {
+			return err
+		}
+		r, err := a.resolveRegistry(ctx, args[0])
+		if err != nil {
+			return err
+		}
+		c, err := a.v1(ctx)
+		if err != nil {
+			return err
+		}
+		if _, err := gc.StartGarbageCollection(ctx, c, r.ID, &gc.StartGCOpts{DeleteUntagged: *deleteUntagged}); err != nil {
+			return err
+		}
+
+		// Give the registry a chance to leave the ACTIVE status first.
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(*interval):
+		}
+		after, err := a.waitRegistryStatus(ctx, r.ID, registry.StatusActive, *interval, *timeout)
+		if err != nil {
+			return err
+		}
+
+		result := &gcResult{
+			Registry:   r.Name,
+			Status:     after.Status,
+			SizeBefore: r.Size,
+			SizeAfter:  after.Size,
+			Freed:      r.Size - after.Size,
+		}
+		if err := hooks.notify(ctx, webhook.GCFinished(after, r.Size, time.Now())); err != nil {
+			return err
+		}
+
+		return a.print(result)
+	}
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/selectel/craas-go/pkg/v1/taghistory"
+)
+
+// defaultHistoryStore is a default file of tag records.
+const defaultHistoryStore = "tag-history.jsonl"
+
+// historyColumns are default columns of tag records.
+var historyColumns = []string{"time", "tag", "digest"}
+
+func historyCommand() *command {
+	return &command{
+		name:    "history",
+		summary: "record and query tag movements",
+		subcommands: []*command{
+			{name: "watch", summary: "periodically record digests tags point to", args: "[REGISTRY [REPOSITORY]]", setup: historyWatch, complete: completeRepositories},
+			{name: "show", summary: "show the history of a tag", args: "REGISTRY REPOSITORY TAG", setup: historyShow, complete: completeImages},
+			{name: "at", summary: "show the digest a tag pointed to at a time", args: "REGISTRY REPOSITORY TAG TIME", setup: historyAt, complete: completeImages},
+		},
+	}
+}
+
+// historyStoreFlag registers the flag of the store file.
+func historyStoreFlag(fs *flag.FlagSet) *string {
+	return fs.String("store", defaultHistoryStore, "file of tag records")
+}
+
+func historyWatch(fs *flag.FlagSet) runFunc {
+	storePath := historyStoreFlag(fs)
+	interval := fs.Duration("interval", taghistory.DefaultInterval, "interval between polls")
+	once := fs.Bool("once", false, "poll once and exit")
+
+	return func(ctx context.Context, a *app, args []string) error {
+		if len(args) > 2 {
+			return expectArgs(args, "[REGISTRY [REPOSITORY]]")
+		}
+		c, err := a.v1(ctx)
+		if err != nil {
+			return err
+		}
+		var targets []taghistory.Target
+		if len(args) > 0 {
+			r, err := a.resolveRegistry(ctx, args[0])
+			if err != nil {
+				return err
+			}
+			target := taghistory.Target{RegistryID: r.ID}
+			if len(args) > 1 {
+				target.Repository = args[1]
+			}
+			targets = append(targets, target)
+		}
+
+		store, err := taghistory.Open(*storePath)
+		if err != nil {
+			return err
+		}
+		defer store.Close()
+
+		watcher := taghistory.NewWatcher(c, store, &taghistory.WatcherOpts{
+			Targets:  targets,
+			Interval: *interval,
+			OnRecords: func(records []*taghistory.Record) {
+				for _, record := range records {
+					a.printRecord(record)
+				}
+			},
+			OnError: func(err error) {
+				fmt.Fprintln(a.errOut, "craas:", err)
+			},
+		})
+		if !*once {
+			return watcher.Run(ctx)
+		}
+		records, err := watcher.Poll(ctx)
+		for _, record := range records {
+			a.printRecord(record)
+		}
+
+		return err
+	}
+}
+
+// printRecord prints a recorded tag change as a line.
+func (a *app) printRecord(record *taghistory.Record) {
+	digest := record.Digest
+	if record.Removed() {
+		digest = "removed"
+	}
+	fmt.Fprintf(a.out, "%s %s:%s %s\n", record.Time.Format(time.RFC3339), record.Repository, record.Tag, digest)
+}
+
+func historyShow(fs *flag.FlagSet) runFunc {
+	storePath := historyStoreFlag(fs)
+
+	return func(ctx context.Context, a *app, args []string) error {
+		if err := expectArgs(args, "REGISTRY", "REPOSITORY", "TAG"); err != nil {
+			return err
+		}
+		store, registryID, err := a.openHistory(ctx, *storePath, args[0])
+		if err != nil {
+			return err
+		}
+		defer store.Close()
+
+		return a.print(store.History(registryID, args[1], args[2]), historyColumns...)
+	}
+}
+
+func historyAt(fs *flag.FlagSet) runFunc {
+	storePath := historyStoreFlag(fs)
+
+	return func(ctx context.Context, a *app, args []string) error {
+		if err := expectArgs(args, "REGISTRY", "REPOSITORY", "TAG", "TIME"); err != nil {
+			return err
+		}
+		t, err := time.Parse(time.RFC3339, args[3])
+		if err != nil {
+			return fmt.Errorf("invalid time, expected RFC 3339: %w", err)
+		}
+		store, registryID, err := a.openHistory(ctx, *storePath, args[0])
+		if err != nil {
+			return err
+		}
+		defer store.Close()
+
+		record, ok := store.At(registryID, args[1], args[2], t)
+		if !ok {
+			return fmt.Errorf("tag %s:%s wasn't recorded at %s", args[1], args[2], args[3])
+		}
+
+		return a.print(record, historyColumns...)
+	}
+}
+
+// openHistory loads the store for queries and resolves the registry ID.
+func (a *app) openHistory(ctx context.Context, storePath, idOrName string) (*taghistory.Store, string, error) {
+	r, err := a.resolveRegistry(ctx, idOrName)
+	if err != nil {
+		return nil, "", err
+	}
+	store, err := taghistory.Load(storePath)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return store, r.ID, nil
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"flag"
+
+	"github.com/selectel/craas-go/pkg/v1/repository"
+)
+
+func imagesCommand() *command {
+	return &command{
+		name:    "images",
+		summary: "manage repository images",
+		subcommands: []*command{
+			{name: "list", summary: "list images", args: "REGISTRY REPOSITORY", setup: imagesList, complete: completeRepositories},
+			{name: "tags", summary: "list tags", args: "REGISTRY REPOSITORY", setup: imagesTags, complete: completeRepositories},
+			{name: "layers", summary: "list image layers", args: "REGISTRY REPOSITORY IMAGE", setup: imagesLayers, complete: completeImages},
+			{name: "delete", summary: "delete an image by its tag or digest", args: "REGISTRY REPOSITORY IMAGE", setup: imagesDelete, complete: completeImages},
+		},
+	}
+}
+
+// imageColumns are default columns of images.
+var imageColumns = []string{"digest", "tags", "size", "layers", "createdAt"}
+
+func imagesList(_ *flag.FlagSet) runFunc {
+	return func(ctx context.Context, a *app, args []string) error {
+		if err := expectArgs(args, "REGISTRY", "REPOSITORY"); err != nil {
+			return err
+		}
+		r, err := a.resolveRegistry(ctx, args[0])
+		if err != nil {
+			return err
+		}
+		c, err := a.v1(ctx)
+		if err != nil {
+			return err
+		}
+		images, _, err := repository.ListImages(ctx, c, r.ID, args[1])
+		if err != nil {
+			return err
+		}
+
+		return a.print(images, imageColumns...)
+	}
+}
+
+func imagesTags(_ *flag.FlagSet) runFunc {
+	return func(ctx context.Context, a *app, args []string) error {
+		if err := expectArgs(args, "REGISTRY", "REPOSITORY"); err != nil {
+			return err
+		}
+		r, err := a.resolveRegistry(ctx, args[0])
+		if err != nil {
+			return err
+		}
+		c, err := a.v1(ctx)
+		if err != nil {
+			return err
+		}
+		tags, _, err := repository.ListTags(ctx, c, r.ID, args[1])
+		if err != nil {
+			return err
+		}
+
+		return a.print(tags)
+	}
+}
+
+func imagesLayers(_ *flag.FlagSet) runFunc {
+	return func(ctx context.Context, a *app, args []string) error {
+		if err := expectArgs(args, "REGISTRY", "REPOSITORY", "IMAGE"); err != nil {
+			return err
+		}
+		r, err := a.resolveRegistry(ctx, args[0])
+		if err != nil {
+			return err
+		}
+		c, err := a.v1(ctx)
+		if err != nil {
+			return err
+		}
+		layers, _, err := repository.ListImageLayers(ctx, c, r.ID, args[1], args[2])
+		if err != nil {
+			return err
+		}
+
+		return a.print(layers)
+	}
+}
+
+func imagesDelete(_ *flag.FlagSet) runFunc {
+	return func(ctx context.Context, a *app, args []string) error {
+		if err := expectArgs(args, "REGISTRY", "REPOSITORY", "IMAGE"); err != nil {
+			return err
+		}
+		r, err := a.resolveRegistry(ctx, args[0])
+		if err != nil {
+			return err
+		}
+		c, err := a.v1(ctx)
+		if err != nil {
+			return err
+		}
+		if _, err := repository.DeleteImageManifest(ctx, c, r.ID, args[1], args[2]); err != nil {
+			return err
+		}
+		a.printDone("image %s deleted", args[2])
+
+		return nil
+	}
+}
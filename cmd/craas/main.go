@@ -0,0 +1,50 @@
+// Command craas is a command-line client of the Selectel Container Registry
+// built on the craas-go SDK.
+//
+// Subcommands mirror the SDK packages:
+//
+//	craas registries create|list|get|delete|wait
+//	craas repos list|get|delete
+//	craas images list|tags|layers|delete
+//	craas gc start|size|preview|run
+//	craas tokens v1 create|get|refresh|revoke
+//	craas tokens v2 create|list|get|refresh|regenerate|revoke|delete|patch|audit
+//	craas plan|apply FILE
+//	craas snapshot take|diff
+//	craas history watch|show|at
+//	craas events
+//	craas quota check
+//	craas capacity check
+//	craas completion bash|zsh|fish
+//
+// Connection settings are read from flags, then from the CRAAS_TOKEN,
+// CRAAS_ENDPOINT and CRAAS_PROFILE environment variables and finally from
+// a profile of the configuration file, see the config package.
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+)
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	a := newApp(os.Stdout, os.Stderr)
+	if err := a.run(ctx, os.Args[1:]); err != nil {
+		if !errors.Is(err, errUsage) {
+			fmt.Fprintln(os.Stderr, "craas:", err)
+		}
+		code := 1
+		var exitErr *exitError
+		if errors.As(err, &exitErr) {
+			code = exitErr.code
+		}
+		stop()
+		os.Exit(code)
+	}
+}
@@ -0,0 +1,352 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/selectel/craas-go/pkg/config"
+	"github.com/selectel/craas-go/pkg/testutils"
+)
+
+const testRegistries = `
+[
+    {
+        "createdAt": "2022-01-28T08:50:34.123Z",
+        "id": "888af692-c646-4b76-a234-81ca9b5bcafe",
+        "name": "test-registry",
+        "size": 1024,
+        "sizeLimit": 2048,
+        "status": "ACTIVE",
+        "used": 50
+    }
+]
+`
+
+const testRepositories = `
+[
+    {
+        "name": "alpine",
+        "size": 512,
+        "updatedAt": "2022-01-28T08:51:34.123Z"
+    }
+]
+`
+
+func runTestApp(t *testing.T, endpoint string, args ...string) (string, error) {
+	t.Helper()
+
+	// Isolate the test from the user's profile file and environment.
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("CRAAS_CONFIG", "")
+	t.Setenv("CRAAS_PROFILE", "")
+
+	var out, errOut bytes.Buffer
+	a := newApp(&out, &errOut)
+	base := []string{"--token", testutils.TokenID, "--endpoint", endpoint}
+	err := a.run(context.Background(), append(args, base...))
+
+	return out.String(), err
+}
+
+func TestReposListResolvesRegistryName(t *testing.T) {
+	registriesCalled, endpointCalled := false, false
+	testEnv := testutils.SetupTestEnv()
+	defer testEnv.TearDownTestEnv()
+
+	testutils.HandleReqWithoutBody(t, &testutils.HandleReqOpts{
+		Mux:         testEnv.Mux,
+		URL:         "/api/v1/registries",
+		RawResponse: testRegistries,
+		Method:      http.MethodGet,
+		Status:      http.StatusOK,
+		CallFlag:    &registriesCalled,
+	})
+	testutils.HandleReqWithoutBody(t, &testutils.HandleReqOpts{
+		Mux:         testEnv.Mux,
+		URL:         "/api/v1/registries/888af692-c646-4b76-a234-81ca9b5bcafe/repositories",
+		RawResponse: testRepositories,
+		Method:      http.MethodGet,
+		Status:      http.StatusOK,
+		CallFlag:    &endpointCalled,
+	})
+
+	out, err := runTestApp(t, testEnv.Server.URL+"/api", "repos", "list", "test-registry", "-o", "json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !registriesCalled || !endpointCalled {
+		t.Fatal("endpoint wasn't called")
+	}
+
+	var actual []map[string]interface{}
+	if err := json.Unmarshal([]byte(out), &actual); err != nil {
+		t.Fatal(err)
+	}
+	if len(actual) != 1 || actual[0]["name"] != "alpine" {
+		t.Fatalf("expected the alpine repository, but got %s", out)
+	}
+}
+
+func TestRegistriesListTable(t *testing.T) {
+	registriesCalled := false
+	testEnv := testutils.SetupTestEnv()
+	defer testEnv.TearDownTestEnv()
+
+	testutils.HandleReqWithoutBody(t, &testutils.HandleReqOpts{
+		Mux:         testEnv.Mux,
+		URL:         "/api/v1/registries",
+		RawResponse: testRegistries,
+		Method:      http.MethodGet,
+		Status:      http.StatusOK,
+		CallFlag:    &registriesCalled,
+	})
+
+	out, err := runTestApp(t, testEnv.Server.URL+"/api/v1", "registries", "list", "--columns", "id,name,status,size,sizeLimit,used")
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected a header and one row, but got %q", out)
+	}
+	expected := []string{"888af692-c646-4b76-a234-81ca9b5bcafe", "test-registry", "ACTIVE", "1.0 KiB", "2.0 KiB", "50"}
+	if actual := regexp.MustCompile(`\s{2,}`).Split(strings.TrimSpace(lines[1]), -1); !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("expected %#v, but got %#v", expected, actual)
+	}
+}
+
+func TestUnknownCommand(t *testing.T) {
+	_, err := runTestApp(t, "http://localhost", "registries", "unknown")
+	if err != errUsage {
+		t.Fatalf("expected %v, but got %v", errUsage, err)
+	}
+}
+
+func TestInitProfile(t *testing.T) {
+	t.Setenv("CRAAS_TOKEN", "")
+	t.Setenv("CRAAS_ENDPOINT", "")
+	t.Setenv("CRAAS_PROFILE", "")
+
+	dir := t.TempDir()
+	tokenFile := filepath.Join(dir, "token")
+	if err := os.WriteFile(tokenFile, []byte("file-token\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	configPath := filepath.Join(dir, "config.yaml")
+	cfg := `
+defaultProfile: dev
+profiles:
+  dev:
+    endpoint: https://dev.example.com/api/v2/
+    timeout: 5s
+    token:
+      file: ` + tokenFile + `
+  prod:
+    token:
+      value: prod-token
+`
+	if err := os.WriteFile(configPath, []byte(cfg), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	a := newApp(&bytes.Buffer{}, &bytes.Buffer{})
+	a.opts = globalOpts{config: configPath, output: "table"}
+	if err := a.init(); err != nil {
+		t.Fatal(err)
+	}
+	token, err := a.session.Token(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token != "file-token" || a.session.Endpoint != "https://dev.example.com/api" {
+		t.Fatalf("unexpected settings of the default profile: %s %s", token, a.session.Endpoint)
+	}
+	if a.session.HTTPClient.Timeout != 5*time.Second {
+		t.Fatalf("expected 5s timeout, but got %s", a.session.HTTPClient.Timeout)
+	}
+
+	// Flags take precedence over the profile.
+	t.Setenv("CRAAS_PROFILE", "prod")
+	a = newApp(&bytes.Buffer{}, &bytes.Buffer{})
+	a.opts = globalOpts{config: configPath, output: "table", token: "flag-token"}
+	if err := a.init(); err != nil {
+		t.Fatal(err)
+	}
+	token, err = a.session.Token(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token != "flag-token" || a.session.Endpoint != config.DefaultEndpoint {
+		t.Fatalf("unexpected settings of the prod profile: %s %s", token, a.session.Endpoint)
+	}
+}
+
+func TestPlanWithoutChanges(t *testing.T) {
+	registriesCalled, tokensCalled := false, false
+	testEnv := testutils.SetupTestEnv()
+	defer testEnv.TearDownTestEnv()
+
+	testutils.HandleReqWithoutBody(t, &testutils.HandleReqOpts{
+		Mux:         testEnv.Mux,
+		URL:         "/api/v1/registries",
+		RawResponse: testRegistries,
+		Method:      http.MethodGet,
+		Status:      http.StatusOK,
+		CallFlag:    &registriesCalled,
+	})
+	testutils.HandleReqWithoutBody(t, &testutils.HandleReqOpts{
+		Mux:         testEnv.Mux,
+		URL:         "/api/v2/tokens",
+		RawResponse: `{"tokens": [], "totalCount": 0}`,
+		Method:      http.MethodGet,
+		Status:      http.StatusOK,
+		CallFlag:    &tokensCalled,
+	})
+
+	specPath := filepath.Join(t.TempDir(), "craas.yaml")
+	if err := os.WriteFile(specPath, []byte("registries:\n  - name: test-registry\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	out, err := runTestApp(t, testEnv.Server.URL+"/api", "plan", specPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "Plan: 0 to create, 0 to update, 0 to delete.\n"
+	if out != expected {
+		t.Fatalf("expected %q, but got %q", expected, out)
+	}
+}
+
+func TestApplyPartialFailure(t *testing.T) {
+	created := 0
+	testEnv := testutils.SetupTestEnv()
+	defer testEnv.TearDownTestEnv()
+
+	testEnv.Mux.HandleFunc("/api/v1/registries", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Content-Type", "application/json")
+		fmt.Fprint(w, testRegistries)
+	})
+	testEnv.Mux.HandleFunc("/api/v2/tokens", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Content-Type", "application/json")
+		if r.Method == http.MethodGet {
+			fmt.Fprint(w, `{"tokens": [], "totalCount": 0}`)
+
+			return
+		}
+		created++
+		if created > 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			fmt.Fprint(w, `{"error": "internal error"}`)
+
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, `{"id": "t-reader", "name": "reader", "token": "secret-value", "status": "active"}`)
+	})
+
+	specPath := filepath.Join(t.TempDir(), "craas.yaml")
+	spec := "tokens:\n  - name: reader\n    registries: [test-registry]\n  - name: writer\n    registries: [test-registry]\n"
+	if err := os.WriteFile(specPath, []byte(spec), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	out, err := runTestApp(t, testEnv.Server.URL+"/api", "apply", "--yes", "-o", "json", specPath)
+	if err == nil {
+		t.Fatal("expected an error of the failed change")
+
+	}
+	if !strings.Contains(out, "secret-value") {
+		t.Fatalf("expected the created token in %q", out)
+	}
+}
+
+func TestSnapshotDiff(t *testing.T) {
+	dir := t.TempDir()
+	before := `{"version": 1, "createdAt": "2023-03-01T12:00:00Z", "registries": [{"name": "test-registry"}]}`
+	after := `{"version": 1, "createdAt": "2023-03-02T12:00:00Z", "registries": []}`
+	for name, content := range map[string]string{"before.json": before, "after.json": after} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	out, err := runTestApp(t, "http://localhost", "snapshot", "diff",
+		filepath.Join(dir, "before.json"), filepath.Join(dir, "after.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "Changes from 2023-03-01T12:00:00Z to 2023-03-02T12:00:00Z\n- registry test-registry\n"
+	if out != expected {
+		t.Fatalf("expected %q, but got %q", expected, out)
+	}
+}
+
+func TestHistoryShow(t *testing.T) {
+	registriesCalled := false
+	testEnv := testutils.SetupTestEnv()
+	defer testEnv.TearDownTestEnv()
+
+	testutils.HandleReqWithoutBody(t, &testutils.HandleReqOpts{
+		Mux:         testEnv.Mux,
+		URL:         "/api/v1/registries",
+		RawResponse: testRegistries,
+		Method:      http.MethodGet,
+		Status:      http.StatusOK,
+		CallFlag:    &registriesCalled,
+	})
+
+	storePath := filepath.Join(t.TempDir(), "tags.jsonl")
+	records := `{"time":"2023-03-01T12:00:00Z","registryId":"888af692-c646-4b76-a234-81ca9b5bcafe","repository":"alpine","tag":"latest","digest":"sha256:aaaa"}
+{"time":"2023-03-02T12:00:00Z","registryId":"888af692-c646-4b76-a234-81ca9b5bcafe","repository":"alpine","tag":"latest","digest":"sha256:bbbb"}
+`
+	if err := os.WriteFile(storePath, []byte(records), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := runTestApp(t, testEnv.Server.URL+"/api", "history", "show", "test-registry", "alpine", "latest",
+		"--store", storePath, "-o", "csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "time,tag,digest\n2023-03-01T12:00:00Z,latest,sha256:aaaa\n2023-03-02T12:00:00Z,latest,sha256:bbbb\n"
+	if out != expected {
+		t.Fatalf("expected %q, but got %q", expected, out)
+	}
+}
+
+func TestQuotaCheckExitCode(t *testing.T) {
+	registriesCalled := false
+	testEnv := testutils.SetupTestEnv()
+	defer testEnv.TearDownTestEnv()
+
+	testutils.HandleReqWithoutBody(t, &testutils.HandleReqOpts{
+		Mux:         testEnv.Mux,
+		URL:         "/api/v1/registries",
+		RawResponse: testRegistries,
+		Method:      http.MethodGet,
+		Status:      http.StatusOK,
+		CallFlag:    &registriesCalled,
+	})
+
+	out, err := runTestApp(t, testEnv.Server.URL+"/api", "quota", "check",
+		"--warning", "40", "--critical", "60", "--skip-garbage-size", "-o", "csv", "--columns", "registry,level")
+	var exitErr *exitError
+	if !errors.As(err, &exitErr) || exitErr.code != 1 {
+		t.Fatalf("expected exit code 1, but got %v", err)
+	}
+	expected := "registry,level\ntest-registry,WARNING\n"
+	if out != expected {
+		t.Fatalf("expected %q, but got %q", expected, out)
+	}
+}
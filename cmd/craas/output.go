@@ -0,0 +1,34 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/selectel/craas-go/pkg/format"
+)
+
+// print renders the value in the selected output format. Default columns
+// are used in tables and CSV unless columns are selected with --columns.
+func (a *app) print(value interface{}, defaultColumns ...string) error {
+	opts := &format.Opts{
+		Format:   format.Format(a.opts.output),
+		Template: a.opts.template,
+		SortBy:   a.opts.sortBy,
+		NoHeader: a.opts.noHeaders,
+	}
+	if a.opts.columns != "" {
+		opts.Columns = strings.Split(a.opts.columns, ",")
+	} else if opts.Format == format.FormatTable || opts.Format == format.FormatCSV {
+		opts.Columns = defaultColumns
+	}
+
+	return format.Render(a.out, value, opts)
+}
+
+// printDone prints a confirmation of an action without a result.
+func (a *app) printDone(msg string, args ...interface{}) {
+	if a.opts.output != string(format.FormatTable) {
+		return
+	}
+	fmt.Fprintf(a.out, msg+"\n", args...)
+}
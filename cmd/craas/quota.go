@@ -0,0 +1,61 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	"github.com/selectel/craas-go/pkg/v1/quota"
+	"github.com/selectel/craas-go/pkg/webhook"
+)
+
+// quotaColumns are default columns of quota findings.
+var quotaColumns = []string{"registry", "level", "usedPercent", "size", "sizeLimit", "headroom", "suggestGC", "suggestDeleteUntagged"}
+
+func quotaCommand() *command {
+	return &command{
+		name:    "quota",
+		summary: "check registry storage usage",
+		subcommands: []*command{
+			{name: "check", summary: "check usage thresholds, exit with 1 on warnings and 2 on critical findings", args: "[REGISTRY...]", setup: quotaCheck, complete: completeRegistries},
+		},
+	}
+}
+
+func quotaCheck(fs *flag.FlagSet) runFunc {
+	opts := &quota.Opts{}
+	fs.Float64Var(&opts.Thresholds.WarningPercent, "warning", quota.DefaultWarningPercent, "usage percent reported as a warning")
+	fs.Float64Var(&opts.Thresholds.CriticalPercent, "critical", quota.DefaultCriticalPercent, "usage percent reported as critical")
+	fs.Var((*sizeValue)(&opts.Thresholds.WarningHeadroom), "warning-headroom", "free space reported as a warning, for example 5GiB")
+	fs.Var((*sizeValue)(&opts.Thresholds.CriticalHeadroom), "critical-headroom", "free space reported as critical, for example 1GiB")
+	fs.BoolVar(&opts.SkipGarbageSize, "skip-garbage-size", false, "don't request garbage sizes to suggest GC")
+	hooks := webhookFlags(fs)
+
+	return func(ctx context.Context, a *app, args []string) error {
+		c, err := a.v1(ctx)
+		if err != nil {
+			return err
+		}
+		opts.Registries = args
+		report, err := quota.Check(ctx, c, opts)
+		if err != nil {
+			return err
+		}
+		for _, finding := range report.Findings {
+			if finding.Level == quota.LevelOK {
+				continue
+			}
+			if err := hooks.notify(ctx, webhook.QuotaFinding(finding, report.CheckedAt)); err != nil {
+				return err
+			}
+		}
+		if err := a.print(report.Findings, quotaColumns...); err != nil {
+			return err
+		}
+		if code := report.ExitCode(); code != 0 {
+			return &exitError{code: code, err: fmt.Errorf("registry usage is %s", report.Level())}
+		}
+
+		return nil
+	}
+}
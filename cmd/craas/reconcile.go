@@ -0,0 +1,118 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+
+	"github.com/selectel/craas-go/pkg/format"
+	"github.com/selectel/craas-go/pkg/reconcile"
+	tokenv2 "github.com/selectel/craas-go/pkg/v2/token"
+)
+
+func planCommand() *command {
+	return &command{name: "plan", summary: "show changes needed to reach a specification", args: "FILE", setup: planRun}
+}
+
+func applyCommand() *command {
+	return &command{name: "apply", summary: "apply a specification of registries and tokens", args: "FILE", setup: applyRun}
+}
+
+// reconcileFlags registers flags shared by the plan and apply commands.
+func reconcileFlags(fs *flag.FlagSet) *reconcile.Opts {
+	opts := &reconcile.Opts{}
+	fs.BoolVar(&opts.PruneRegistries, "prune-registries", false, "delete registries missing from the specification")
+	fs.BoolVar(&opts.PruneTokens, "prune-tokens", false, "delete tokens missing from the specification")
+	fs.Var((*stringList)(&opts.Protected), "protect", "name pattern of resources that are never deleted, can be repeated")
+
+	return opts
+}
+
+// newPlan reads the specification and plans changes.
+func (a *app) newPlan(ctx context.Context, filename string, opts *reconcile.Opts) (*reconcile.Plan, error) {
+	spec, err := reconcile.Load(filename)
+	if err != nil {
+		return nil, err
+	}
+	c1, err := a.v1(ctx)
+	if err != nil {
+		return nil, err
+	}
+	c2, err := a.v2(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return reconcile.NewPlan(ctx, c1, c2, spec, opts)
+}
+
+func planRun(fs *flag.FlagSet) runFunc {
+	opts := reconcileFlags(fs)
+
+	return func(ctx context.Context, a *app, args []string) error {
+		if err := expectArgs(args, "FILE"); err != nil {
+			return err
+		}
+		plan, err := a.newPlan(ctx, args[0], opts)
+		if err != nil {
+			return err
+		}
+		if a.opts.output != string(format.FormatTable) {
+			return a.print(plan)
+		}
+
+		return plan.Write(a.out)
+	}
+}
+
+func applyRun(fs *flag.FlagSet) runFunc {
+	opts := reconcileFlags(fs)
+	yes := fs.Bool("yes", false, "apply the changes without a confirmation")
+
+	return func(ctx context.Context, a *app, args []string) error {
+		if err := expectArgs(args, "FILE"); err != nil {
+			return err
+		}
+		plan, err := a.newPlan(ctx, args[0], opts)
+		if err != nil {
+			return err
+		}
+		if err := plan.Write(a.errOut); err != nil {
+			return err
+		}
+		if plan.IsEmpty() {
+			return nil
+		}
+		if !*yes {
+			return errors.New("rerun with --yes to apply the changes")
+		}
+
+		c1, err := a.v1(ctx)
+		if err != nil {
+			return err
+		}
+		c2, err := a.v2(ctx)
+		if err != nil {
+			return err
+		}
+		result, applyErr := plan.Apply(ctx, c1, c2)
+		for _, c := range result.Applied {
+			fmt.Fprintf(a.errOut, "%s %s %s: done\n", c.Action, c.Kind, c.Name)
+		}
+		// Secrets of the tokens created before a failure are shown only once.
+		if len(result.CreatedTokens) > 0 {
+			tokens := make([]*tokenv2.TokenV2, 0, len(result.CreatedTokens))
+			for _, c := range result.Applied {
+				if tkn, ok := result.CreatedTokens[c.Name]; ok && c.Kind == reconcile.KindToken {
+					tokens = append(tokens, tkn)
+				}
+			}
+			if err := a.print(tokens, "id", "name", "token"); err != nil {
+				return err
+			}
+		}
+
+		return applyErr
+	}
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/selectel/craas-go/pkg/v1/registry"
+)
+
+func registriesCommand() *command {
+	return &command{
+		name:    "registries",
+		summary: "manage registries",
+		subcommands: []*command{
+			{name: "create", summary: "create a registry", args: "NAME", setup: registriesCreate},
+			{name: "list", summary: "list registries", setup: registriesList},
+			{name: "get", summary: "show a registry", args: "REGISTRY", setup: registriesGet, complete: completeRegistries},
+			{name: "delete", summary: "delete a registry", args: "REGISTRY", setup: registriesDelete, complete: completeRegistries},
+			{name: "wait", summary: "wait for a registry status", args: "REGISTRY", setup: registriesWait, complete: completeRegistries},
+		},
+	}
+}
+
+// registryColumns are default columns of registries.
+var registryColumns = []string{"id", "name", "status", "size", "sizeLimit", "used", "createdAt"}
+
+func registriesCreate(_ *flag.FlagSet) runFunc {
+	return func(ctx context.Context, a *app, args []string) error {
+		if err := expectArgs(args, "NAME"); err != nil {
+			return err
+		}
+		c, err := a.v1(ctx)
+		if err != nil {
+			return err
+		}
+		r, _, err := registry.Create(ctx, c, args[0])
+		if err != nil {
+			return err
+		}
+
+		return a.print(r, registryColumns...)
+	}
+}
+
+func registriesList(_ *flag.FlagSet) runFunc {
+	return func(ctx context.Context, a *app, args []string) error {
+		if err := expectArgs(args); err != nil {
+			return err
+		}
+		c, err := a.v1(ctx)
+		if err != nil {
+			return err
+		}
+		registries, _, err := registry.List(ctx, c)
+		if err != nil {
+			return err
+		}
+
+		return a.print(registries, registryColumns...)
+	}
+}
+
+func registriesGet(_ *flag.FlagSet) runFunc {
+	return func(ctx context.Context, a *app, args []string) error {
+		if err := expectArgs(args, "REGISTRY"); err != nil {
+			return err
+		}
+		r, err := a.resolveRegistry(ctx, args[0])
+		if err != nil {
+			return err
+		}
+
+		return a.print(r, registryColumns...)
+	}
+}
+
+func registriesDelete(_ *flag.FlagSet) runFunc {
+	return func(ctx context.Context, a *app, args []string) error {
+		if err := expectArgs(args, "REGISTRY"); err != nil {
+			return err
+		}
+		r, err := a.resolveRegistry(ctx, args[0])
+		if err != nil {
+			return err
+		}
+		c, err := a.v1(ctx)
+		if err != nil {
+			return err
+		}
+		if _, err := registry.Delete(ctx, c, r.ID); err != nil {
+			return err
+		}
+		a.printDone("registry %s deleted", r.Name)
+
+		return nil
+	}
+}
+
+func registriesWait(fs *flag.FlagSet) runFunc {
+	status := fs.String("status", string(registry.StatusActive), "registry status to wait for")
+	interval := fs.Duration("interval", 5*time.Second, "polling interval")
+	timeout := fs.Duration("wait-timeout", 10*time.Minute, "maximum time to wait")
+
+	return func(ctx context.Context, a *app, args []string) error {
+		if err := expectArgs(args, "REGISTRY"); err != nil {
+			return err
+		}
+		r, err := a.resolveRegistry(ctx, args[0])
+		if err != nil {
+			return err
+		}
+		r, err = a.waitRegistryStatus(ctx, r.ID, registry.Status(*status), *interval, *timeout)
+		if err != nil {
+			return err
+		}
+
+		return a.print(r, registryColumns...)
+	}
+}
+
+// waitRegistryStatus polls the registry until it gets the status.
+func (a *app) waitRegistryStatus(
+	ctx context.Context,
+	registryID string,
+	status registry.Status,
+	interval, timeout time.Duration,
+) (*registry.Registry, error) {
+	c, err := a.v1(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		r, _, err := registry.Get(ctx, c, registryID)
+		if err != nil {
+			return nil, err
+		}
+		if r.Status == status {
+			return r, nil
+		}
+		if r.Status == registry.StatusError {
+			return r, fmt.Errorf("registry %s is in the %s status", r.Name, r.Status)
+		}
+
+		select {
+		case <-ctx.Done():
+			return r, fmt.Errorf("registry %s is still in the %s status: %w", r.Name, r.Status, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// resolveRegistry returns a registry by its ID or name.
+func (a *app) resolveRegistry(ctx context.Context, idOrName string) (*registry.Registry, error) {
+	c, err := a.v1(ctx)
+	if err != nil {
+		return nil, err
+	}
+	registries, _, err := registry.List(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range registries {
+		if r.ID == idOrName || r.Name == idOrName {
+			return r, nil
+		}
+	}
+
+	return nil, fmt.Errorf("registry %q not found", idOrName)
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"flag"
+
+	"github.com/selectel/craas-go/pkg/v1/repository"
+)
+
+func reposCommand() *command {
+	return &command{
+		name:    "repos",
+		summary: "manage repositories",
+		subcommands: []*command{
+			{name: "list", summary: "list repositories", args: "REGISTRY", setup: reposList, complete: completeRegistries},
+			{name: "get", summary: "show a repository", args: "REGISTRY REPOSITORY", setup: reposGet, complete: completeRepositories},
+			{name: "delete", summary: "delete a repository", args: "REGISTRY REPOSITORY", setup: reposDelete, complete: completeRepositories},
+		},
+	}
+}
+
+func reposList(_ *flag.FlagSet) runFunc {
+	return func(ctx context.Context, a *app, args []string) error {
+		if err := expectArgs(args, "REGISTRY"); err != nil {
+			return err
+		}
+		r, err := a.resolveRegistry(ctx, args[0])
+		if err != nil {
+			return err
+		}
+		c, err := a.v1(ctx)
+		if err != nil {
+			return err
+		}
+		repositories, _, err := repository.ListRepositories(ctx, c, r.ID)
+		if err != nil {
+			return err
+		}
+
+		return a.print(repositories)
+	}
+}
+
+func reposGet(_ *flag.FlagSet) runFunc {
+	return func(ctx context.Context, a *app, args []string) error {
+		if err := expectArgs(args, "REGISTRY", "REPOSITORY"); err != nil {
+			return err
+		}
+		r, err := a.resolveRegistry(ctx, args[0])
+		if err != nil {
+			return err
+		}
+		c, err := a.v1(ctx)
+		if err != nil {
+			return err
+		}
+		repo, _, err := repository.GetRepository(ctx, c, r.ID, args[1])
+		if err != nil {
+			return err
+		}
+
+		return a.print(repo)
+	}
+}
+
+func reposDelete(_ *flag.FlagSet) runFunc {
+	return func(ctx context.Context, a *app, args []string) error {
+		if err := expectArgs(args, "REGISTRY", "REPOSITORY"); err != nil {
+			return err
+		}
+		r, err := a.resolveRegistry(ctx, args[0])
+		if err != nil {
+			return err
+		}
+		c, err := a.v1(ctx)
+		if err != nil {
+			return err
+		}
+		if _, err := repository.DeleteRepository(ctx, c, r.ID, args[1]); err != nil {
+			return err
+		}
+		a.printDone("repository %s deleted", args[1])
+
+		return nil
+	}
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"context"
+	"flag"
+
+	"github.com/selectel/craas-go/pkg/v1/snapshot"
+)
+
+func snapshotCommand() *command {
+	return &command{
+		name:    "snapshot",
+		summary: "capture and compare inventories of registries",
+		subcommands: []*command{
+			{name: "take", summary: "capture registries, repositories and images", args: "[REGISTRY...]", setup: snapshotTake, complete: completeRegistries},
+			{name: "diff", summary: "compare two snapshots", args: "OLD NEW", setup: snapshotDiff, local: true},
+		},
+	}
+}
+
+func snapshotTake(fs *flag.FlagSet) runFunc {
+	file := fs.String("f", "", "write the snapshot to a file instead of stdout")
+
+	return func(ctx context.Context, a *app, args []string) error {
+		c, err := a.v1(ctx)
+		if err != nil {
+			return err
+		}
+		snap, err := snapshot.Take(ctx, c, &snapshot.TakeOpts{Registries: args})
+		if err != nil {
+			return err
+		}
+		if *file != "" {
+			return snap.Save(*file)
+		}
+
+		return snap.Write(a.out)
+	}
+}
+
+func snapshotDiff(fs *flag.FlagSet) runFunc {
+	return func(ctx context.Context, a *app, args []string) error {
+		if err := expectArgs(args, "OLD", "NEW"); err != nil {
+			return err
+		}
+		before, err := snapshot.Load(args[0])
+		if err != nil {
+			return err
+		}
+		after, err := snapshot.Load(args[1])
+		if err != nil {
+			return err
+		}
+		diff := snapshot.Compare(before, after)
+		if a.opts.output != "table" {
+			return a.print(diff)
+		}
+
+		return diff.Write(a.out)
+	}
+}
@@ -0,0 +1,396 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"time"
+
+	"github.com/selectel/craas-go/pkg/format"
+	"github.com/selectel/craas-go/pkg/v1/token"
+	tokenv2 "github.com/selectel/craas-go/pkg/v2/token"
+	"github.com/selectel/craas-go/pkg/v2/token/audit"
+	"github.com/selectel/craas-go/pkg/webhook"
+)
+
+func tokensCommand() *command {
+	return &command{
+		name:    "tokens",
+		summary: "manage tokens",
+		subcommands: []*command{
+			{
+				name:    "v1",
+				summary: "manage v1 tokens",
+				subcommands: []*command{
+					{name: "create", summary: "create a token", setup: tokensV1Create},
+					{name: "get", summary: "show a token", args: "TOKEN", setup: tokensV1Get},
+					{name: "refresh", summary: "refresh a token", args: "TOKEN", setup: tokensV1Refresh},
+					{name: "revoke", summary: "revoke a token", args: "TOKEN", setup: tokensV1Revoke},
+				},
+			},
+			{
+				name:    "v2",
+				summary: "manage v2 tokens",
+				subcommands: []*command{
+					{name: "create", summary: "create a token", setup: tokensV2Create},
+					{name: "list", summary: "list tokens", setup: tokensV2List},
+					{name: "get", summary: "show a token", args: "TOKEN_ID", setup: tokensV2Get},
+					{name: "refresh", summary: "extend a token expiration", args: "TOKEN_ID", setup: tokensV2Refresh},
+					{name: "regenerate", summary: "regenerate a token value", args: "TOKEN_ID", setup: tokensV2Regenerate},
+					{name: "revoke", summary: "revoke a token", args: "TOKEN_ID", setup: tokensV2Revoke},
+					{name: "delete", summary: "delete a token", args: "TOKEN_ID", setup: tokensV2Delete},
+					{name: "patch", summary: "update a token partially", args: "TOKEN_ID", setup: tokensV2Patch},
+					{name: "audit", summary: "report unused, expiring and risky tokens", setup: tokensV2Audit},
+				},
+			},
+		},
+	}
+}
+
+// tokenV2Columns are default columns of v2 tokens.
+var tokenV2Columns = []string{
+	"id", "name", "status", "scope.modeRW", "scope.allRegistries", "scope.registryIds", "expiration.expiresAt", "lastUsedAt",
+}
+
+func tokensV1Create(fs *flag.FlagSet) runFunc {
+	ttl := fs.String("ttl", string(token.TTL12Hours), "token lifetime: 12h or 1y")
+
+	return func(ctx context.Context, a *app, args []string) error {
+		if err := expectArgs(args); err != nil {
+			return err
+		}
+		c, err := a.v1(ctx)
+		if err != nil {
+			return err
+		}
+		t, _, err := token.Create(ctx, c, &token.CreateOpts{TokenTTL: token.TTL(*ttl)})
+		if err != nil {
+			return err
+		}
+
+		return a.print(t)
+	}
+}
+
+func tokensV1Get(_ *flag.FlagSet) runFunc {
+	return func(ctx context.Context, a *app, args []string) error {
+		if err := expectArgs(args, "TOKEN"); err != nil {
+			return err
+		}
+		c, err := a.v1(ctx)
+		if err != nil {
+			return err
+		}
+		t, _, err := token.Get(ctx, c, args[0])
+		if err != nil {
+			return err
+		}
+
+		return a.print(t)
+	}
+}
+
+func tokensV1Refresh(_ *flag.FlagSet) runFunc {
+	return func(ctx context.Context, a *app, args []string) error {
+		if err := expectArgs(args, "TOKEN"); err != nil {
+			return err
+		}
+		c, err := a.v1(ctx)
+		if err != nil {
+			return err
+		}
+		t, _, err := token.Refresh(ctx, c, args[0])
+		if err != nil {
+			return err
+		}
+
+		return a.print(t)
+	}
+}
+
+func tokensV1Revoke(_ *flag.FlagSet) runFunc {
+	return func(ctx context.Context, a *app, args []string) error {
+		if err := expectArgs(args, "TOKEN"); err != nil {
+			return err
+		}
+		c, err := a.v1(ctx)
+		if err != nil {
+			return err
+		}
+		if _, err := token.Revoke(ctx, c, args[0]); err != nil {
+			return err
+		}
+		a.printDone("token revoked")
+
+		return nil
+	}
+}
+
+// expiration converts a lifetime flag into a token expiration,
+// zero lifetime means the token never expires.
+func expiration(expiresIn time.Duration) tokenv2.Expiration {
+	if expiresIn <= 0 {
+		return tokenv2.Expiration{}
+	}
+
+	return tokenv2.Expiration{IsSet: true, ExpiresAt: time.Now().Add(expiresIn).UTC()}
+}
+
+func tokensV2Create(fs *flag.FlagSet) runFunc {
+	name := fs.String("name", "", "token name")
+	readWrite := fs.Bool("rw", false, "grant read-write access")
+	allRegistries := fs.Bool("all-registries", false, "grant access to all registries")
+	var registries stringList
+	fs.Var(&registries, "registry", "registry ID or name, can be repeated")
+	expiresIn := fs.Duration("expires-in", 0, "token lifetime, the token never expires if not set")
+
+	return func(ctx context.Context, a *app, args []string) error {
+		if err := expectArgs(args); err != nil {
+			return err
+		}
+		if *name == "" {
+			return errors.New("--name is required")
+		}
+		builder := tokenv2.NewScopeBuilder()
+		if *readWrite {
+			builder.ReadWrite()
+		} else {
+			builder.ReadOnly()
+		}
+		if *allRegistries {
+			builder.AllRegistries()
+		}
+		for _, idOrName := range registries {
+			r, err := a.resolveRegistry(ctx, idOrName)
+			if err != nil {
+				return err
+			}
+			builder.ForRegistries(r.ID)
+		}
+		scope, err := builder.Build()
+		if err != nil {
+			return err
+		}
+		c, err := a.v2(ctx)
+		if err != nil {
+			return err
+		}
+		tkn := &tokenv2.TokenV2{Name: *name, Scope: scope, Expiration: expiration(*expiresIn)}
+		t, _, err := tokenv2.Create(ctx, c, tkn, nil)
+		if err != nil {
+			return err
+		}
+
+		return a.print(t, append(tokenV2Columns, "token")...)
+	}
+}
+
+func tokensV2List(fs *flag.FlagSet) runFunc {
+	sortField := fs.String("sort-field", "", "sort field: name, createdAt, expiresAt, lastUsedAt or status")
+	sortType := fs.String("sort-type", "", "sort direction: asc or desc")
+	search := fs.String("search", "", "search tokens by name")
+	scopeMode := fs.String("scope-mode", "", "filter tokens by access mode: r or rw")
+	active := fs.Bool("active", false, "show only active tokens")
+
+	return func(ctx context.Context, a *app, args []string) error {
+		if err := expectArgs(args); err != nil {
+			return err
+		}
+		c, err := a.v2(ctx)
+		if err != nil {
+			return err
+		}
+		tokens, err := tokenv2.ListAll(ctx, c, tokenv2.Opts{
+			SortField: *sortField,
+			SortType:  *sortType,
+			Search:    *search,
+			ScopeMode: *scopeMode,
+		})
+		if err != nil {
+			return err
+		}
+		if *active {
+			tokens = tokenv2.ActiveOnly(tokens)
+		}
+
+		return a.print(tokens, tokenV2Columns...)
+	}
+}
+
+func tokensV2Get(_ *flag.FlagSet) runFunc {
+	return func(ctx context.Context, a *app, args []string) error {
+		if err := expectArgs(args, "TOKEN_ID"); err != nil {
+			return err
+		}
+		c, err := a.v2(ctx)
+		if err != nil {
+			return err
+		}
+		t, _, err := tokenv2.GetByID(ctx, c, args[0])
+		if err != nil {
+			return err
+		}
+
+		return a.print(t, tokenV2Columns...)
+	}
+}
+
+func tokensV2Refresh(fs *flag.FlagSet) runFunc {
+	expiresIn := fs.Duration("expires-in", 0, "new token lifetime, the token never expires if not set")
+
+	return func(ctx context.Context, a *app, args []string) error {
+		if err := expectArgs(args, "TOKEN_ID"); err != nil {
+			return err
+		}
+		c, err := a.v2(ctx)
+		if err != nil {
+			return err
+		}
+		t, _, err := tokenv2.Refresh(ctx, c, args[0], expiration(*expiresIn))
+		if err != nil {
+			return err
+		}
+
+		return a.print(t, tokenV2Columns...)
+	}
+}
+
+func tokensV2Regenerate(fs *flag.FlagSet) runFunc {
+	expiresIn := fs.Duration("expires-in", 0, "new token lifetime, the token never expires if not set")
+
+	return func(ctx context.Context, a *app, args []string) error {
+		if err := expectArgs(args, "TOKEN_ID"); err != nil {
+			return err
+		}
+		c, err := a.v2(ctx)
+		if err != nil {
+			return err
+		}
+		t, _, err := tokenv2.Regenerate(ctx, c, args[0], expiration(*expiresIn))
+		if err != nil {
+			return err
+		}
+
+		return a.print(t, append(tokenV2Columns, "token")...)
+	}
+}
+
+func tokensV2Revoke(_ *flag.FlagSet) runFunc {
+	return func(ctx context.Context, a *app, args []string) error {
+		if err := expectArgs(args, "TOKEN_ID"); err != nil {
+			return err
+		}
+		c, err := a.v2(ctx)
+		if err != nil {
+			return err
+		}
+		if _, err := tokenv2.Revoke(ctx, c, args[0]); err != nil {
+			return err
+		}
+		a.printDone("token %s revoked", args[0])
+
+		return nil
+	}
+}
+
+func tokensV2Delete(_ *flag.FlagSet) runFunc {
+	return func(ctx context.Context, a *app, args []string) error {
+		if err := expectArgs(args, "TOKEN_ID"); err != nil {
+			return err
+		}
+		c, err := a.v2(ctx)
+		if err != nil {
+			return err
+		}
+		if _, err := tokenv2.Delete(ctx, c, args[0]); err != nil {
+			return err
+		}
+		a.printDone("token %s deleted", args[0])
+
+		return nil
+	}
+}
+
+func tokensV2Patch(fs *flag.FlagSet) runFunc {
+	name := fs.String("name", "", "new token name")
+	var addRegistries, removeRegistries stringList
+	fs.Var(&addRegistries, "add-registry", "registry ID or name to add to the scope, can be repeated")
+	fs.Var(&removeRegistries, "remove-registry", "registry ID or name to remove from the scope, can be repeated")
+	expiresIn := fs.Duration("expires-in", 0, "new token lifetime")
+
+	return func(ctx context.Context, a *app, args []string) error {
+		if err := expectArgs(args, "TOKEN_ID"); err != nil {
+			return err
+		}
+		opts := &tokenv2.PatchOpts{}
+		if *name != "" {
+			opts.SetName(*name)
+		}
+		for _, idOrName := range addRegistries {
+			r, err := a.resolveRegistry(ctx, idOrName)
+			if err != nil {
+				return err
+			}
+			opts.AddRegistries(r.ID)
+		}
+		for _, idOrName := range removeRegistries {
+			r, err := a.resolveRegistry(ctx, idOrName)
+			if err != nil {
+				return err
+			}
+			opts.RemoveRegistries(r.ID)
+		}
+		if *expiresIn > 0 {
+			opts.SetExpiration(expiration(*expiresIn))
+		}
+		c, err := a.v2(ctx)
+		if err != nil {
+			return err
+		}
+		t, _, err := tokenv2.PatchWithOpts(ctx, c, args[0], opts)
+		if err != nil {
+			return err
+		}
+
+		return a.print(t, tokenV2Columns...)
+	}
+}
+
+func tokensV2Audit(fs *flag.FlagSet) runFunc {
+	unusedDays := fs.Int("unused-days", audit.DefaultUnusedDays, "days without usage to report a token as unused")
+	expiringDays := fs.Int("expiring-days", audit.DefaultExpiringDays, "days before expiration to report a token as expiring")
+	reportFormat := fs.String("format", "", "report format: table, json or csv, overrides the output format")
+	hooks := webhookFlags(fs)
+
+	return func(ctx context.Context, a *app, args []string) error {
+		if err := expectArgs(args); err != nil {
+			return err
+		}
+		c2, err := a.v2(ctx)
+		if err != nil {
+			return err
+		}
+		c1, err := a.v1(ctx)
+		if err != nil {
+			return err
+		}
+		report, err := audit.Run(ctx, c2, c1, &audit.Opts{
+			UnusedDays:   *unusedDays,
+			ExpiringDays: *expiringDays,
+		})
+		if err != nil {
+			return err
+		}
+		if err := hooks.notify(ctx, webhook.FromAuditReport(report)); err != nil {
+			return err
+		}
+		if *reportFormat != "" {
+			return report.Write(a.out, audit.Format(*reportFormat))
+		}
+		if a.opts.output == string(format.FormatTable) {
+			return report.Write(a.out, audit.FormatTable)
+		}
+
+		return a.print(report)
+	}
+}
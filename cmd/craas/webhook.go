@@ -0,0 +1,50 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/selectel/craas-go/pkg/webhook"
+)
+
+// envWebhookSecret is an environment variable with the webhook secret.
+const envWebhookSecret = "CRAAS_WEBHOOK_SECRET"
+
+// webhookOpts represents flags of commands sending notifications.
+type webhookOpts struct {
+	urls     []string
+	format   string
+	template string
+	secret   string
+}
+
+// webhookFlags registers flags shared by commands sending notifications.
+func webhookFlags(fs *flag.FlagSet) *webhookOpts {
+	o := &webhookOpts{}
+	fs.Var((*stringList)(&o.urls), "webhook", "URL to post notifications to, can be repeated")
+	fs.StringVar(&o.format, "webhook-format", string(webhook.FormatJSON), "webhook payload format: json, slack or mattermost")
+	fs.StringVar(&o.template, "webhook-template", "", "text/template of the webhook body")
+	fs.StringVar(&o.secret, "webhook-secret", os.Getenv(envWebhookSecret), "HMAC key of webhook signatures (env "+envWebhookSecret+")")
+
+	return o
+}
+
+// notify sends the notification to every webhook and returns the first error.
+func (o *webhookOpts) notify(ctx context.Context, n *webhook.Notification) error {
+	var firstErr error
+	for _, url := range o.urls {
+		hook := &webhook.Webhook{
+			URL:      url,
+			Format:   webhook.Format(o.format),
+			Template: o.template,
+			Secret:   o.secret,
+		}
+		if err := hook.Send(ctx, n); err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("webhook %s: %w", url, err)
+		}
+	}
+
+	return firstErr
+}
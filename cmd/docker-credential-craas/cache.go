@@ -0,0 +1,140 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/selectel/craas-go/pkg/atomicfile"
+)
+
+const (
+	// lockTimeout is a maximum time to wait for another helper invocation.
+	lockTimeout = 30 * time.Second
+
+	// lockRetryInterval is a period between attempts to take the cache lock.
+	lockRetryInterval = 50 * time.Millisecond
+
+	// lockStaleAge is an age the lock file of a crashed invocation is removed after.
+	lockStaleAge = 2 * time.Minute
+)
+
+// errCacheLocked is returned when another invocation holds the cache lock for too long.
+var errCacheLocked = errors.New("timed out waiting for the credentials cache lock")
+
+// credential represents cached registry credentials.
+type credential struct {
+	Username string `json:"username"`
+	Secret   string `json:"secret"`
+
+	// ExpiresAt is zero for credentials stored by Docker that never expire.
+	ExpiresAt time.Time `json:"expiresAt,omitempty"`
+
+	// TokenID is an ID of a minted v2 token required to refresh it.
+	TokenID string `json:"tokenId,omitempty"`
+
+	// Minted is set for credentials created by the helper.
+	Minted bool `json:"minted,omitempty"`
+}
+
+// expiresWithin reports whether the credential expires within the duration.
+func (c *credential) expiresWithin(now time.Time, d time.Duration) bool {
+	return !c.ExpiresAt.IsZero() && !now.Add(d).Before(c.ExpiresAt)
+}
+
+// cache is a file-backed credentials storage keyed by a server URL.
+type cache struct {
+	path    string
+	Entries map[string]*credential `json:"entries"`
+}
+
+// loadCache reads the credentials cache, a missing file is an empty cache.
+func loadCache(path string) (*cache, error) {
+	c := &cache{
+		path:    path,
+		Entries: make(map[string]*credential),
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, c); err != nil {
+		return nil, err
+	}
+	if c.Entries == nil {
+		c.Entries = make(map[string]*credential)
+	}
+
+	return c, nil
+}
+
+// save atomically replaces the cache file, so that concurrent helper
+// invocations never read a partially written file.
+func (c *cache) save() error {
+	data, err := json.MarshalIndent(c, "", "\t")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0o700); err != nil {
+		return err
+	}
+
+	return atomicfile.WriteFile(c.path, data, 0o600)
+}
+
+// reload replaces the entries with the ones of the cache file,
+// which another helper invocation may have updated.
+func (c *cache) reload() error {
+	loaded, err := loadCache(c.path)
+	if err != nil {
+		return err
+	}
+	c.Entries = loaded.Entries
+
+	return nil
+}
+
+// lock creates a lock file next to the cache and returns a function removing it.
+// Helper invocations of parallel docker commands wait for each other, so only
+// one of them mints a token and the others read it from the cache.
+// A lock file left by a crashed invocation is removed after lockStaleAge.
+func (c *cache) lock(ctx context.Context) (func(), error) {
+	lockPath := c.path + ".lock"
+	if err := os.MkdirAll(filepath.Dir(lockPath), 0o700); err != nil {
+		return nil, err
+	}
+
+	deadline := time.Now().Add(lockTimeout)
+	for {
+		f, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+		if err == nil {
+			f.Close()
+
+			return func() { os.Remove(lockPath) }, nil
+		}
+		if !errors.Is(err, fs.ErrExist) {
+			return nil, err
+		}
+		if info, err := os.Stat(lockPath); err == nil && time.Since(info.ModTime()) > lockStaleAge {
+			os.Remove(lockPath)
+
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, errCacheLocked
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(lockRetryInterval):
+		}
+	}
+}
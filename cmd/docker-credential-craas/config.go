@@ -0,0 +1,118 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/selectel/craas-go/pkg/dockerconfig"
+)
+
+const (
+	defaultEndpoint = "https://cr.selcloud.ru/api"
+	defaultTTL      = 12 * time.Hour
+)
+
+// Token API versions the helper mints credentials with.
+const (
+	apiV1 = "v1"
+	apiV2 = "v2"
+)
+
+// config represents the helper configuration read from environment variables.
+type config struct {
+	token      string
+	endpoint   string
+	apiVersion string
+	ttl        string
+	readWrite  bool
+	registries []string
+	hosts      []string
+	cachePath  string
+}
+
+// loadConfig reads the helper configuration from environment variables.
+func loadConfig() (*config, error) {
+	cfg := &config{
+		token:      os.Getenv("CRAAS_TOKEN"),
+		endpoint:   os.Getenv("CRAAS_ENDPOINT"),
+		apiVersion: os.Getenv("CRAAS_API_VERSION"),
+		ttl:        os.Getenv("CRAAS_TOKEN_TTL"),
+		readWrite:  os.Getenv("CRAAS_TOKEN_READ_WRITE") == "true",
+		cachePath:  os.Getenv("CRAAS_CREDENTIALS_FILE"),
+	}
+	if cfg.apiVersion == "" {
+		cfg.apiVersion = apiV1
+	}
+	if cfg.apiVersion != apiV1 && cfg.apiVersion != apiV2 {
+		return nil, fmt.Errorf("invalid CRAAS_API_VERSION %q, expected %s or %s", cfg.apiVersion, apiV1, apiV2)
+	}
+	if cfg.endpoint == "" {
+		cfg.endpoint = defaultEndpoint
+	}
+	cfg.endpoint = strings.TrimSuffix(cfg.endpoint, "/")
+	// A version in the endpoint is accepted only if it matches CRAAS_API_VERSION.
+	for _, version := range []string{apiV1, apiV2} {
+		if !strings.HasSuffix(cfg.endpoint, "/"+version) {
+			continue
+		}
+		if version != cfg.apiVersion {
+			return nil, fmt.Errorf("CRAAS_ENDPOINT version %s doesn't match CRAAS_API_VERSION %s", version, cfg.apiVersion)
+		}
+		cfg.endpoint = strings.TrimSuffix(cfg.endpoint, "/"+version)
+	}
+
+	for _, name := range strings.Split(os.Getenv("CRAAS_TOKEN_REGISTRIES"), ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			cfg.registries = append(cfg.registries, name)
+		}
+	}
+
+	hosts := os.Getenv("CRAAS_REGISTRY_HOSTS")
+	if hosts == "" {
+		hosts = dockerconfig.DefaultRegistryHost
+	}
+	for _, host := range strings.Split(hosts, ",") {
+		if host = normalizeServerURL(host); host != "" {
+			cfg.hosts = append(cfg.hosts, host)
+		}
+	}
+
+	if cfg.cachePath == "" {
+		dir, err := os.UserCacheDir()
+		if err != nil {
+			return nil, err
+		}
+		cfg.cachePath = filepath.Join(dir, "craas", "credentials.json")
+	}
+
+	return cfg, nil
+}
+
+// isManaged reports whether credentials for the server are minted by the helper.
+func (cfg *config) isManaged(serverURL string) bool {
+	for _, host := range cfg.hosts {
+		if host == serverURL {
+			return true
+		}
+	}
+
+	return false
+}
+
+// normalizeServerURL strips a scheme, a path and a trailing slash from
+// the server URL, so that "https://cr.selcloud.ru/" and "cr.selcloud.ru"
+// refer to the same credentials.
+func normalizeServerURL(serverURL string) string {
+	serverURL = strings.TrimSpace(serverURL)
+	if i := strings.Index(serverURL, "://"); i >= 0 {
+		serverURL = serverURL[i+3:]
+	}
+	if i := strings.Index(serverURL, "/"); i >= 0 {
+		serverURL = serverURL[:i]
+	}
+
+	return strings.ToLower(serverURL)
+}
@@ -0,0 +1,226 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"time"
+)
+
+// refreshMargin is a period before expiration when cached credentials are refreshed.
+const refreshMargin = 10 * time.Minute
+
+// errCredentialsNotFound is the message Docker expects when there are no credentials.
+var errCredentialsNotFound = errors.New("credentials not found in native keychain")
+
+// credentials represents the credential helper protocol payload.
+type credentials struct {
+	ServerURL string `json:"ServerURL"`
+	Username  string `json:"Username"`
+	Secret    string `json:"Secret"`
+}
+
+// helper implements the credential helper actions.
+type helper struct {
+	cfg    *config
+	cache  *cache
+	minter minter
+	now    func() time.Time
+}
+
+func newHelper(cfg *config) (*helper, error) {
+	c, err := loadCache(cfg.cachePath)
+	if err != nil {
+		return nil, err
+	}
+
+	return &helper{
+		cfg:   cfg,
+		cache: c,
+		now:   time.Now,
+	}, nil
+}
+
+// getMinter lazily creates a minter, so that actions that don't mint
+// credentials work without API access.
+func (h *helper) getMinter() (minter, error) {
+	if h.minter == nil {
+		m, err := newMinter(h.cfg)
+		if err != nil {
+			return nil, err
+		}
+		h.minter = m
+	}
+
+	return h.minter, nil
+}
+
+// get prints credentials for the server URL read from the input.
+func (h *helper) get(ctx context.Context, in io.Reader, out io.Writer) error {
+	serverURL, err := readServerURL(in)
+	if err != nil {
+		return err
+	}
+
+	cred, err := h.lookup(ctx, serverURL)
+	if err != nil {
+		return err
+	}
+
+	return json.NewEncoder(out).Encode(credentials{
+		ServerURL: serverURL,
+		Username:  cred.Username,
+		Secret:    cred.Secret,
+	})
+}
+
+// lookup returns cached credentials, refreshing or minting them if needed.
+// The cache is locked and reloaded before credentials are refreshed or minted,
+// so parallel invocations don't mint a token each.
+func (h *helper) lookup(ctx context.Context, serverURL string) (*credential, error) {
+	key := normalizeServerURL(serverURL)
+	if cached, ok := h.cache.Entries[key]; ok && !cached.expiresWithin(h.now(), refreshMargin) {
+		return cached, nil
+	}
+
+	unlock, err := h.cache.lock(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	if err := h.cache.reload(); err != nil {
+		return nil, err
+	}
+
+	cached, ok := h.cache.Entries[key]
+	now := h.now()
+	if ok && !cached.expiresWithin(now, refreshMargin) {
+		return cached, nil
+	}
+	if !h.cfg.isManaged(key) {
+		if ok && !cached.expiresWithin(now, 0) {
+			return cached, nil
+		}
+
+		return nil, errCredentialsNotFound
+	}
+
+	m, err := h.getMinter()
+	if err != nil {
+		return nil, err
+	}
+
+	var cred *credential
+	if ok && cached.Minted && !cached.expiresWithin(now, 0) {
+		// Fall back to a new token if the cached one can't be refreshed.
+		cred, _ = m.refresh(ctx, cached)
+	}
+	if cred == nil {
+		if cred, err = m.mint(ctx); err != nil {
+			return nil, err
+		}
+		if ok && cached.Minted {
+			// The replaced token isn't cached anymore, so it's revoked instead of
+			// being left orphaned. It expires anyway, so a failure isn't fatal.
+			_ = m.revoke(ctx, cached)
+		}
+	}
+
+	h.cache.Entries[key] = cred
+	if err := h.cache.save(); err != nil {
+		return nil, err
+	}
+
+	return cred, nil
+}
+
+// store saves credentials provided by Docker.
+func (h *helper) store(ctx context.Context, in io.Reader) error {
+	var creds credentials
+	if err := json.NewDecoder(in).Decode(&creds); err != nil {
+		return err
+	}
+	key := normalizeServerURL(creds.ServerURL)
+	if key == "" {
+		return errors.New("missing server URL")
+	}
+
+	unlock, err := h.cache.lock(ctx)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	if err := h.cache.reload(); err != nil {
+		return err
+	}
+	h.cache.Entries[key] = &credential{
+		Username: creds.Username,
+		Secret:   creds.Secret,
+	}
+
+	return h.cache.save()
+}
+
+// erase removes credentials of the server URL and revokes minted tokens.
+func (h *helper) erase(ctx context.Context, in io.Reader) error {
+	serverURL, err := readServerURL(in)
+	if err != nil {
+		return err
+	}
+	key := normalizeServerURL(serverURL)
+
+	unlock, err := h.cache.lock(ctx)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	if err := h.cache.reload(); err != nil {
+		return err
+	}
+	cached, ok := h.cache.Entries[key]
+	if !ok {
+		return errCredentialsNotFound
+	}
+
+	if cached.Minted && !cached.expiresWithin(h.now(), 0) {
+		m, err := h.getMinter()
+		if err == nil {
+			// The token expires anyway, so a failed revocation isn't fatal.
+			_ = m.revoke(ctx, cached)
+		}
+	}
+	delete(h.cache.Entries, key)
+
+	return h.cache.save()
+}
+
+// list prints usernames of all cached credentials keyed by server URL.
+func (h *helper) list(out io.Writer) error {
+	now := h.now()
+	result := make(map[string]string, len(h.cache.Entries))
+	for serverURL, cred := range h.cache.Entries {
+		if cred.expiresWithin(now, 0) {
+			continue
+		}
+		result[serverURL] = cred.Username
+	}
+
+	return json.NewEncoder(out).Encode(result)
+}
+
+// readServerURL reads a server URL passed on the input.
+func readServerURL(in io.Reader) (string, error) {
+	line, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+	serverURL := strings.TrimSpace(line)
+	if serverURL == "" {
+		return "", errors.New("missing server URL")
+	}
+
+	return serverURL, nil
+}
@@ -0,0 +1,298 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/selectel/craas-go/pkg/testutils"
+	tokenv2 "github.com/selectel/craas-go/pkg/v2/token"
+)
+
+const testTokenID = `CRgAAAAAWiMnwN63eyASwQk8a3DBPRPirT9fWQTx`
+
+func newTestHelper(t *testing.T, endpoint string) *helper {
+	t.Helper()
+
+	h, err := newHelper(&config{
+		token:      testutils.TokenID,
+		endpoint:   endpoint,
+		apiVersion: apiV1,
+		hosts:      []string{"cr.selcloud.ru"},
+		cachePath:  filepath.Join(t.TempDir(), "credentials.json"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return h
+}
+
+func TestHelperGetMintsAndCaches(t *testing.T) {
+	createCalls := 0
+	testEnv := testutils.SetupTestEnv()
+	defer testEnv.TearDownTestEnv()
+
+	expiresAt := time.Now().Add(12 * time.Hour).Unix()
+	testEnv.Mux.HandleFunc("/api/v1/token", func(w http.ResponseWriter, r *http.Request) {
+		createCalls++
+		w.Header().Add("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"token": %q, "expireAt": %d, "expireIn": 43200}`, testTokenID, expiresAt)
+	})
+
+	h := newTestHelper(t, testEnv.Server.URL+"/api")
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		var out bytes.Buffer
+		if err := h.get(ctx, strings.NewReader("https://cr.selcloud.ru\n"), &out); err != nil {
+			t.Fatal(err)
+		}
+		var actual credentials
+		if err := json.Unmarshal(out.Bytes(), &actual); err != nil {
+			t.Fatal(err)
+		}
+		expected := credentials{ServerURL: "https://cr.selcloud.ru", Username: "token", Secret: testTokenID}
+		if actual != expected {
+			t.Fatalf("expected %#v, but got %#v", expected, actual)
+		}
+	}
+	if createCalls != 1 {
+		t.Fatalf("expected token to be created once, but got %d calls", createCalls)
+	}
+
+	// A new helper reads the credentials from the cache file.
+	reloaded, err := newHelper(h.cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := reloaded.lookup(ctx, "cr.selcloud.ru"); err != nil {
+		t.Fatal(err)
+	}
+	if createCalls != 1 {
+		t.Fatalf("expected cached token to be reused, but got %d calls", createCalls)
+	}
+}
+
+func TestHelperGetParallelMintsOnce(t *testing.T) {
+	var createCalls int32
+	testEnv := testutils.SetupTestEnv()
+	defer testEnv.TearDownTestEnv()
+
+	expiresAt := time.Now().Add(12 * time.Hour).Unix()
+	testEnv.Mux.HandleFunc("/api/v1/token", func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&createCalls, 1)
+		time.Sleep(50 * time.Millisecond)
+		w.Header().Add("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"token": %q, "expireAt": %d, "expireIn": 43200}`, testTokenID, expiresAt)
+	})
+
+	first := newTestHelper(t, testEnv.Server.URL+"/api")
+	second, err := newHelper(first.cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 2)
+	for _, h := range []*helper{first, second} {
+		wg.Add(1)
+		go func(h *helper) {
+			defer wg.Done()
+			_, err := h.lookup(context.Background(), "cr.selcloud.ru")
+			errs <- err
+		}(h)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if calls := atomic.LoadInt32(&createCalls); calls != 1 {
+		t.Fatalf("expected token to be created once, but got %d calls", calls)
+	}
+}
+
+func TestHelperGetRefreshesExpiring(t *testing.T) {
+	refreshCalls := 0
+	testEnv := testutils.SetupTestEnv()
+	defer testEnv.TearDownTestEnv()
+
+	expiresAt := time.Now().Add(12 * time.Hour).Unix()
+	testEnv.Mux.HandleFunc("/api/v1/token/"+testTokenID+"/refresh", func(w http.ResponseWriter, r *http.Request) {
+		refreshCalls++
+		w.Header().Add("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"expireAt": %d, "expireIn": 43200}`, expiresAt)
+	})
+
+	h := newTestHelper(t, testEnv.Server.URL+"/api")
+	h.cache.Entries["cr.selcloud.ru"] = &credential{
+		Username:  "token",
+		Secret:    testTokenID,
+		ExpiresAt: time.Now().Add(time.Minute),
+		Minted:    true,
+	}
+	if err := h.cache.save(); err != nil {
+		t.Fatal(err)
+	}
+
+	cred, err := h.lookup(context.Background(), "cr.selcloud.ru")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if refreshCalls != 1 {
+		t.Fatalf("expected token to be refreshed once, but got %d calls", refreshCalls)
+	}
+	if cred.Secret != testTokenID || cred.ExpiresAt.Unix() != expiresAt {
+		t.Fatalf("unexpected refreshed credential %#v", cred)
+	}
+}
+
+func TestHelperGetRevokesUnrefreshable(t *testing.T) {
+	const oldTokenID = "CRgAAAAAOldTokenOldTokenOldTokenOldToken"
+	revokeCalls := 0
+	testEnv := testutils.SetupTestEnv()
+	defer testEnv.TearDownTestEnv()
+
+	expiresAt := time.Now().Add(12 * time.Hour).Unix()
+	testEnv.Mux.HandleFunc("/api/v1/token/"+oldTokenID+"/refresh", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	})
+	testEnv.Mux.HandleFunc("/api/v1/token/"+oldTokenID, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodDelete {
+			t.Errorf("expected %s method but got %s", http.MethodDelete, r.Method)
+		}
+		revokeCalls++
+		w.WriteHeader(http.StatusNoContent)
+	})
+	testEnv.Mux.HandleFunc("/api/v1/token", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"token": %q, "expireAt": %d, "expireIn": 43200}`, testTokenID, expiresAt)
+	})
+
+	h := newTestHelper(t, testEnv.Server.URL+"/api")
+	h.cache.Entries["cr.selcloud.ru"] = &credential{
+		Username:  "token",
+		Secret:    oldTokenID,
+		ExpiresAt: time.Now().Add(time.Minute),
+		Minted:    true,
+	}
+	if err := h.cache.save(); err != nil {
+		t.Fatal(err)
+	}
+
+	cred, err := h.lookup(context.Background(), "cr.selcloud.ru")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cred.Secret != testTokenID {
+		t.Fatalf("expected a new token, but got %#v", cred)
+	}
+	if revokeCalls != 1 {
+		t.Fatalf("expected the old token to be revoked once, but got %d calls", revokeCalls)
+	}
+}
+
+func TestHelperGetMintsReadOnlyV2(t *testing.T) {
+	createCalls := 0
+	testEnv := testutils.SetupTestEnv()
+	defer testEnv.TearDownTestEnv()
+
+	testEnv.Mux.HandleFunc("/api/v1/registries", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Content-Type", "application/json")
+		fmt.Fprint(w, `[{"id": "888af692-c646-4b76-a234-81ca9b5bcafe", "name": "test-registry", "status": "ACTIVE"}]`)
+	})
+	testEnv.Mux.HandleFunc("/api/v2/tokens", func(w http.ResponseWriter, r *http.Request) {
+		createCalls++
+		var tkn tokenv2.TokenV2
+		if err := json.NewDecoder(r.Body).Decode(&tkn); err != nil {
+			t.Errorf("unable to decode the request body: %v", err)
+		}
+		expected := tokenv2.Scope{RegistryIDs: []string{"888af692-c646-4b76-a234-81ca9b5bcafe"}}
+		if !reflect.DeepEqual(expected, tkn.Scope) {
+			t.Errorf("expected %#v scope, but got %#v", expected, tkn.Scope)
+		}
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprintf(w, `{"id": "t-helper", "token": %q, "expiration": {"isSet": true, "expiresAt": "2099-01-01T00:00:00Z"}}`, testTokenID)
+	})
+
+	h := newTestHelper(t, testEnv.Server.URL+"/api")
+	h.cfg.apiVersion = apiV2
+	h.cfg.registries = []string{"test-registry"}
+	cred, err := h.lookup(context.Background(), "cr.selcloud.ru")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if createCalls != 1 || cred.TokenID != "t-helper" || cred.Secret != testTokenID {
+		t.Fatalf("unexpected minted credential %#v after %d calls", cred, createCalls)
+	}
+}
+
+func TestHelperStoreListErase(t *testing.T) {
+	h := newTestHelper(t, "http://localhost/api")
+	ctx := context.Background()
+
+	stored := `{"ServerURL": "https://ghcr.io", "Username": "user", "Secret": "pass"}`
+	if err := h.store(ctx, strings.NewReader(stored)); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	if err := h.list(&out); err != nil {
+		t.Fatal(err)
+	}
+	if strings.TrimSpace(out.String()) != `{"ghcr.io":"user"}` {
+		t.Fatalf("unexpected list output %s", out.String())
+	}
+
+	out.Reset()
+	if err := h.get(ctx, strings.NewReader("ghcr.io"), &out); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), `"Secret":"pass"`) {
+		t.Fatalf("unexpected get output %s", out.String())
+	}
+
+	if err := h.erase(ctx, strings.NewReader("ghcr.io")); err != nil {
+		t.Fatal(err)
+	}
+	if err := h.get(ctx, strings.NewReader("ghcr.io"), &out); err != errCredentialsNotFound {
+		t.Fatalf("expected %v error, but got %v", errCredentialsNotFound, err)
+	}
+}
+
+func TestLoadConfigAPIVersion(t *testing.T) {
+	t.Setenv("CRAAS_CREDENTIALS_FILE", filepath.Join(t.TempDir(), "credentials.json"))
+
+	t.Setenv("CRAAS_API_VERSION", "v2")
+	t.Setenv("CRAAS_ENDPOINT", "https://cr.selcloud.ru/api/v2/")
+	cfg, err := loadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.apiVersion != apiV2 || cfg.endpoint != "https://cr.selcloud.ru/api" {
+		t.Fatalf("unexpected API settings: %s %s", cfg.apiVersion, cfg.endpoint)
+	}
+
+	t.Setenv("CRAAS_API_VERSION", "")
+	if _, err := loadConfig(); err == nil {
+		t.Fatal("expected an error for the endpoint version not matching the default v1")
+	}
+
+	t.Setenv("CRAAS_API_VERSION", "v3")
+	t.Setenv("CRAAS_ENDPOINT", "")
+	if _, err := loadConfig(); err == nil {
+		t.Fatal("expected an error for an unsupported API version")
+	}
+}
@@ -0,0 +1,89 @@
+// Command docker-credential-craas implements the Docker credential helper
+// protocol for the Selectel Container Registry.
+//
+// Registry credentials are minted with the CRaaS token API, cached locally
+// until they expire and refreshed automatically. Add the helper to the
+// Docker config to use it:
+//
+//	{
+//	    "credHelpers": {
+//	        "cr.selcloud.ru": "craas"
+//	    }
+//	}
+//
+// The helper is configured with environment variables:
+//
+//	CRAAS_TOKEN            - project token used to access the CRaaS API (required to mint credentials).
+//	CRAAS_ENDPOINT         - CRaaS API endpoint without a version (default https://cr.selcloud.ru/api).
+//	CRAAS_API_VERSION      - token API credentials are minted with: "v1" or "v2" (default v1).
+//	CRAAS_TOKEN_TTL        - lifetime of minted credentials: "12h" or "1y" for v1, any Go duration for v2 (default 12h).
+//	CRAAS_TOKEN_READ_WRITE - mint v2 tokens allowing pushes if set to "true", they are read-only by default.
+//	CRAAS_TOKEN_REGISTRIES - comma-separated registry names v2 tokens are scoped to (default all registries).
+//	CRAAS_REGISTRY_HOSTS   - comma-separated registry hosts credentials are minted for (default cr.selcloud.ru).
+//	CRAAS_CREDENTIALS_FILE - path of the credentials cache (default <user cache dir>/craas/credentials.json).
+//
+// Docker passes only a registry host to the helper, so v2 tokens are scoped to
+// CRAAS_TOKEN_REGISTRIES rather than to the registry of the pulled image.
+// v1 tokens can't be scoped and grant read-write access to the whole project.
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+)
+
+const usage = "Usage: docker-credential-craas <get|store|erase|list|version>"
+
+// version of the helper reported by the version action.
+const version = "0.1.0"
+
+func main() {
+	if len(os.Args) != 2 {
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(1)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if err := run(ctx, os.Args[1], os.Stdin, os.Stdout); err != nil {
+		// Credential helpers report errors on stdout.
+		fmt.Fprintln(os.Stdout, err)
+		stop()
+		os.Exit(1)
+	}
+}
+
+// run executes a credential helper action.
+func run(ctx context.Context, action string, in io.Reader, out io.Writer) error {
+	if action == "version" {
+		fmt.Fprintln(out, "docker-credential-craas "+version)
+
+		return nil
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	h, err := newHelper(cfg)
+	if err != nil {
+		return err
+	}
+
+	switch action {
+	case "get":
+		return h.get(ctx, in, out)
+	case "store":
+		return h.store(ctx, in)
+	case "erase":
+		return h.erase(ctx, in)
+	case "list":
+		return h.list(out)
+	}
+
+	return fmt.Errorf("unknown action %q\n%s", action, usage)
+}
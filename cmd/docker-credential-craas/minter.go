@@ -0,0 +1,185 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/selectel/craas-go/pkg/v1/client"
+	"github.com/selectel/craas-go/pkg/v1/token"
+	clientv2 "github.com/selectel/craas-go/pkg/v2/client"
+	tokenv2 "github.com/selectel/craas-go/pkg/v2/token"
+)
+
+// minter creates and refreshes registry credentials.
+type minter interface {
+	mint(ctx context.Context) (*credential, error)
+	refresh(ctx context.Context, cred *credential) (*credential, error)
+	revoke(ctx context.Context, cred *credential) error
+}
+
+// newMinter returns a minter for the configured token API version.
+func newMinter(cfg *config) (minter, error) {
+	if cfg.token == "" {
+		return nil, errors.New("CRAAS_TOKEN is not set")
+	}
+
+	if cfg.apiVersion == apiV2 {
+		c, err := clientv2.NewCRaaSClientV2(cfg.token, cfg.endpoint+"/"+apiV2)
+		if err != nil {
+			return nil, err
+		}
+		registriesClient, err := client.NewCRaaSClientV1(cfg.token, cfg.endpoint+"/"+apiV1)
+		if err != nil {
+			return nil, err
+		}
+		ttl := defaultTTL
+		if cfg.ttl != "" {
+			if ttl, err = time.ParseDuration(cfg.ttl); err != nil {
+				return nil, fmt.Errorf("invalid CRAAS_TOKEN_TTL: %w", err)
+			}
+		}
+
+		return &v2Minter{
+			client:           c,
+			registriesClient: registriesClient,
+			ttl:              ttl,
+			readWrite:        cfg.readWrite,
+			registries:       cfg.registries,
+		}, nil
+	}
+
+	c, err := client.NewCRaaSClientV1(cfg.token, cfg.endpoint+"/"+apiV1)
+	if err != nil {
+		return nil, err
+	}
+	ttl := token.TTL12Hours
+	if cfg.ttl != "" {
+		ttl = token.TTL(cfg.ttl)
+	}
+
+	return &v1Minter{client: c, ttl: ttl}, nil
+}
+
+// v1Minter mints credentials with the v1 token API.
+type v1Minter struct {
+	client *client.ServiceClient
+	ttl    token.TTL
+}
+
+func (m *v1Minter) mint(ctx context.Context) (*credential, error) {
+	tkn, _, err := token.Create(ctx, m.client, &token.CreateOpts{TokenTTL: m.ttl})
+	if err != nil {
+		return nil, err
+	}
+
+	return v1Credential(tkn), nil
+}
+
+func (m *v1Minter) refresh(ctx context.Context, cred *credential) (*credential, error) {
+	tkn, _, err := token.Refresh(ctx, m.client, cred.Secret)
+	if err != nil {
+		return nil, err
+	}
+
+	return v1Credential(tkn), nil
+}
+
+func (m *v1Minter) revoke(ctx context.Context, cred *credential) error {
+	_, err := token.Revoke(ctx, m.client, cred.Secret)
+
+	return err
+}
+
+func v1Credential(tkn *token.Token) *credential {
+	username, secret := tkn.RegistryCredentials()
+
+	return &credential{
+		Username:  username,
+		Secret:    secret,
+		ExpiresAt: tkn.ExpirationTime(),
+		Minted:    true,
+	}
+}
+
+// v2Minter mints credentials with the v2 token API.
+// Tokens are read-only unless read-write access is requested and grant
+// access to the configured registries or to all of them if none are set.
+type v2Minter struct {
+	client           *clientv2.ServiceClient
+	registriesClient *client.ServiceClient
+	ttl              time.Duration
+	readWrite        bool
+	registries       []string
+}
+
+func (m *v2Minter) mint(ctx context.Context) (*credential, error) {
+	name := "docker-credential-craas"
+	if hostname, err := os.Hostname(); err == nil {
+		name += "-" + hostname
+	}
+	scope, err := m.scope(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tkn, _, err := tokenv2.Create(ctx, m.client, &tokenv2.TokenV2{
+		Name:       name,
+		Expiration: m.expiration(),
+		Scope:      scope,
+	}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return v2Credential(tkn, tkn.Token), nil
+}
+
+func (m *v2Minter) refresh(ctx context.Context, cred *credential) (*credential, error) {
+	tkn, _, err := tokenv2.Refresh(ctx, m.client, cred.TokenID, m.expiration())
+	if err != nil {
+		return nil, err
+	}
+
+	// Refresh extends the token lifetime without changing its secret.
+	return v2Credential(tkn, cred.Secret), nil
+}
+
+func (m *v2Minter) revoke(ctx context.Context, cred *credential) error {
+	_, err := tokenv2.Delete(ctx, m.client, cred.TokenID)
+
+	return err
+}
+
+// scope returns a scope of minted tokens, registry names are resolved to IDs.
+func (m *v2Minter) scope(ctx context.Context) (tokenv2.Scope, error) {
+	builder := tokenv2.NewScopeBuilder()
+	if m.readWrite {
+		builder.ReadWrite()
+	}
+	if len(m.registries) == 0 {
+		builder.AllRegistries()
+	} else {
+		builder.ForRegistryNames(m.registries...)
+	}
+
+	return builder.Resolve(ctx, m.registriesClient)
+}
+
+func (m *v2Minter) expiration() tokenv2.Expiration {
+	return tokenv2.Expiration{
+		IsSet:     true,
+		ExpiresAt: time.Now().Add(m.ttl).UTC(),
+	}
+}
+
+func v2Credential(tkn *tokenv2.TokenV2, secret string) *credential {
+	return &credential{
+		Username:  tokenv2.RegistryUsername,
+		Secret:    secret,
+		ExpiresAt: tkn.Expiration.ExpiresAt,
+		TokenID:   tkn.ID,
+		Minted:    true,
+	}
+}
@@ -0,0 +1,15 @@
+/*
+Package `atomicfile` provides a function for replacing files atomically.
+
+The data is written into a temporary file in the directory of the target,
+synced and renamed to the target, so readers see either the previous or
+the new content and never a partially written file.
+
+Example of writing a state file:
+
+	err := atomicfile.WriteFile("state.json", data, 0o600)
+	if err != nil {
+	    log.Fatal(err)
+	}
+*/
+package atomicfile
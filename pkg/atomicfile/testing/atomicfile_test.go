@@ -0,0 +1,49 @@
+package testing
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/selectel/craas-go/pkg/atomicfile"
+)
+
+func TestWriteFile(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "state.json")
+	if err := os.WriteFile(filename, []byte("old"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := atomicfile.WriteFile(filename, []byte("new"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "new" {
+		t.Fatalf("expected new, but got %s", data)
+	}
+	info, err := os.Stat(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0o644 {
+		t.Fatalf("expected %v mode, but got %v", os.FileMode(0o644), info.Mode().Perm())
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("expected temporary files to be removed, but got %d files", len(entries))
+	}
+}
+
+func TestWriteFileMissingDir(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "missing", "state.json")
+	if err := atomicfile.WriteFile(filename, []byte("new"), 0o600); err == nil {
+		t.Fatal("expected an error for a missing directory")
+	}
+}
@@ -0,0 +1,247 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// DefaultEndpoint is an API endpoint without a version used when a profile doesn't set one.
+	DefaultEndpoint = "https://cr.selcloud.ru/api"
+
+	// DefaultProfile is a name of the profile used when no profile is selected.
+	DefaultProfile = "default"
+
+	// EnvConfig is an environment variable with a path of the configuration file.
+	EnvConfig = "CRAAS_CONFIG"
+
+	// EnvProfile is an environment variable with a name of the selected profile.
+	EnvProfile = "CRAAS_PROFILE"
+
+	// EnvToken is an environment variable overriding a token of the profile.
+	EnvToken = "CRAAS_TOKEN"
+
+	// EnvEndpoint is an environment variable overriding an endpoint of the profile.
+	EnvEndpoint = "CRAAS_ENDPOINT"
+)
+
+// RegionEndpoints contains API endpoints of known regions,
+// the configuration file can define additional regions.
+var RegionEndpoints = map[string]string{
+	"ru-1": DefaultEndpoint,
+}
+
+var (
+	ErrProfileNotFound      = errors.New("profile not found")
+	ErrUnknownRegion        = errors.New("unknown region")
+	ErrEndpointAndRegion    = errors.New("endpoint and region are mutually exclusive")
+	ErrMultipleTokenSources = errors.New("only one token source can be set")
+	ErrNoToken              = errors.New("token is not set")
+	ErrInvalidRetry         = errors.New("invalid retry settings")
+)
+
+// Config represents the configuration file.
+type Config struct {
+	// DefaultProfile is a name of the profile used when no profile is selected.
+	DefaultProfile string `yaml:"defaultProfile"`
+
+	// Regions maps region names to API endpoints in addition to RegionEndpoints.
+	Regions map[string]string `yaml:"regions"`
+
+	// Profiles contains named profiles.
+	Profiles map[string]*Profile `yaml:"profiles"`
+}
+
+// Profile represents connection settings of an account and a region.
+type Profile struct {
+	// Endpoint is an API endpoint without a version.
+	Endpoint string `yaml:"endpoint"`
+
+	// Region is a name of the region the endpoint is taken from.
+	Region string `yaml:"region"`
+
+	// Token is a source of the token.
+	Token TokenConfig `yaml:"token"`
+
+	// Timeout is an HTTP request timeout.
+	Timeout time.Duration `yaml:"timeout"`
+
+	// Retry contains retry settings of failed requests.
+	Retry RetryConfig `yaml:"retry"`
+}
+
+// TokenConfig represents a source of the token, only one field can be set.
+type TokenConfig struct {
+	// Value is a literal token.
+	Value string `yaml:"value"`
+
+	// Env is a name of an environment variable with the token.
+	Env string `yaml:"env"`
+
+	// File is a path of a file with the token.
+	File string `yaml:"file"`
+
+	// Exec is a command printing the token to its standard output.
+	Exec []string `yaml:"exec"`
+
+	// Keystone contains credentials the token is issued with.
+	Keystone *KeystoneConfig `yaml:"keystone"`
+}
+
+// RetryConfig represents retry settings of failed idempotent requests.
+type RetryConfig struct {
+	// MaxAttempts is a maximum number of attempts of a request, requests aren't retried by default.
+	MaxAttempts int `yaml:"maxAttempts"`
+
+	// MinBackoff is a delay before the first retry, 500ms by default.
+	MinBackoff time.Duration `yaml:"minBackoff"`
+
+	// MaxBackoff is a maximum delay between retries, 10s by default.
+	MaxBackoff time.Duration `yaml:"maxBackoff"`
+}
+
+// DefaultPath returns a path of the configuration file.
+func DefaultPath() (string, error) {
+	if path := os.Getenv(EnvConfig); path != "" {
+		return path, nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, "craas", "config.yaml"), nil
+}
+
+// Load reads the configuration file.
+func Load(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse %s: %w", path, err)
+	}
+
+	return cfg, nil
+}
+
+// LoadDefault reads the configuration file from the default path,
+// an empty configuration is returned when the file doesn't exist.
+func LoadDefault() (*Config, error) {
+	path, err := DefaultPath()
+	if err != nil {
+		return &Config{}, nil //nolint:nilerr // no config directory means no profiles.
+	}
+	cfg, err := Load(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return &Config{}, nil
+	}
+
+	return cfg, err
+}
+
+// Parse parses and validates the configuration.
+func Parse(data []byte) (*Config, error) {
+	cfg := &Config{}
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return nil, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// Validate checks profiles of the configuration.
+func (c *Config) Validate() error {
+	for name, p := range c.Profiles {
+		if p == nil {
+			return fmt.Errorf("profile %q is empty", name)
+		}
+		if err := p.Validate(); err != nil {
+			return fmt.Errorf("profile %q: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+// Validate checks settings of the profile.
+func (p *Profile) Validate() error {
+	if p.Endpoint != "" && p.Region != "" {
+		return ErrEndpointAndRegion
+	}
+	if err := p.Token.Validate(); err != nil {
+		return err
+	}
+	if p.Retry.MaxAttempts < 0 || p.Retry.MinBackoff < 0 || p.Retry.MaxBackoff < 0 {
+		return ErrInvalidRetry
+	}
+	if p.Retry.MaxBackoff > 0 && p.Retry.MinBackoff > p.Retry.MaxBackoff {
+		return fmt.Errorf("%w: minBackoff is greater than maxBackoff", ErrInvalidRetry)
+	}
+
+	return nil
+}
+
+// Validate checks that at most one token source is set.
+func (t *TokenConfig) Validate() error {
+	sources := make([]string, 0, 1)
+	if t.Value != "" {
+		sources = append(sources, "value")
+	}
+	if t.Env != "" {
+		sources = append(sources, "env")
+	}
+	if t.File != "" {
+		sources = append(sources, "file")
+	}
+	if len(t.Exec) > 0 {
+		sources = append(sources, "exec")
+	}
+	if t.Keystone != nil {
+		sources = append(sources, "keystone")
+		if err := t.Keystone.Validate(); err != nil {
+			return err
+		}
+	}
+	if len(sources) > 1 {
+		return fmt.Errorf("%w: %s", ErrMultipleTokenSources, strings.Join(sources, ", "))
+	}
+
+	return nil
+}
+
+// ProfileNames returns names of the profiles.
+func (c *Config) ProfileNames() []string {
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// regionEndpoint returns an endpoint of the region.
+func (c *Config) regionEndpoint(region string) (string, error) {
+	if endpoint, ok := c.Regions[region]; ok {
+		return endpoint, nil
+	}
+	if endpoint, ok := RegionEndpoints[region]; ok {
+		return endpoint, nil
+	}
+
+	return "", fmt.Errorf("%w: %q", ErrUnknownRegion, region)
+}
@@ -0,0 +1,52 @@
+/*
+Package `config` provides a set of functions for loading named connection
+profiles from a configuration file and building ready v1 and v2 ServiceClients.
+
+The default file is ~/.config/craas/config.yaml, it can be changed with
+the CRAAS_CONFIG environment variable:
+
+	defaultProfile: prod
+	regions:
+	  ru-1: https://cr.selcloud.ru/api
+	profiles:
+	  prod:
+	    region: ru-1
+	    timeout: 30s
+	    retry:
+	      maxAttempts: 3
+	    token:
+	      keystone:
+	        username: deployer
+	        passwordEnv: CRAAS_KEYSTONE_PASSWORD
+	        domain: "123456"
+	        projectID: 1a2b3c4d5e6f
+	  dev:
+	    endpoint: https://cr.selcloud.ru/api
+	    token:
+	      exec: ["pass", "show", "craas/dev"]
+
+A token is taken from exactly one source of the profile: a literal value,
+an environment variable, a file, an output of a command or Keystone credentials.
+The CRAAS_PROFILE, CRAAS_TOKEN and CRAAS_ENDPOINT environment variables
+override the selected profile, its token and its endpoint.
+
+Example of building clients of the selected profile:
+
+	cfg, err := config.LoadDefault()
+	if err != nil {
+	    log.Fatal(err)
+	}
+	session, err := cfg.Select("", nil)
+	if err != nil {
+	    log.Fatal(err)
+	}
+	craasClient, err := session.ClientV1(ctx)
+	if err != nil {
+	    log.Fatal(err)
+	}
+	registries, _, err := registry.List(ctx, craasClient)
+	if err != nil {
+	    log.Fatal(err)
+	}
+*/
+package config
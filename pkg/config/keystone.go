@@ -0,0 +1,204 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/selectel/craas-go/pkg/svc"
+)
+
+// DefaultKeystoneURL is an identity endpoint used when credentials don't set one.
+const DefaultKeystoneURL = "https://cloud.api.selcloud.ru/identity/v3"
+
+// keystoneExpirySkew is a time before the expiration a Keystone token is reissued.
+const keystoneExpirySkew = 5 * time.Minute
+
+var (
+	ErrKeystoneCredentials = errors.New("invalid keystone credentials")
+	ErrKeystoneNoToken     = errors.New("keystone response doesn't contain a token")
+)
+
+// KeystoneConfig represents credentials of a project-scoped Keystone token.
+type KeystoneConfig struct {
+	// AuthURL is an identity endpoint, DefaultKeystoneURL by default.
+	AuthURL string `yaml:"authURL"`
+
+	// Username is a name of the service user.
+	Username string `yaml:"username"`
+
+	// Password is a password of the user.
+	Password string `yaml:"password"`
+
+	// PasswordEnv is a name of an environment variable with the password.
+	PasswordEnv string `yaml:"passwordEnv"`
+
+	// Domain is a name of the user domain, the account ID in Selectel.
+	Domain string `yaml:"domain"`
+
+	// ProjectID is an ID of the project the token is scoped to.
+	ProjectID string `yaml:"projectID"`
+
+	// ProjectName is a name of the project in the user domain, used when ProjectID is empty.
+	ProjectName string `yaml:"projectName"`
+}
+
+// Validate checks the credentials.
+func (k *KeystoneConfig) Validate() error {
+	switch {
+	case k.Username == "":
+		return fmt.Errorf("%w: username is empty", ErrKeystoneCredentials)
+	case k.Password == "" && k.PasswordEnv == "":
+		return fmt.Errorf("%w: password or passwordEnv is required", ErrKeystoneCredentials)
+	case k.Password != "" && k.PasswordEnv != "":
+		return fmt.Errorf("%w: password and passwordEnv are mutually exclusive", ErrKeystoneCredentials)
+	case k.Domain == "":
+		return fmt.Errorf("%w: domain is empty", ErrKeystoneCredentials)
+	case k.ProjectID == "" && k.ProjectName == "":
+		return fmt.Errorf("%w: projectID or projectName is required", ErrKeystoneCredentials)
+	}
+
+	return nil
+}
+
+// KeystoneToken issues project-scoped tokens with Keystone credentials
+// and reuses them until they are about to expire.
+type KeystoneToken struct {
+	cfg        KeystoneConfig
+	httpClient *http.Client
+
+	mu        sync.Mutex
+	token     string
+	expiresAt time.Time
+}
+
+// NewKeystoneToken returns a Keystone token source.
+// If the HTTP client is nil, the default client is used.
+func NewKeystoneToken(cfg *KeystoneConfig, httpClient *http.Client) *KeystoneToken {
+	if httpClient == nil {
+		httpClient = svc.NewHTTPClient()
+	}
+
+	return &KeystoneToken{cfg: *cfg, httpClient: httpClient}
+}
+
+// Token returns a cached token or issues a new one.
+func (k *KeystoneToken) Token(ctx context.Context) (string, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if k.token != "" && time.Until(k.expiresAt) > keystoneExpirySkew {
+		return k.token, nil
+	}
+	token, expiresAt, err := k.issue(ctx)
+	if err != nil {
+		return "", err
+	}
+	k.token, k.expiresAt = token, expiresAt
+
+	return token, nil
+}
+
+// keystoneAuthRequest represents a body of the password authentication request.
+type keystoneAuthRequest struct {
+	Auth struct {
+		Identity struct {
+			Methods  []string `json:"methods"`
+			Password struct {
+				User struct {
+					Name     string         `json:"name"`
+					Password string         `json:"password"`
+					Domain   keystoneDomain `json:"domain"`
+				} `json:"user"`
+			} `json:"password"`
+		} `json:"identity"`
+		Scope struct {
+			Project keystoneProject `json:"project"`
+		} `json:"scope"`
+	} `json:"auth"`
+}
+
+type keystoneDomain struct {
+	Name string `json:"name"`
+}
+
+type keystoneProject struct {
+	ID     string          `json:"id,omitempty"`
+	Name   string          `json:"name,omitempty"`
+	Domain *keystoneDomain `json:"domain,omitempty"`
+}
+
+// keystoneAuthResponse represents a body of the authentication response.
+type keystoneAuthResponse struct {
+	Token struct {
+		ExpiresAt time.Time `json:"expires_at"`
+	} `json:"token"`
+}
+
+func (k *KeystoneToken) issue(ctx context.Context) (string, time.Time, error) {
+	if err := k.cfg.Validate(); err != nil {
+		return "", time.Time{}, err
+	}
+	password := k.cfg.Password
+	if k.cfg.PasswordEnv != "" {
+		password = os.Getenv(k.cfg.PasswordEnv)
+		if password == "" {
+			return "", time.Time{}, fmt.Errorf("%w: environment variable %s is empty", ErrKeystoneCredentials, k.cfg.PasswordEnv)
+		}
+	}
+
+	var body keystoneAuthRequest
+	body.Auth.Identity.Methods = []string{"password"}
+	body.Auth.Identity.Password.User.Name = k.cfg.Username
+	body.Auth.Identity.Password.User.Password = password
+	body.Auth.Identity.Password.User.Domain.Name = k.cfg.Domain
+	if k.cfg.ProjectID != "" {
+		body.Auth.Scope.Project.ID = k.cfg.ProjectID
+	} else {
+		body.Auth.Scope.Project.Name = k.cfg.ProjectName
+		body.Auth.Scope.Project.Domain = &keystoneDomain{Name: k.cfg.Domain}
+	}
+	data, err := json.Marshal(body)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	authURL := k.cfg.AuthURL
+	if authURL == "" {
+		authURL = DefaultKeystoneURL
+	}
+	url := strings.TrimSuffix(authURL, "/") + "/auth/tokens"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", svc.UserAgent)
+
+	resp, err := k.httpClient.Do(req)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
+		return "", time.Time{}, fmt.Errorf("keystone authentication failed with status %d", resp.StatusCode)
+	}
+
+	token := resp.Header.Get("X-Subject-Token")
+	if token == "" {
+		return "", time.Time{}, ErrKeystoneNoToken
+	}
+	var result keystoneAuthResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", time.Time{}, fmt.Errorf("unable to decode keystone response: %w", err)
+	}
+
+	return token, result.Token.ExpiresAt, nil
+}
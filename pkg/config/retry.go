@@ -0,0 +1,126 @@
+package config
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultMinBackoff = 500 * time.Millisecond
+	defaultMaxBackoff = 10 * time.Second
+)
+
+// retryTransport retries idempotent requests failed with network errors
+// or with 429, 502, 503 and 504 statuses.
+type retryTransport struct {
+	next       http.RoundTripper
+	attempts   int
+	minBackoff time.Duration
+	maxBackoff time.Duration
+}
+
+func newRetryTransport(next http.RoundTripper, cfg RetryConfig) *retryTransport {
+	t := &retryTransport{
+		next:       next,
+		attempts:   cfg.MaxAttempts,
+		minBackoff: cfg.MinBackoff,
+		maxBackoff: cfg.MaxBackoff,
+	}
+	if t.minBackoff == 0 {
+		t.minBackoff = defaultMinBackoff
+	}
+	if t.maxBackoff == 0 {
+		t.maxBackoff = defaultMaxBackoff
+	}
+	if t.minBackoff > t.maxBackoff {
+		t.minBackoff = t.maxBackoff
+	}
+
+	return t
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !isIdempotent(req.Method) {
+		return t.next.RoundTrip(req)
+	}
+
+	for attempt := 1; ; attempt++ {
+		resp, err := t.next.RoundTrip(req)
+		if attempt >= t.attempts || !shouldRetry(resp, err) || req.Context().Err() != nil {
+			return resp, err
+		}
+		// A request body that can't be replayed is already consumed.
+		hasBody := req.Body != nil && req.Body != http.NoBody
+		if hasBody && req.GetBody == nil {
+			return resp, err
+		}
+		delay := t.backoff(attempt, resp)
+		if resp != nil {
+			_, _ = io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+		if hasBody {
+			body, bodyErr := req.GetBody()
+			if bodyErr != nil {
+				return nil, bodyErr
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// backoff returns a delay before the next attempt, Retry-After in seconds is respected.
+func (t *retryTransport) backoff(attempt int, resp *http.Response) time.Duration {
+	if resp != nil {
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds >= 0 {
+			if delay := time.Duration(seconds) * time.Second; delay < t.maxBackoff {
+				return delay
+			}
+
+			return t.maxBackoff
+		}
+	}
+
+	delay := t.minBackoff
+	for i := 1; i < attempt && delay < t.maxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > t.maxBackoff {
+		delay = t.maxBackoff
+	}
+
+	return delay
+}
+
+func shouldRetry(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete, http.MethodOptions:
+		return true
+	default:
+		return false
+	}
+}
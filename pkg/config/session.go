@@ -0,0 +1,259 @@
+package config
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/selectel/craas-go/pkg/svc"
+	clientv1 "github.com/selectel/craas-go/pkg/v1/client"
+	clientv2 "github.com/selectel/craas-go/pkg/v2/client"
+)
+
+// Overrides represents settings taking precedence over the profile,
+// for example command-line flags. Empty fields are ignored.
+type Overrides struct {
+	// Token replaces the token source of the profile.
+	Token string
+
+	// Endpoint replaces the endpoint and the region of the profile.
+	Endpoint string
+
+	// Timeout replaces the HTTP request timeout of the profile.
+	Timeout time.Duration
+}
+
+// Session represents resolved settings of the selected profile.
+type Session struct {
+	// Profile is a name of the selected profile, it's empty when the
+	// configuration file doesn't define it.
+	Profile string
+
+	// Endpoint is an API endpoint without a version.
+	Endpoint string
+
+	// HTTPClient is used by the clients, it applies the timeout and retry settings
+	// and sets a token of the source to every request.
+	HTTPClient *http.Client
+
+	source TokenSource
+
+	// sourceID identifies the token source configuration, see CacheKey.
+	sourceID string
+}
+
+// Select resolves the profile by its name. The name is taken from the
+// CRAAS_PROFILE environment variable or the default profile when it's empty.
+// The CRAAS_TOKEN and CRAAS_ENDPOINT environment variables override the profile
+// and the overrides take precedence over everything else.
+func (c *Config) Select(name string, overrides *Overrides) (*Session, error) {
+	o := Overrides{}
+	if overrides != nil {
+		o = *overrides
+	}
+
+	explicit := name != ""
+	if name == "" {
+		name = os.Getenv(EnvProfile)
+		explicit = name != ""
+	}
+	if name == "" {
+		name = c.DefaultProfile
+		explicit = name != ""
+	}
+	if name == "" {
+		name = DefaultProfile
+	}
+	p, ok := c.Profiles[name]
+	if !ok {
+		if explicit {
+			return nil, fmt.Errorf("%w: %q", ErrProfileNotFound, name)
+		}
+		// Without profiles the session is configured by environment variables only.
+		name = ""
+		p = &Profile{}
+	}
+
+	endpoint, err := c.endpoint(p, o)
+	if err != nil {
+		return nil, err
+	}
+
+	source, err := NewTokenSource(p.Token)
+	if err != nil {
+		return nil, err
+	}
+	sourceID, err := json.Marshal(p.Token)
+	if err != nil {
+		return nil, err
+	}
+	if token := os.Getenv(EnvToken); token != "" {
+		source = StaticToken(token)
+		sourceID = []byte(token)
+	}
+	if o.Token != "" {
+		source = StaticToken(o.Token)
+		sourceID = []byte(o.Token)
+	}
+	if source == nil {
+		return nil, fmt.Errorf("%w: set it in the profile or with %s", ErrNoToken, EnvToken)
+	}
+
+	timeout := p.Timeout
+	if o.Timeout > 0 {
+		timeout = o.Timeout
+	}
+
+	return &Session{
+		Profile:    name,
+		Endpoint:   endpoint,
+		HTTPClient: newHTTPClient(timeout, p.Retry, source),
+		source:     source,
+		sourceID:   string(sourceID),
+	}, nil
+}
+
+// endpoint resolves an API endpoint without a version.
+func (c *Config) endpoint(p *Profile, o Overrides) (string, error) {
+	endpoint := o.Endpoint
+	if endpoint == "" {
+		endpoint = os.Getenv(EnvEndpoint)
+	}
+	if endpoint == "" {
+		endpoint = p.Endpoint
+	}
+	if endpoint == "" && p.Region != "" {
+		var err error
+		if endpoint, err = c.regionEndpoint(p.Region); err != nil {
+			return "", err
+		}
+	}
+	if endpoint == "" {
+		endpoint = DefaultEndpoint
+	}
+
+	return BaseEndpoint(endpoint), nil
+}
+
+// BaseEndpoint strips a trailing slash and an API version from the endpoint.
+func BaseEndpoint(endpoint string) string {
+	endpoint = strings.TrimSuffix(endpoint, "/")
+	for _, version := range []string{"/v1", "/v2"} {
+		endpoint = strings.TrimSuffix(endpoint, version)
+	}
+
+	return endpoint
+}
+
+// Token returns the current token of the session source.
+func (s *Session) Token(ctx context.Context) (string, error) {
+	return s.source.Token(ctx)
+}
+
+// CacheKey returns a hash of the profile, the endpoint and the token source
+// configuration for keying local caches. Unlike Token it never runs commands
+// or requests tokens, and it doesn't change when a token is reissued.
+func (s *Session) CacheKey() string {
+	sum := sha256.Sum256([]byte(strings.Join([]string{s.Profile, s.Endpoint, s.sourceID}, "\x00")))
+
+	return hex.EncodeToString(sum[:])
+}
+
+// ClientV1 returns a client of the v1 API.
+// The token is taken from the source for every request,
+// so the client keeps working after the token is reissued.
+func (s *Session) ClientV1(ctx context.Context) (*clientv1.ServiceClient, error) {
+	token, err := s.Token(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return clientv1.NewCRaaSClientV1WithCustomHTTP(s.HTTPClient, token, s.Endpoint+"/v1")
+}
+
+// ClientV2 returns a client of the v2 API.
+// The token is taken from the source for every request.
+func (s *Session) ClientV2(ctx context.Context) (*clientv2.ServiceClient, error) {
+	token, err := s.Token(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return clientv2.NewCRaaSClientV2WithCustomHTTP(s.HTTPClient, token, s.Endpoint+"/v2")
+}
+
+// newHTTPClient returns an HTTP client with the timeout, retry and token settings.
+func newHTTPClient(timeout time.Duration, retry RetryConfig, source TokenSource) *http.Client {
+	httpClient := svc.NewHTTPClient()
+	if timeout > 0 {
+		httpClient.Timeout = timeout
+	}
+	httpClient.Transport = &tokenTransport{next: httpClient.Transport, source: source}
+	if retry.MaxAttempts > 1 {
+		httpClient.Transport = newRetryTransport(httpClient.Transport, retry)
+	}
+
+	return httpClient
+}
+
+// tokenTransport sets the X-Auth-Token header of every request from the source,
+// retried requests pass through it again and get a reissued token.
+type tokenTransport struct {
+	next   http.RoundTripper
+	source TokenSource
+}
+
+// tokenInvalidator is a token source caching tokens, see CachedToken.
+type tokenInvalidator interface {
+	Invalidate()
+}
+
+func (t *tokenTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.roundTrip(req)
+	invalidator, ok := t.source.(tokenInvalidator)
+	if err != nil || !ok || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+
+	// The cached token may have been revoked or reissued,
+	// the request is sent once more with a token requested from the source.
+	invalidator.Invalidate()
+	hasBody := req.Body != nil && req.Body != http.NoBody
+	if hasBody && req.GetBody == nil {
+		return resp, nil
+	}
+	retry := req.Clone(req.Context())
+	if hasBody {
+		body, err := req.GetBody()
+		if err != nil {
+			return resp, nil
+		}
+		retry.Body = body
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+
+	return t.roundTrip(retry)
+}
+
+func (t *tokenTransport) roundTrip(req *http.Request) (*http.Response, error) {
+	token, err := t.source.Token(req.Context())
+	if err != nil {
+		if req.Body != nil {
+			req.Body.Close()
+		}
+
+		return nil, err
+	}
+	req = req.Clone(req.Context())
+	req.Header.Set("X-Auth-Token", token)
+
+	return t.next.RoundTrip(req)
+}
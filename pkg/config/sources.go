@@ -0,0 +1,176 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TokenSource returns a token used to access the API.
+type TokenSource interface {
+	Token(ctx context.Context) (string, error)
+}
+
+// ErrTokenEmpty is returned when a token source provides an empty token.
+var ErrTokenEmpty = errors.New("token source returned an empty token")
+
+// DefaultTokenCacheTTL is a time a token read from a file or printed by
+// a command is reused before the source is asked again.
+const DefaultTokenCacheTTL = 5 * time.Minute
+
+// NewTokenSource returns a source of the token configuration,
+// nil is returned when no source is set.
+func NewTokenSource(cfg TokenConfig) (TokenSource, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	switch {
+	case cfg.Value != "":
+		return StaticToken(cfg.Value), nil
+	case cfg.Env != "":
+		return EnvVarToken(cfg.Env), nil
+	case cfg.File != "":
+		return NewCachedToken(FileToken(cfg.File), DefaultTokenCacheTTL), nil
+	case len(cfg.Exec) > 0:
+		return NewCachedToken(ExecToken(cfg.Exec), DefaultTokenCacheTTL), nil
+	case cfg.Keystone != nil:
+		return NewKeystoneToken(cfg.Keystone, nil), nil
+	default:
+		return nil, nil
+	}
+}
+
+// StaticToken is a literal token.
+type StaticToken string
+
+// Token returns the literal token.
+func (t StaticToken) Token(_ context.Context) (string, error) {
+	if t == "" {
+		return "", ErrTokenEmpty
+	}
+
+	return string(t), nil
+}
+
+// EnvVarToken reads the token from an environment variable.
+type EnvVarToken string
+
+// Token returns a value of the environment variable.
+func (t EnvVarToken) Token(_ context.Context) (string, error) {
+	token := strings.TrimSpace(os.Getenv(string(t)))
+	if token == "" {
+		return "", fmt.Errorf("%w: environment variable %s is empty", ErrTokenEmpty, string(t))
+	}
+
+	return token, nil
+}
+
+// FileToken reads the token from a file.
+type FileToken string
+
+// Token returns the content of the file without surrounding whitespace.
+func (t FileToken) Token(_ context.Context) (string, error) {
+	path, err := expandHome(string(t))
+	if err != nil {
+		return "", err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	token := strings.TrimSpace(string(data))
+	if token == "" {
+		return "", fmt.Errorf("%w: %s is empty", ErrTokenEmpty, path)
+	}
+
+	return token, nil
+}
+
+// ExecToken runs a command and reads the token from its standard output.
+type ExecToken []string
+
+// Token runs the command.
+func (t ExecToken) Token(ctx context.Context) (string, error) {
+	if len(t) == 0 {
+		return "", errors.New("token command is empty")
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, t[0], t[1:]...) //nolint:gosec // the command comes from the user's configuration.
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("token command %s failed: %w: %s", t[0], err, msg)
+		}
+
+		return "", fmt.Errorf("token command %s failed: %w", t[0], err)
+	}
+	token := strings.TrimSpace(stdout.String())
+	if token == "" {
+		return "", fmt.Errorf("%w: command %s printed nothing", ErrTokenEmpty, t[0])
+	}
+
+	return token, nil
+}
+
+// CachedToken reuses a token of the source until the TTL passes or the token
+// is invalidated, so a command or a file isn't run or read for every request.
+type CachedToken struct {
+	source TokenSource
+	ttl    time.Duration
+
+	mu        sync.Mutex
+	token     string
+	expiresAt time.Time
+}
+
+// NewCachedToken returns a source caching tokens of the source for the TTL.
+func NewCachedToken(source TokenSource, ttl time.Duration) *CachedToken {
+	return &CachedToken{source: source, ttl: ttl}
+}
+
+// Token returns the cached token or requests a new one from the source.
+func (c *CachedToken) Token(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.token != "" && time.Now().Before(c.expiresAt) {
+		return c.token, nil
+	}
+	token, err := c.source.Token(ctx)
+	if err != nil {
+		return "", err
+	}
+	c.token, c.expiresAt = token, time.Now().Add(c.ttl)
+
+	return token, nil
+}
+
+// Invalidate drops the cached token, the next token is requested from the source.
+func (c *CachedToken) Invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.token = ""
+}
+
+// expandHome replaces the leading "~/" of the path with the home directory.
+func expandHome(path string) (string, error) {
+	if !strings.HasPrefix(path, "~/") {
+		return path, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+
+	return home + path[1:], nil
+}
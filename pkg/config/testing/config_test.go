@@ -0,0 +1,389 @@
+package testing
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/selectel/craas-go/pkg/config"
+	"github.com/selectel/craas-go/pkg/testutils"
+	"github.com/selectel/craas-go/pkg/v1/registry"
+)
+
+func unsetEnv(t *testing.T) {
+	t.Helper()
+
+	for _, name := range []string{config.EnvProfile, config.EnvToken, config.EnvEndpoint} {
+		t.Setenv(name, "")
+	}
+}
+
+func TestParseValidation(t *testing.T) {
+	if _, err := config.Parse([]byte(testConfigMultipleSources)); !errors.Is(err, config.ErrMultipleTokenSources) {
+		t.Fatalf("expected %v, but got %v", config.ErrMultipleTokenSources, err)
+	}
+	if _, err := config.Parse([]byte(testConfigEndpointAndRegion)); !errors.Is(err, config.ErrEndpointAndRegion) {
+		t.Fatalf("expected %v, but got %v", config.ErrEndpointAndRegion, err)
+	}
+}
+
+func TestSelectDefaultProfile(t *testing.T) {
+	unsetEnv(t)
+	cfg, err := config.Parse([]byte(testConfig))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	session, err := cfg.Select("", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if session.Profile != "prod" || session.Endpoint != config.DefaultEndpoint {
+		t.Fatalf("unexpected session: %s %s", session.Profile, session.Endpoint)
+	}
+	if session.HTTPClient.Timeout != 30*time.Second {
+		t.Fatalf("expected 30s timeout, but got %s", session.HTTPClient.Timeout)
+	}
+	token, err := session.Token(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token != "prod-token" {
+		t.Fatalf("expected prod-token, but got %s", token)
+	}
+}
+
+func TestSelectOverrides(t *testing.T) {
+	unsetEnv(t)
+	cfg, err := config.Parse([]byte(testConfig))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv(config.EnvProfile, "staging")
+	session, err := cfg.Select("", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if session.Endpoint != "https://test-1.example.com/api" {
+		t.Fatalf("expected the region endpoint, but got %s", session.Endpoint)
+	}
+	token, err := session.Token(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token != "exec-token" {
+		t.Fatalf("expected exec-token, but got %s", token)
+	}
+
+	t.Setenv(config.EnvToken, "env-token")
+	t.Setenv(config.EnvEndpoint, "https://env.example.com/api")
+	session, err = cfg.Select("staging", &config.Overrides{Token: "flag-token"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, err = session.Token(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token != "flag-token" || session.Endpoint != "https://env.example.com/api" {
+		t.Fatalf("unexpected overridden session: %s %s", token, session.Endpoint)
+	}
+
+	if _, err := cfg.Select("unknown", nil); !errors.Is(err, config.ErrProfileNotFound) {
+		t.Fatalf("expected %v, but got %v", config.ErrProfileNotFound, err)
+	}
+}
+
+func TestSelectWithoutToken(t *testing.T) {
+	unsetEnv(t)
+	cfg, err := config.Parse([]byte(testConfig))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cfg.Select("empty", nil); !errors.Is(err, config.ErrNoToken) {
+		t.Fatalf("expected %v, but got %v", config.ErrNoToken, err)
+	}
+}
+
+func TestLoadDefaultMissingFile(t *testing.T) {
+	unsetEnv(t)
+	t.Setenv(config.EnvConfig, filepath.Join(t.TempDir(), "config.yaml"))
+	t.Setenv(config.EnvToken, "env-token")
+
+	cfg, err := config.LoadDefault()
+	if err != nil {
+		t.Fatal(err)
+	}
+	session, err := cfg.Select("", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if session.Profile != "" || session.Endpoint != config.DefaultEndpoint {
+		t.Fatalf("unexpected session: %q %s", session.Profile, session.Endpoint)
+	}
+}
+
+func TestFileToken(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(path, []byte("file-token\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	token, err := config.FileToken(path).Token(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token != "file-token" {
+		t.Fatalf("expected file-token, but got %s", token)
+	}
+}
+
+func TestKeystoneToken(t *testing.T) {
+	calls := 0
+	testEnv := testutils.SetupTestEnv()
+	defer testEnv.TearDownTestEnv()
+
+	testEnv.Mux.HandleFunc("/identity/v3/auth/tokens", func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		var actual, expected interface{}
+		if err := json.NewDecoder(r.Body).Decode(&actual); err != nil {
+			t.Errorf("unable to decode the request body: %v", err)
+		}
+		if err := json.Unmarshal([]byte(testKeystoneRequest), &expected); err != nil {
+			t.Errorf("unable to decode the expected request: %v", err)
+		}
+		if !reflect.DeepEqual(expected, actual) {
+			t.Errorf("expected %#v request, but got %#v", expected, actual)
+		}
+		w.Header().Set("X-Subject-Token", "keystone-token")
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, testKeystoneResponse)
+	})
+
+	t.Setenv("CRAAS_TEST_PASSWORD", "secret")
+	source := config.NewKeystoneToken(&config.KeystoneConfig{
+		AuthURL:     testEnv.Server.URL + "/identity/v3",
+		Username:    "deployer",
+		PasswordEnv: "CRAAS_TEST_PASSWORD",
+		Domain:      "123456",
+		ProjectID:   "1a2b3c4d5e6f",
+	}, nil)
+	for i := 0; i < 2; i++ {
+		token, err := source.Token(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if token != "keystone-token" {
+			t.Fatalf("expected keystone-token, but got %s", token)
+		}
+	}
+	if calls != 1 {
+		t.Fatalf("expected the token to be issued once, but got %d calls", calls)
+	}
+}
+
+func TestSessionRetries(t *testing.T) {
+	unsetEnv(t)
+	calls := 0
+	testEnv := testutils.SetupTestEnv()
+	defer testEnv.TearDownTestEnv()
+
+	testEnv.Mux.HandleFunc("/api/v1/registries", func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, testRegistriesResponse)
+	})
+
+	cfg, err := config.Parse([]byte(testConfig))
+	if err != nil {
+		t.Fatal(err)
+	}
+	session, err := cfg.Select("staging", &config.Overrides{
+		Token:    testutils.TokenID,
+		Endpoint: testEnv.Server.URL + "/api",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := session.ClientV1(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	registries, _, err := registry.List(context.Background(), client)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if calls != 3 || len(registries) != 1 {
+		t.Fatalf("expected 3 calls and 1 registry, but got %d calls and %d registries", calls, len(registries))
+	}
+}
+
+func TestSessionTokenPerRequest(t *testing.T) {
+	unsetEnv(t)
+	var tokens []string
+	testEnv := testutils.SetupTestEnv()
+	defer testEnv.TearDownTestEnv()
+
+	testEnv.Mux.HandleFunc("/api/v1/registries", func(w http.ResponseWriter, r *http.Request) {
+		tokens = append(tokens, r.Header.Get("X-Auth-Token"))
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, testRegistriesResponse)
+	})
+
+	cfg, err := config.Parse([]byte(testConfigEnvToken))
+	if err != nil {
+		t.Fatal(err)
+	}
+	session, err := cfg.Select("rotated", &config.Overrides{Endpoint: testEnv.Server.URL + "/api"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("CRAAS_TEST_TOKEN", "first-token")
+	client, err := session.ClientV1(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, token := range []string{"first-token", "second-token"} {
+		t.Setenv("CRAAS_TEST_TOKEN", token)
+		if _, _, err := registry.List(context.Background(), client); err != nil {
+			t.Fatal(err)
+		}
+	}
+	expected := []string{"first-token", "second-token"}
+	if !reflect.DeepEqual(expected, tokens) {
+		t.Fatalf("expected %#v, but got %#v", expected, tokens)
+	}
+}
+
+func TestSessionFileTokenCache(t *testing.T) {
+	unsetEnv(t)
+	path := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(path, []byte("first-token\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	var tokens []string
+	testEnv := testutils.SetupTestEnv()
+	defer testEnv.TearDownTestEnv()
+
+	testEnv.Mux.HandleFunc("/api/v1/registries", func(w http.ResponseWriter, r *http.Request) {
+		token := r.Header.Get("X-Auth-Token")
+		tokens = append(tokens, token)
+		current, _ := os.ReadFile(path)
+		if token != strings.TrimSpace(string(current)) {
+			w.WriteHeader(http.StatusUnauthorized)
+
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, testRegistriesResponse)
+	})
+
+	cfg, err := config.Parse([]byte(fmt.Sprintf(testConfigFileToken, path)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	session, err := cfg.Select("cached", &config.Overrides{Endpoint: testEnv.Server.URL + "/api"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := session.ClientV1(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := registry.List(context.Background(), client); err != nil {
+		t.Fatal(err)
+	}
+	// The cached token is used until the API rejects it.
+	if err := os.WriteFile(path, []byte("second-token\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := registry.List(context.Background(), client); err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"first-token", "first-token", "second-token"}
+	if !reflect.DeepEqual(expected, tokens) {
+		t.Fatalf("expected %#v, but got %#v", expected, tokens)
+	}
+}
+
+func TestSessionCacheKey(t *testing.T) {
+	unsetEnv(t)
+	cfg, err := config.Parse([]byte(testConfigExecToken))
+	if err != nil {
+		t.Fatal(err)
+	}
+	selectKey := func(overrides *config.Overrides) string {
+		t.Helper()
+
+		session, err := cfg.Select("exec", overrides)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return session.CacheKey()
+	}
+
+	// The key is built without running the token command.
+	key := selectKey(&config.Overrides{})
+	if key != selectKey(&config.Overrides{}) {
+		t.Fatal("expected the key of the same session to be stable")
+	}
+	if key == selectKey(&config.Overrides{Endpoint: "https://example.com/api"}) {
+		t.Fatal("expected the key to depend on the endpoint")
+	}
+	if key == selectKey(&config.Overrides{Token: testutils.TokenID}) {
+		t.Fatal("expected the key to depend on the token source")
+	}
+}
+
+func TestSessionRetriesWithoutReplayableBody(t *testing.T) {
+	unsetEnv(t)
+	calls := 0
+	testEnv := testutils.SetupTestEnv()
+	defer testEnv.TearDownTestEnv()
+
+	testEnv.Mux.HandleFunc("/api/v1/registries", func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+
+	cfg, err := config.Parse([]byte(testConfig))
+	if err != nil {
+		t.Fatal(err)
+	}
+	session, err := cfg.Select("staging", &config.Overrides{
+		Token:    testutils.TokenID,
+		Endpoint: testEnv.Server.URL + "/api",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	body := struct{ io.Reader }{strings.NewReader("{}")}
+	req, err := http.NewRequest(http.MethodPut, testEnv.Server.URL+"/api/v1/registries", body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := session.HTTPClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if calls != 1 || resp.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("expected 1 call with status 503, but got %d calls with status %d", calls, resp.StatusCode)
+	}
+}
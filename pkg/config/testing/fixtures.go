@@ -0,0 +1,100 @@
+package testing
+
+const testConfig = `
+defaultProfile: prod
+regions:
+  test-1: https://test-1.example.com/api/v1/
+profiles:
+  prod:
+    region: ru-1
+    timeout: 30s
+    token:
+      value: prod-token
+  staging:
+    region: test-1
+    retry:
+      maxAttempts: 3
+      minBackoff: 1ms
+      maxBackoff: 5ms
+    token:
+      exec: ["sh", "-c", "echo exec-token"]
+  empty: {}
+`
+
+const testConfigMultipleSources = `
+profiles:
+  broken:
+    token:
+      value: token
+      env: CRAAS_TEST_TOKEN
+`
+
+const testConfigEnvToken = `
+profiles:
+  rotated:
+    token:
+      env: CRAAS_TEST_TOKEN
+`
+
+const testConfigFileToken = `
+profiles:
+  cached:
+    token:
+      file: %s
+`
+
+const testConfigExecToken = `
+profiles:
+  exec:
+    token:
+      exec: ["craas-test-missing-command"]
+`
+
+const testConfigEndpointAndRegion = `
+profiles:
+  broken:
+    endpoint: https://cr.selcloud.ru/api
+    region: ru-1
+`
+
+const testKeystoneResponse = `
+{
+    "token": {
+        "expires_at": "2099-01-01T00:00:00.000000Z"
+    }
+}
+`
+
+const testKeystoneRequest = `
+{
+    "auth": {
+        "identity": {
+            "methods": ["password"],
+            "password": {
+                "user": {
+                    "name": "deployer",
+                    "password": "secret",
+                    "domain": {"name": "123456"}
+                }
+            }
+        },
+        "scope": {
+            "project": {"id": "1a2b3c4d5e6f"}
+        }
+    }
+}
+`
+
+const testRegistriesResponse = `
+[
+    {
+        "createdAt": "2022-01-28T08:50:34.123Z",
+        "id": "888af692-c646-4b76-a234-81ca9b5bcafe",
+        "name": "test-registry",
+        "size": 1024,
+        "sizeLimit": 2048,
+        "status": "ACTIVE",
+        "used": 50
+    }
+]
+`
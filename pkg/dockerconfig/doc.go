@@ -0,0 +1,34 @@
+/*
+Package `dockerconfig` provides a set of functions for rendering Docker config.json
+auths entries and Kubernetes pull secrets from CRaaS tokens.
+
+Both token.Token and tokenv2.TokenV2 can be used as credentials.
+
+Example of merging a token into the Docker config file:
+
+	cfg, err := dockerconfig.New(dockerconfig.DefaultRegistryHost, craasToken)
+	if err != nil {
+	    log.Fatal(err)
+	}
+	path, err := dockerconfig.DefaultConfigPath()
+	if err != nil {
+	    log.Fatal(err)
+	}
+	err = dockerconfig.MergeFile(path, cfg)
+	if err != nil {
+	    log.Fatal(err)
+	}
+
+Example of rendering a Kubernetes pull secret manifest:
+
+	secret, err := dockerconfig.NewSecret("craas-pull", "default", cfg)
+	if err != nil {
+	    log.Fatal(err)
+	}
+	manifest, err := secret.YAML()
+	if err != nil {
+	    log.Fatal(err)
+	}
+	fmt.Print(string(manifest))
+*/
+package dockerconfig
@@ -0,0 +1,228 @@
+package dockerconfig
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+
+	"github.com/selectel/craas-go/pkg/atomicfile"
+)
+
+const (
+	// DefaultRegistryHost is a host of the Selectel Container Registry.
+	DefaultRegistryHost = "cr.selcloud.ru"
+
+	// SecretType is a type of Kubernetes Secrets that hold Docker config.
+	SecretType = "kubernetes.io/dockerconfigjson"
+
+	// SecretDataKey is a key of the Docker config in the Kubernetes Secret data.
+	SecretDataKey = ".dockerconfigjson"
+
+	// configFileMode is a permission mode of created config files.
+	configFileMode fs.FileMode = 0o600
+)
+
+var (
+	ErrHostEmpty        = errors.New("registry host is empty")
+	ErrCredentialsEmpty = errors.New("registry credentials are empty")
+	ErrSecretNameEmpty  = errors.New("secret name is empty")
+	ErrConfigNil        = errors.New("docker config is nil")
+)
+
+// New returns a Docker config with a single auths entry for the host.
+func New(host string, creds Credentials) (*Config, error) {
+	cfg := &Config{Auths: make(map[string]AuthConfig)}
+	if err := cfg.Add(host, creds); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// Add sets an auths entry for the host replacing the existing one.
+func (cfg *Config) Add(host string, creds Credentials) error {
+	if host == "" {
+		return ErrHostEmpty
+	}
+	if creds == nil {
+		return ErrCredentialsEmpty
+	}
+	username, password := creds.RegistryCredentials()
+	if username == "" || password == "" {
+		return ErrCredentialsEmpty
+	}
+	if cfg.Auths == nil {
+		cfg.Auths = make(map[string]AuthConfig)
+	}
+	cfg.Auths[host] = NewAuthConfig(username, password)
+
+	return nil
+}
+
+// NewAuthConfig returns an auths entry with the encoded auth field populated.
+func NewAuthConfig(username, password string) AuthConfig {
+	return AuthConfig{
+		Username: username,
+		Password: password,
+		Auth:     base64.StdEncoding.EncodeToString([]byte(username + ":" + password)),
+	}
+}
+
+// JSON renders the Docker config as an indented config.json document.
+func (cfg *Config) JSON() ([]byte, error) {
+	return json.MarshalIndent(cfg, "", "\t")
+}
+
+// DefaultConfigPath returns a path of the current user Docker config file.
+// The DOCKER_CONFIG environment variable is respected.
+func DefaultConfigPath() (string, error) {
+	if dir := os.Getenv("DOCKER_CONFIG"); dir != "" {
+		return filepath.Join(dir, "config.json"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(home, ".docker", "config.json"), nil
+}
+
+// WriteFile writes the Docker config to the path replacing the existing file.
+func WriteFile(path string, cfg *Config) error {
+	if cfg == nil {
+		return ErrConfigNil
+	}
+	data, err := cfg.JSON()
+	if err != nil {
+		return err
+	}
+
+	return writeFileAtomic(path, data)
+}
+
+// MergeFile sets auths entries of the Docker config in the existing file.
+// Other auths entries and top-level keys such as credHelpers are preserved.
+// The file is created if it doesn't exist.
+func MergeFile(path string, cfg *Config) error {
+	if cfg == nil {
+		return ErrConfigNil
+	}
+
+	document := make(map[string]json.RawMessage)
+	existing, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+	case err != nil:
+		return err
+	case len(bytes.TrimSpace(existing)) != 0:
+		if err := json.Unmarshal(existing, &document); err != nil {
+			return fmt.Errorf("unable to parse %s: %w", path, err)
+		}
+	}
+
+	auths := make(map[string]json.RawMessage)
+	if raw, ok := document["auths"]; ok {
+		if err := json.Unmarshal(raw, &auths); err != nil {
+			return fmt.Errorf("unable to parse auths of %s: %w", path, err)
+		}
+	}
+	for host, auth := range cfg.Auths {
+		raw, err := json.Marshal(auth)
+		if err != nil {
+			return err
+		}
+		auths[host] = raw
+	}
+	rawAuths, err := json.Marshal(auths)
+	if err != nil {
+		return err
+	}
+	document["auths"] = rawAuths
+
+	data, err := json.MarshalIndent(document, "", "\t")
+	if err != nil {
+		return err
+	}
+
+	return writeFileAtomic(path, data)
+}
+
+// writeFileAtomic atomically replaces the file with the data, creating its
+// directory if needed. The permission mode of the existing file is preserved.
+func writeFileAtomic(path string, data []byte) error {
+	mode := configFileMode
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+
+	return atomicfile.WriteFile(path, data, mode)
+}
+
+// NewSecret returns a Kubernetes pull secret manifest holding the Docker config.
+// Namespace is optional.
+func NewSecret(name, namespace string, cfg *Config) (*Secret, error) {
+	if name == "" {
+		return nil, ErrSecretNameEmpty
+	}
+	if cfg == nil {
+		return nil, ErrConfigNil
+	}
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Secret{
+		APIVersion: "v1",
+		Kind:       "Secret",
+		Metadata: SecretMetadata{
+			Name:      name,
+			Namespace: namespace,
+		},
+		Type: SecretType,
+		Data: map[string]string{
+			SecretDataKey: base64.StdEncoding.EncodeToString(data),
+		},
+	}, nil
+}
+
+// JSON renders the Secret as an indented JSON manifest.
+func (s *Secret) JSON() ([]byte, error) {
+	return json.MarshalIndent(s, "", "  ")
+}
+
+// YAML renders the Secret as a YAML manifest.
+// Scalars are written as double-quoted strings, so any value is safe to embed.
+func (s *Secret) YAML() ([]byte, error) {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "apiVersion: %s\n", strconv.Quote(s.APIVersion))
+	fmt.Fprintf(&b, "kind: %s\n", strconv.Quote(s.Kind))
+	b.WriteString("metadata:\n")
+	fmt.Fprintf(&b, "  name: %s\n", strconv.Quote(s.Metadata.Name))
+	if s.Metadata.Namespace != "" {
+		fmt.Fprintf(&b, "  namespace: %s\n", strconv.Quote(s.Metadata.Namespace))
+	}
+	fmt.Fprintf(&b, "type: %s\n", strconv.Quote(s.Type))
+	b.WriteString("data:\n")
+
+	keys := make([]string, 0, len(s.Data))
+	for key := range s.Data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fmt.Fprintf(&b, "  %s: %s\n", strconv.Quote(key), strconv.Quote(s.Data[key]))
+	}
+
+	return b.Bytes(), nil
+}
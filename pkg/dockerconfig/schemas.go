@@ -0,0 +1,42 @@
+package dockerconfig
+
+// Credentials represents registry credentials source.
+// It's implemented by token.Token and tokenv2.TokenV2.
+type Credentials interface {
+	// RegistryCredentials returns username and password for the registry login.
+	RegistryCredentials() (username, password string)
+}
+
+// Config represents a Docker config.json file auths section.
+type Config struct {
+	// Auths maps registry hosts to their credentials.
+	Auths map[string]AuthConfig `json:"auths"`
+}
+
+// AuthConfig represents credentials of a single registry host.
+type AuthConfig struct {
+	// Username is a registry username.
+	Username string `json:"username,omitempty"`
+
+	// Password is a registry password.
+	Password string `json:"password,omitempty"`
+
+	// Auth is a base64 encoded "username:password" string.
+	Auth string `json:"auth,omitempty"`
+}
+
+// Secret represents a Kubernetes Secret manifest of the
+// kubernetes.io/dockerconfigjson type.
+type Secret struct {
+	APIVersion string            `json:"apiVersion"`
+	Kind       string            `json:"kind"`
+	Metadata   SecretMetadata    `json:"metadata"`
+	Type       string            `json:"type"`
+	Data       map[string]string `json:"data"`
+}
+
+// SecretMetadata represents metadata of a Kubernetes Secret manifest.
+type SecretMetadata struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty"`
+}
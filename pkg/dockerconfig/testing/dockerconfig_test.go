@@ -0,0 +1,157 @@
+package testing
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/selectel/craas-go/pkg/dockerconfig"
+	"github.com/selectel/craas-go/pkg/v1/token"
+)
+
+func TestNew(t *testing.T) {
+	for name, creds := range map[string]dockerconfig.Credentials{
+		"v1 token": testToken,
+		"v2 token": testTokenV2,
+	} {
+		t.Run(name, func(t *testing.T) {
+			actual, err := dockerconfig.New(dockerconfig.DefaultRegistryHost, creds)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(expectedConfig, actual) {
+				t.Fatalf("expected %#v, but got %#v", expectedConfig, actual)
+			}
+		})
+	}
+}
+
+func TestNewErrors(t *testing.T) {
+	if _, err := dockerconfig.New("", testToken); !errors.Is(err, dockerconfig.ErrHostEmpty) {
+		t.Fatalf("expected %v error, but got %v", dockerconfig.ErrHostEmpty, err)
+	}
+	_, err := dockerconfig.New(dockerconfig.DefaultRegistryHost, &token.Token{})
+	if !errors.Is(err, dockerconfig.ErrCredentialsEmpty) {
+		t.Fatalf("expected %v error, but got %v", dockerconfig.ErrCredentialsEmpty, err)
+	}
+}
+
+func TestWriteFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "docker", "config.json")
+	if err := dockerconfig.WriteFile(path, expectedConfig); err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0o600 {
+		t.Fatalf("expected 0600 file mode, but got %o", info.Mode().Perm())
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var actual dockerconfig.Config
+	if err := json.Unmarshal(data, &actual); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(*expectedConfig, actual) {
+		t.Fatalf("expected %#v, but got %#v", *expectedConfig, actual)
+	}
+}
+
+func TestMergeFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(testExistingConfigRaw), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := dockerconfig.MergeFile(path, expectedConfig); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var actual, expected interface{}
+	if err := json.Unmarshal(data, &actual); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal([]byte(expectedMergedConfigRaw), &expected); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("expected %#v, but got %#v", expected, actual)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0o644 {
+		t.Fatalf("expected file mode to be preserved, but got %o", info.Mode().Perm())
+	}
+}
+
+func TestMergeFileInvalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte("{"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := dockerconfig.MergeFile(path, expectedConfig); err == nil {
+		t.Fatal("expected an error for the invalid config file")
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "{" {
+		t.Fatalf("expected the invalid file to stay untouched, but got %q", data)
+	}
+}
+
+func TestNewSecret(t *testing.T) {
+	secret, err := dockerconfig.NewSecret("craas-pull", "default", expectedConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	actualYAML, err := secret.YAML()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(actualYAML) != expectedSecretYAML {
+		t.Fatalf("expected %s, but got %s", expectedSecretYAML, actualYAML)
+	}
+
+	actualJSON, err := secret.JSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded dockerconfig.Secret
+	if err := json.Unmarshal(actualJSON, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Type != dockerconfig.SecretType {
+		t.Fatalf("expected %s secret type, but got %s", dockerconfig.SecretType, decoded.Type)
+	}
+	rawConfig, err := base64.StdEncoding.DecodeString(decoded.Data[dockerconfig.SecretDataKey])
+	if err != nil {
+		t.Fatal(err)
+	}
+	var actualConfig dockerconfig.Config
+	if err := json.Unmarshal(rawConfig, &actualConfig); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(*expectedConfig, actualConfig) {
+		t.Fatalf("expected %#v, but got %#v", *expectedConfig, actualConfig)
+	}
+}
@@ -0,0 +1,74 @@
+package testing
+
+import (
+	"github.com/selectel/craas-go/pkg/dockerconfig"
+	"github.com/selectel/craas-go/pkg/v1/token"
+	tokenV2 "github.com/selectel/craas-go/pkg/v2/token"
+)
+
+const testTokenID = `CRgAAAAAWiMnwN63eyASwQk8a3DBPRPirT9fWQTx`
+
+var testToken = &token.Token{
+	Token:     testTokenID,
+	ExpiresAt: 1666644533,
+	ExpiresIn: 43200,
+}
+
+var testTokenV2 = &tokenV2.TokenV2{
+	ID:    "c29e3f63-0711-4772-a415-ad79973bdaef",
+	Name:  "my-token",
+	Token: testTokenID,
+}
+
+var expectedConfig = &dockerconfig.Config{
+	Auths: map[string]dockerconfig.AuthConfig{
+		dockerconfig.DefaultRegistryHost: {
+			Username: "token",
+			Password: testTokenID,
+			Auth:     "dG9rZW46Q1JnQUFBQUFXaU1ud042M2V5QVN3UWs4YTNEQlBSUGlyVDlmV1FUeA==",
+		},
+	},
+}
+
+// testExistingConfigRaw represents a Docker config file with foreign entries.
+const testExistingConfigRaw = `{
+	"auths": {
+		"ghcr.io": {
+			"auth": "dXNlcjpwYXNz"
+		},
+		"cr.selcloud.ru": {
+			"auth": "b2xkOm9sZA=="
+		}
+	},
+	"credHelpers": {
+		"gcr.io": "gcloud"
+	}
+}`
+
+// expectedMergedConfigRaw represents the expected merge result
+// of the testExistingConfigRaw and the expectedConfig.
+const expectedMergedConfigRaw = `{
+	"auths": {
+		"ghcr.io": {
+			"auth": "dXNlcjpwYXNz"
+		},
+		"cr.selcloud.ru": {
+			"username": "token",
+			"password": "CRgAAAAAWiMnwN63eyASwQk8a3DBPRPirT9fWQTx",
+			"auth": "dG9rZW46Q1JnQUFBQUFXaU1ud042M2V5QVN3UWs4YTNEQlBSUGlyVDlmV1FUeA=="
+		}
+	},
+	"credHelpers": {
+		"gcr.io": "gcloud"
+	}
+}`
+
+const expectedSecretYAML = `apiVersion: "v1"
+kind: "Secret"
+metadata:
+  name: "craas-pull"
+  namespace: "default"
+type: "kubernetes.io/dockerconfigjson"
+data:
+  ".dockerconfigjson": "eyJhdXRocyI6eyJjci5zZWxjbG91ZC5ydSI6eyJ1c2VybmFtZSI6InRva2VuIiwicGFzc3dvcmQiOiJDUmdBQUFBQVdpTW53TjYzZXlBU3dRazhhM0RCUFJQaXJUOWZXUVR4IiwiYXV0aCI6ImRHOXJaVzQ2UTFKblFVRkJRVUZYYVUxdWQwNDJNMlY1UVZOM1VXczRZVE5FUWxCU1VHbHlWRGxtVjFGVWVBPT0ifX19"
+`
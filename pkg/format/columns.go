@@ -0,0 +1,203 @@
+package format
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// valueColumn is a name of the only column of scalar items.
+const valueColumn = "value"
+
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	rawMessageType = reflect.TypeOf(json.RawMessage{})
+)
+
+// column represents a struct field reachable from an item.
+type column struct {
+	name  string
+	path  [][]int
+	typ   reflect.Type
+	shown bool
+}
+
+// header returns a table header of the column, words of camel-cased
+// names are separated, e.g. "scope.modeRW" becomes "SCOPE MODE RW".
+func (c column) header() string {
+	runes := []rune(c.name)
+	var b strings.Builder
+	for i, r := range runes {
+		if r == '.' || r == '_' {
+			b.WriteRune(' ')
+
+			continue
+		}
+		if i > 0 && unicode.IsUpper(r) {
+			prev := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+				b.WriteRune(' ')
+			}
+		}
+		b.WriteRune(unicode.ToUpper(r))
+	}
+
+	return b.String()
+}
+
+// value returns a value of the column, an invalid value is returned when
+// a pointer on the path is nil.
+func (c column) value(item reflect.Value) reflect.Value {
+	v := indirect(item)
+	for _, index := range c.path {
+		if !v.IsValid() {
+			return v
+		}
+		v = indirect(v.FieldByIndex(index))
+	}
+
+	return v
+}
+
+// columns returns all columns of the type.
+func columns(t reflect.Type) []column {
+	if t == nil {
+		return nil
+	}
+	if t.Kind() != reflect.Struct || t == timeType {
+		return []column{{name: valueColumn, typ: t, shown: true}}
+	}
+
+	return structColumns(t, "", nil)
+}
+
+func structColumns(t reflect.Type, prefix string, path [][]int) []column {
+	result := make([]column, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name, ok := fieldName(f)
+		if !ok {
+			continue
+		}
+		fieldPath := make([][]int, len(path), len(path)+1)
+		copy(fieldPath, path)
+		fieldPath = append(fieldPath, f.Index)
+
+		ft := indirectType(f.Type)
+		if ft.Kind() == reflect.Struct && ft != timeType {
+			if f.Anonymous && f.Tag.Get("json") == "" {
+				result = append(result, structColumns(ft, prefix, fieldPath)...)
+			} else {
+				result = append(result, structColumns(ft, prefix+name+".", fieldPath)...)
+			}
+
+			continue
+		}
+		result = append(result, column{
+			name:  prefix + name,
+			path:  fieldPath,
+			typ:   ft,
+			shown: isShownByDefault(ft),
+		})
+	}
+
+	return result
+}
+
+// fieldName returns a JSON name of the field.
+func fieldName(f reflect.StructField) (string, bool) {
+	tag := f.Tag.Get("json")
+	if tag == "-" {
+		return "", false
+	}
+	if name := strings.Split(tag, ",")[0]; name != "" {
+		return name, true
+	}
+	if f.Name == "" {
+		return "", false
+	}
+
+	return strings.ToLower(f.Name[:1]) + f.Name[1:], true
+}
+
+// isShownByDefault hides raw documents and maps from default columns.
+func isShownByDefault(t reflect.Type) bool {
+	if t == rawMessageType {
+		return false
+	}
+	switch t.Kind() {
+	case reflect.Map, reflect.Func, reflect.Chan, reflect.Interface:
+		return false
+	case reflect.Slice:
+		return t.Elem().Kind() != reflect.Uint8
+	default:
+		return true
+	}
+}
+
+// selectColumns returns columns by names or default columns.
+func selectColumns(t reflect.Type, names []string) ([]column, error) {
+	all := columns(t)
+	if len(names) == 0 {
+		shown := make([]column, 0, len(all))
+		for _, c := range all {
+			if c.shown {
+				shown = append(shown, c)
+			}
+		}
+
+		return shown, nil
+	}
+
+	selected := make([]column, 0, len(names))
+	for _, name := range names {
+		c, err := lookupColumn(all, name)
+		if err != nil {
+			return nil, err
+		}
+		selected = append(selected, c)
+	}
+
+	return selected, nil
+}
+
+// findColumn returns a column of the type by its name.
+func findColumn(t reflect.Type, name string) (column, error) {
+	return lookupColumn(columns(t), name)
+}
+
+func lookupColumn(all []column, name string) (column, error) {
+	for _, c := range all {
+		if strings.EqualFold(c.name, strings.TrimSpace(name)) {
+			return c, nil
+		}
+	}
+
+	return column{}, fmt.Errorf("%w: %q", ErrUnknownColumn, name)
+}
+
+func indirect(v reflect.Value) reflect.Value {
+	for v.IsValid() && (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+		v = v.Elem()
+	}
+
+	return v
+}
+
+func indirectType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	return t
+}
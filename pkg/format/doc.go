@@ -0,0 +1,35 @@
+/*
+Package `format` provides a set of functions for rendering SDK result types
+such as registry.Registry, repository.Image or tokenv2.TokenV2 as aligned
+tables, JSON, YAML, CSV or user-supplied text/template output.
+
+Columns are discovered from exported struct fields and named after their JSON
+keys, nested structs are flattened with dots, for example "scope.modeRW".
+Tables show sizes and times in a human-readable form, while CSV keeps raw values.
+
+Example of rendering registries as a table sorted by size:
+
+	registries, _, err := registry.List(ctx, craasClient)
+	if err != nil {
+	    log.Fatal(err)
+	}
+	err = format.Render(os.Stdout, registries, &format.Opts{
+	    Format:  format.FormatTable,
+	    Columns: []string{"name", "status", "size", "createdAt"},
+	    SortBy:  "-size",
+	})
+	if err != nil {
+	    log.Fatal(err)
+	}
+
+Example of rendering tokens with a template:
+
+	err = format.Render(os.Stdout, tokens, &format.Opts{
+	    Format:   format.FormatTemplate,
+	    Template: `{{.Name}} expires {{relativeTime .Expiration.ExpiresAt}}`,
+	})
+	if err != nil {
+	    log.Fatal(err)
+	}
+*/
+package format
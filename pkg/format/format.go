@@ -0,0 +1,381 @@
+package format
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Format represents an output format.
+type Format string
+
+const (
+	FormatTable    Format = "table"
+	FormatJSON     Format = "json"
+	FormatYAML     Format = "yaml"
+	FormatCSV      Format = "csv"
+	FormatTemplate Format = "template"
+)
+
+var (
+	ErrUnsupportedFormat = errors.New("unsupported output format")
+	ErrUnknownColumn     = errors.New("unknown column")
+	ErrTemplateEmpty     = errors.New("template is empty")
+	ErrInvalidSize       = errors.New("invalid size")
+)
+
+// Opts represents options of the rendering.
+type Opts struct {
+	// Format is an output format, FormatTable is used by default.
+	Format Format
+
+	// Template is a text/template executed for every item with FormatTemplate.
+	Template string
+
+	// Columns selects and orders columns by their names, all scalar columns
+	// are rendered by default.
+	Columns []string
+
+	// SortBy is a column items are sorted by, a "-" prefix sorts in descending order.
+	SortBy string
+
+	// NoHeader disables the header of table and CSV outputs.
+	NoHeader bool
+
+	// Now is a time relative times are calculated against, time.Now() is used by default.
+	Now time.Time
+}
+
+// Formats returns all supported formats.
+func Formats() []Format {
+	return []Format{FormatTable, FormatJSON, FormatYAML, FormatCSV, FormatTemplate}
+}
+
+// ParseFormat returns a Format by its name.
+func ParseFormat(name string) (Format, error) {
+	for _, f := range Formats() {
+		if string(f) == name {
+			return f, nil
+		}
+	}
+
+	return "", fmt.Errorf("%w: %q", ErrUnsupportedFormat, name)
+}
+
+// Render writes the value in the format of the options.
+// The value can be a struct, a slice of structs or pointers to them, or a scalar.
+func Render(w io.Writer, value interface{}, opts *Opts) error {
+	o := Opts{}
+	if opts != nil {
+		o = *opts
+	}
+	if o.Format == "" {
+		o.Format = FormatTable
+	}
+	if o.Now.IsZero() {
+		o.Now = time.Now()
+	}
+
+	items, single := collectItems(value)
+	columns, err := selectColumns(itemType(value), o.Columns)
+	if err != nil {
+		return err
+	}
+	if o.SortBy != "" {
+		if err := sortItems(items, itemType(value), o.SortBy); err != nil {
+			return err
+		}
+	}
+
+	switch o.Format {
+	case FormatTable:
+		return renderTable(w, items, columns, o)
+	case FormatCSV:
+		return renderCSV(w, items, columns, o)
+	case FormatJSON:
+		data, err := marshalJSON(items, single, columns, o)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(data)
+
+		return err
+	case FormatYAML:
+		data, err := marshalJSON(items, single, columns, o)
+		if err != nil {
+			return err
+		}
+
+		return renderYAML(w, data)
+	case FormatTemplate:
+		return renderTemplate(w, items, o)
+	default:
+		return fmt.Errorf("%w: %q", ErrUnsupportedFormat, o.Format)
+	}
+}
+
+// collectItems returns items of a slice or the value itself.
+func collectItems(value interface{}) ([]reflect.Value, bool) {
+	v := reflect.ValueOf(value)
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil, true
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return []reflect.Value{v}, true
+	}
+	if v.Type().Elem().Kind() == reflect.Uint8 {
+		// []byte and json.RawMessage are rendered as a single value.
+		return []reflect.Value{v}, true
+	}
+
+	items := make([]reflect.Value, 0, v.Len())
+	for i := 0; i < v.Len(); i++ {
+		items = append(items, v.Index(i))
+	}
+
+	return items, false
+}
+
+// itemType returns a type of items of the value.
+func itemType(value interface{}) reflect.Type {
+	t := reflect.TypeOf(value)
+	if t == nil {
+		return nil
+	}
+	t = indirectType(t)
+	if (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) && t.Elem().Kind() != reflect.Uint8 {
+		t = indirectType(t.Elem())
+	}
+
+	return t
+}
+
+func renderTable(w io.Writer, items []reflect.Value, columns []column, o Opts) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	if !o.NoHeader {
+		headers := make([]string, 0, len(columns))
+		for _, c := range columns {
+			headers = append(headers, c.header())
+		}
+		fmt.Fprintln(tw, strings.Join(headers, "\t"))
+	}
+	for _, item := range items {
+		cells := make([]string, 0, len(columns))
+		for _, c := range columns {
+			cells = append(cells, humanValue(c, c.value(item), o.Now))
+		}
+		fmt.Fprintln(tw, strings.Join(cells, "\t"))
+	}
+
+	return tw.Flush()
+}
+
+func renderCSV(w io.Writer, items []reflect.Value, columns []column, o Opts) error {
+	writer := csv.NewWriter(w)
+	if !o.NoHeader {
+		names := make([]string, 0, len(columns))
+		for _, c := range columns {
+			names = append(names, c.name)
+		}
+		if err := writer.Write(names); err != nil {
+			return err
+		}
+	}
+	for _, item := range items {
+		cells := make([]string, 0, len(columns))
+		for _, c := range columns {
+			cells = append(cells, rawValue(c.value(item)))
+		}
+		if err := writer.Write(cells); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+
+	return writer.Error()
+}
+
+// marshalJSON renders the items as indented JSON. When columns are selected
+// explicitly, only their values are kept.
+func marshalJSON(items []reflect.Value, single bool, columns []column, o Opts) ([]byte, error) {
+	var value interface{}
+	if len(o.Columns) == 0 {
+		values := make([]interface{}, 0, len(items))
+		for _, item := range items {
+			values = append(values, item.Interface())
+		}
+		value = values
+		if single {
+			value = nil
+			if len(values) == 1 {
+				value = values[0]
+			}
+		}
+	} else {
+		records := make([]record, 0, len(items))
+		for _, item := range items {
+			r := make(record, 0, len(columns))
+			for _, c := range columns {
+				v := c.value(item)
+				var field interface{}
+				if v.IsValid() {
+					field = v.Interface()
+				}
+				r = append(r, recordField{name: c.name, value: field})
+			}
+			records = append(records, r)
+		}
+		value = records
+		if single && len(records) == 1 {
+			value = records[0]
+		}
+	}
+
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	return append(data, '\n'), nil
+}
+
+// record is a JSON object which keeps the order of selected columns.
+type record []recordField
+
+type recordField struct {
+	name  string
+	value interface{}
+}
+
+func (r record) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, f := range r {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		name, err := json.Marshal(f.name)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(f.value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(name)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+
+	return buf.Bytes(), nil
+}
+
+// renderYAML converts JSON into block-style YAML keeping the order of keys.
+func renderYAML(w io.Writer, data []byte) error {
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return err
+	}
+	resetStyle(&node)
+
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&node); err != nil {
+		return err
+	}
+
+	return encoder.Close()
+}
+
+func resetStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		resetStyle(child)
+	}
+}
+
+func renderTemplate(w io.Writer, items []reflect.Value, o Opts) error {
+	if o.Template == "" {
+		return ErrTemplateEmpty
+	}
+	tmpl, err := template.New("format").Funcs(templateFuncs(o.Now)).Parse(o.Template)
+	if err != nil {
+		return err
+	}
+	for _, item := range items {
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, item.Interface()); err != nil {
+			return err
+		}
+		if buf.Len() == 0 || buf.Bytes()[buf.Len()-1] != '\n' {
+			buf.WriteByte('\n')
+		}
+		if _, err := w.Write(buf.Bytes()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// templateFuncs returns helper functions available in templates.
+func templateFuncs(now time.Time) template.FuncMap {
+	return template.FuncMap{
+		"humanSize": HumanSize,
+		"relativeTime": func(t interface{}) string {
+			switch v := t.(type) {
+			case time.Time:
+				return RelativeTime(v, now)
+			case *time.Time:
+				if v == nil {
+					return ""
+				}
+
+				return RelativeTime(*v, now)
+			default:
+				return fmt.Sprint(t)
+			}
+		},
+		"join":  strings.Join,
+		"upper": strings.ToUpper,
+		"lower": strings.ToLower,
+		"json": func(v interface{}) (string, error) {
+			data, err := json.Marshal(v)
+
+			return string(data), err
+		},
+	}
+}
+
+// sortItems sorts the items by the column in place.
+func sortItems(items []reflect.Value, t reflect.Type, sortBy string) error {
+	desc := strings.HasPrefix(sortBy, "-")
+	c, err := findColumn(t, strings.TrimPrefix(sortBy, "-"))
+	if err != nil {
+		return err
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		if desc {
+			return less(c.value(items[j]), c.value(items[i]))
+		}
+
+		return less(c.value(items[i]), c.value(items[j]))
+	})
+
+	return nil
+}
@@ -0,0 +1,74 @@
+package testing
+
+import (
+	"time"
+
+	"github.com/selectel/craas-go/pkg/v1/registry"
+	tokenV2 "github.com/selectel/craas-go/pkg/v2/token"
+)
+
+var testNow = time.Date(2022, 2, 1, 12, 0, 0, 0, time.UTC)
+
+var testRegistries = []*registry.Registry{
+	{
+		ID:        "888af692-c646-4b76-a234-81ca9b5bcafe",
+		Name:      "small-registry",
+		CreatedAt: time.Date(2022, 1, 29, 12, 0, 0, 0, time.UTC),
+		Status:    registry.StatusActive,
+		Size:      1536,
+		SizeLimit: 1073741824,
+		Used:      0.5,
+	},
+	{
+		ID:        "4fa2d5d5-a7f1-4e2b-8a85-8d1e0f6c0e3c",
+		Name:      "big-registry",
+		CreatedAt: time.Date(2022, 2, 1, 10, 0, 0, 0, time.UTC),
+		Status:    registry.StatusGC,
+		Size:      5242880,
+		SizeLimit: 1073741824,
+		Used:      12.25,
+	},
+}
+
+const expectedRegistriesTable = `NAME            STATUS              SIZE     CREATED AT
+big-registry    GARBAGE_COLLECTION  5.0 MiB  2 hours ago
+small-registry  ACTIVE              1.5 KiB  3 days ago
+`
+
+const expectedRegistriesCSV = `name,size,createdAt
+small-registry,1536,2022-01-29T12:00:00Z
+big-registry,5242880,2022-02-01T10:00:00Z
+`
+
+const expectedRegistriesYAML = `- name: small-registry
+  status: ACTIVE
+- name: big-registry
+  status: GARBAGE_COLLECTION
+`
+
+var testExpiresAt = time.Date(2022, 2, 4, 12, 0, 0, 0, time.UTC)
+
+var testTokenV2 = tokenV2.TokenV2{
+	ID:         "c29e3f63-0711-4772-a415-ad79973bdaef",
+	Name:       "ci",
+	Expiration: tokenV2.Expiration{IsSet: true, ExpiresAt: testExpiresAt},
+	Scope: tokenV2.Scope{
+		ModeRW:      true,
+		RegistryIDs: []string{"888af692-c646-4b76-a234-81ca9b5bcafe", "4fa2d5d5-a7f1-4e2b-8a85-8d1e0f6c0e3c"},
+	},
+	Status: tokenV2.StatusActive,
+}
+
+const expectedTokenTable = `NAME  SCOPE MODE RW  SCOPE REGISTRY IDS                                                         EXPIRATION EXPIRES AT  LAST USED AT
+ci    true           888af692-c646-4b76-a234-81ca9b5bcafe,4fa2d5d5-a7f1-4e2b-8a85-8d1e0f6c0e3c  in 3 days              -
+`
+
+const expectedTokenJSON = `{
+  "name": "ci",
+  "scope.modeRW": true,
+  "expiration.expiresAt": "2022-02-04T12:00:00Z"
+}
+`
+
+const expectedTokenTemplate = `ci expires in 3 days
+`
@@ -0,0 +1,135 @@
+package testing
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/selectel/craas-go/pkg/format"
+	tokenV2 "github.com/selectel/craas-go/pkg/v2/token"
+)
+
+func render(t *testing.T, value interface{}, opts *format.Opts) string {
+	t.Helper()
+
+	opts.Now = testNow
+	var buf bytes.Buffer
+	if err := format.Render(&buf, value, opts); err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.String()
+}
+
+func TestRenderTableSortedDesc(t *testing.T) {
+	actual := render(t, testRegistries, &format.Opts{
+		Columns: []string{"name", "status", "size", "createdAt"},
+		SortBy:  "-size",
+	})
+	if actual != expectedRegistriesTable {
+		t.Fatalf("expected %q, but got %q", expectedRegistriesTable, actual)
+	}
+}
+
+func TestRenderCSV(t *testing.T) {
+	actual := render(t, testRegistries, &format.Opts{
+		Format:  format.FormatCSV,
+		Columns: []string{"name", "size", "createdAt"},
+		SortBy:  "createdAt",
+	})
+	if actual != expectedRegistriesCSV {
+		t.Fatalf("expected %q, but got %q", expectedRegistriesCSV, actual)
+	}
+}
+
+func TestRenderYAMLColumns(t *testing.T) {
+	actual := render(t, testRegistries, &format.Opts{
+		Format:  format.FormatYAML,
+		Columns: []string{"name", "status"},
+	})
+	if actual != expectedRegistriesYAML {
+		t.Fatalf("expected %q, but got %q", expectedRegistriesYAML, actual)
+	}
+}
+
+func TestRenderNestedColumns(t *testing.T) {
+	columns := []string{"name", "scope.modeRW", "scope.registryIds", "expiration.expiresAt", "lastUsedAt"}
+	actual := render(t, &testTokenV2, &format.Opts{Columns: columns})
+	if actual != expectedTokenTable {
+		t.Fatalf("expected %q, but got %q", expectedTokenTable, actual)
+	}
+
+	actual = render(t, testTokenV2, &format.Opts{
+		Format:  format.FormatJSON,
+		Columns: []string{"name", "scope.modeRW", "expiration.expiresAt"},
+	})
+	if actual != expectedTokenJSON {
+		t.Fatalf("expected %q, but got %q", expectedTokenJSON, actual)
+	}
+}
+
+func TestRenderTemplate(t *testing.T) {
+	actual := render(t, []tokenV2.TokenV2{testTokenV2}, &format.Opts{
+		Format:   format.FormatTemplate,
+		Template: `{{.Name}} expires {{relativeTime .Expiration.ExpiresAt}}`,
+	})
+	if actual != expectedTokenTemplate {
+		t.Fatalf("expected %q, but got %q", expectedTokenTemplate, actual)
+	}
+}
+
+func TestRenderErrors(t *testing.T) {
+	var buf bytes.Buffer
+	err := format.Render(&buf, testRegistries, &format.Opts{Columns: []string{"unknown"}})
+	if !errors.Is(err, format.ErrUnknownColumn) {
+		t.Fatalf("expected %v, but got %v", format.ErrUnknownColumn, err)
+	}
+	err = format.Render(&buf, testRegistries, &format.Opts{Format: "xml"})
+	if !errors.Is(err, format.ErrUnsupportedFormat) {
+		t.Fatalf("expected %v, but got %v", format.ErrUnsupportedFormat, err)
+	}
+	err = format.Render(&buf, testRegistries, &format.Opts{Format: format.FormatTemplate})
+	if !errors.Is(err, format.ErrTemplateEmpty) {
+		t.Fatalf("expected %v, but got %v", format.ErrTemplateEmpty, err)
+	}
+}
+
+func TestHumanSize(t *testing.T) {
+	testCases := map[int64]string{
+		0:          "0 B",
+		1023:       "1023 B",
+		1024:       "1.0 KiB",
+		1572864:    "1.5 MiB",
+		1073741824: "1.0 GiB",
+	}
+	for size, expected := range testCases {
+		if actual := format.HumanSize(size); actual != expected {
+			t.Fatalf("expected %q, but got %q", expected, actual)
+		}
+	}
+}
+
+func TestParseSize(t *testing.T) {
+	testCases := map[string]int64{
+		"512":     512,
+		"1.5GiB":  1610612736,
+		"500 MB":  500000000,
+		"2g":      2147483648,
+		"10 KiB ": 10240,
+	}
+	for text, expected := range testCases {
+		actual, err := format.ParseSize(text)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if actual != expected {
+			t.Fatalf("expected %d, but got %d", expected, actual)
+		}
+	}
+
+	for _, text := range []string{"", "GiB", "1 PB", "-1"} {
+		if _, err := format.ParseSize(text); !errors.Is(err, format.ErrInvalidSize) {
+			t.Fatalf("expected %v for %q, but got %v", format.ErrInvalidSize, text, err)
+		}
+	}
+}
@@ -0,0 +1,246 @@
+package format
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// emptyCell is rendered in tables for empty values.
+const emptyCell = "-"
+
+// rawValue returns a machine-readable text of the value.
+func rawValue(v reflect.Value) string {
+	if !v.IsValid() {
+		return ""
+	}
+	if v.Type() == timeType {
+		t := v.Interface().(time.Time)
+		if t.IsZero() {
+			return ""
+		}
+
+		return t.UTC().Format(time.RFC3339)
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return v.String()
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(v.Uint(), 10)
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'f', -1, v.Type().Bits())
+	case reflect.Slice, reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return string(v.Bytes())
+		}
+		if !isScalar(v.Type().Elem()) {
+			return strconv.Itoa(v.Len())
+		}
+		parts := make([]string, 0, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			parts = append(parts, rawValue(indirect(v.Index(i))))
+		}
+
+		return strings.Join(parts, ",")
+	case reflect.Map:
+		parts := make([]string, 0, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			parts = append(parts, rawValue(indirect(iter.Key()))+"="+rawValue(indirect(iter.Value())))
+		}
+		sort.Strings(parts)
+
+		return strings.Join(parts, ",")
+	default:
+		return fmt.Sprint(v.Interface())
+	}
+}
+
+// humanValue returns a human-readable text of the column value.
+func humanValue(c column, v reflect.Value, now time.Time) string {
+	if !v.IsValid() {
+		return emptyCell
+	}
+	if v.Type() == timeType {
+		t := v.Interface().(time.Time)
+		if t.IsZero() {
+			return emptyCell
+		}
+
+		return RelativeTime(t, now)
+	}
+	if isSizeColumn(c) {
+		switch v.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return HumanSize(v.Int())
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			return HumanSize(int64(v.Uint()))
+		}
+	}
+	if text := rawValue(v); text != "" {
+		return text
+	}
+
+	return emptyCell
+}
+
+// isSizeColumn reports whether the column holds a size in bytes.
+func isSizeColumn(c column) bool {
+	name := c.name
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		name = name[i+1:]
+	}
+
+	return strings.Contains(strings.ToLower(name), "size")
+}
+
+func isScalar(t reflect.Type) bool {
+	t = indirectType(t)
+	if t == timeType {
+		return true
+	}
+	switch t.Kind() {
+	case reflect.Struct, reflect.Map, reflect.Slice, reflect.Array, reflect.Interface, reflect.Func, reflect.Chan:
+		return false
+	default:
+		return true
+	}
+}
+
+// less compares values of a column for sorting, invalid values go first.
+func less(a, b reflect.Value) bool {
+	if !a.IsValid() || !b.IsValid() {
+		return !a.IsValid() && b.IsValid()
+	}
+	if a.Type() == timeType {
+		return a.Interface().(time.Time).Before(b.Interface().(time.Time))
+	}
+
+	switch a.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return a.Int() < b.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return a.Uint() < b.Uint()
+	case reflect.Float32, reflect.Float64:
+		return a.Float() < b.Float()
+	case reflect.Bool:
+		return !a.Bool() && b.Bool()
+	case reflect.Slice, reflect.Array, reflect.Map:
+		if !isScalar(a.Type().Elem()) {
+			return a.Len() < b.Len()
+		}
+	}
+
+	return rawValue(a) < rawValue(b)
+}
+
+// HumanSize returns a size in bytes with a binary unit, for example "1.5 MiB".
+func HumanSize(size int64) string {
+	const unit = 1024
+	if size < unit && size > -unit {
+		return strconv.FormatInt(size, 10) + " B"
+	}
+
+	value := float64(size)
+	units := []string{"KiB", "MiB", "GiB", "TiB", "PiB", "EiB"}
+	i := -1
+	for (value >= unit || value <= -unit) && i < len(units)-1 {
+		value /= unit
+		i++
+	}
+
+	return strconv.FormatFloat(value, 'f', 1, 64) + " " + units[i]
+}
+
+// sizeUnits are multipliers of size suffixes accepted by ParseSize.
+var sizeUnits = map[string]int64{
+	"":    1,
+	"B":   1,
+	"K":   1 << 10,
+	"KB":  1000,
+	"KIB": 1 << 10,
+	"M":   1 << 20,
+	"MB":  1000 * 1000,
+	"MIB": 1 << 20,
+	"G":   1 << 30,
+	"GB":  1000 * 1000 * 1000,
+	"GIB": 1 << 30,
+	"T":   1 << 40,
+	"TB":  1000 * 1000 * 1000 * 1000,
+	"TIB": 1 << 40,
+}
+
+// ParseSize parses a size like "512", "1.5GiB" or "500 MB" into bytes.
+// Suffixes without "i" are decimal, single letters are binary.
+func ParseSize(text string) (int64, error) {
+	trimmed := strings.TrimSpace(text)
+	i := strings.IndexFunc(trimmed, func(r rune) bool {
+		return (r < '0' || r > '9') && r != '.'
+	})
+	if i < 0 {
+		i = len(trimmed)
+	}
+	number, unit := trimmed[:i], strings.ToUpper(strings.TrimSpace(trimmed[i:]))
+
+	multiplier, ok := sizeUnits[unit]
+	if !ok {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidSize, text)
+	}
+	value, err := strconv.ParseFloat(number, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidSize, text)
+	}
+
+	return int64(value * float64(multiplier)), nil
+}
+
+// RelativeTime returns a time relative to now, for example "3 days ago" or "in 2 hours".
+func RelativeTime(t, now time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+
+	d := now.Sub(t)
+	future := d < 0
+	if future {
+		d = -d
+	}
+	if d < time.Minute {
+		return "just now"
+	}
+
+	var text string
+	switch {
+	case d < time.Hour:
+		text = plural(int(d/time.Minute), "minute")
+	case d < 48*time.Hour:
+		text = plural(int(d/time.Hour), "hour")
+	case d < 60*24*time.Hour:
+		text = plural(int(d/(24*time.Hour)), "day")
+	case d < 2*365*24*time.Hour:
+		text = plural(int(d/(30*24*time.Hour)), "month")
+	default:
+		text = plural(int(d/(365*24*time.Hour)), "year")
+	}
+	if future {
+		return "in " + text
+	}
+
+	return text + " ago"
+}
+
+func plural(n int, unit string) string {
+	if n == 1 {
+		return "1 " + unit
+	}
+
+	return strconv.Itoa(n) + " " + unit + "s"
+}
@@ -0,0 +1,117 @@
+package metrics
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/selectel/craas-go/pkg/v1/client"
+	"github.com/selectel/craas-go/pkg/v1/gc"
+	"github.com/selectel/craas-go/pkg/v1/registry"
+	"github.com/selectel/craas-go/pkg/v1/repository"
+)
+
+// CollectorOpts represents options of the collector.
+type CollectorOpts struct {
+	// SkipGarbageSize disables garbage size requests.
+	SkipGarbageSize bool
+
+	// SkipRepositories disables repository list requests.
+	SkipRepositories bool
+
+	// Now returns the current time, time.Now is used by default.
+	Now func() time.Time
+}
+
+// Collector collects registry usage and counts failed requests.
+type Collector struct {
+	client *client.ServiceClient
+	opts   CollectorOpts
+
+	mu     sync.Mutex
+	errors map[Operation]int64
+}
+
+// NewCollector returns a collector using the v1 client.
+func NewCollector(client *client.ServiceClient, opts *CollectorOpts) *Collector {
+	c := &Collector{
+		client: client,
+		errors: map[Operation]int64{
+			OperationListRegistries:   0,
+			OperationGarbageSize:      0,
+			OperationListRepositories: 0,
+		},
+	}
+	if opts != nil {
+		c.opts = *opts
+	}
+	if c.opts.Now == nil {
+		c.opts.Now = time.Now
+	}
+
+	return c
+}
+
+// Collect requests registry usage. Failed requests are counted and skipped,
+// so a snapshot is always returned.
+func (c *Collector) Collect(ctx context.Context) *Snapshot {
+	start := c.opts.Now()
+	snapshot := &Snapshot{CollectedAt: start}
+
+	registries, _, err := registry.List(ctx, c.client)
+	if err != nil {
+		c.countError(OperationListRegistries)
+	} else {
+		snapshot.Up = true
+		for _, r := range registries {
+			snapshot.Registries = append(snapshot.Registries, c.collectRegistry(ctx, r))
+		}
+	}
+
+	snapshot.Duration = c.opts.Now().Sub(start)
+	snapshot.Errors = c.errorCounts()
+
+	return snapshot
+}
+
+func (c *Collector) collectRegistry(ctx context.Context, r *registry.Registry) *RegistryMetrics {
+	result := &RegistryMetrics{Registry: r}
+
+	if !c.opts.SkipGarbageSize {
+		size, _, err := gc.GetGarbageSize(ctx, c.client, r.ID)
+		if err != nil {
+			c.countError(OperationGarbageSize)
+		} else {
+			result.GarbageSize = size
+		}
+	}
+	if !c.opts.SkipRepositories {
+		repositories, _, err := repository.ListRepositories(ctx, c.client, r.ID)
+		if err != nil {
+			c.countError(OperationListRepositories)
+		} else {
+			result.Repositories = repositories
+		}
+	}
+
+	return result
+}
+
+func (c *Collector) countError(op Operation) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.errors[op]++
+}
+
+func (c *Collector) errorCounts() map[Operation]int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	counts := make(map[Operation]int64, len(c.errors))
+	for op, n := range c.errors {
+		counts[op] = n
+	}
+
+	return counts
+}
@@ -0,0 +1,26 @@
+/*
+Package `metrics` provides a set of functions for collecting registry usage
+and rendering it in the Prometheus text exposition format.
+
+A collection lists registries, requests garbage sizes and repositories of each
+registry. Failed requests don't stop the collection, they are counted in the
+craas_scrape_errors_total counter by operation.
+
+Example of collecting metrics once and printing them:
+
+	collector := metrics.NewCollector(craasClient, nil)
+	snapshot := collector.Collect(ctx)
+	err := snapshot.WriteText(os.Stdout)
+	if err != nil {
+	    log.Fatal(err)
+	}
+
+Example of writing metrics for the node-exporter textfile collector:
+
+	snapshot := metrics.NewCollector(craasClient, nil).Collect(ctx)
+	err := snapshot.WriteTextFile("/var/lib/node_exporter/textfile/craas.prom")
+	if err != nil {
+	    log.Fatal(err)
+	}
+*/
+package metrics
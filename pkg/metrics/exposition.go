@@ -0,0 +1,152 @@
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/selectel/craas-go/pkg/v1/registry"
+)
+
+// registryStatuses are values of the craas_registry_status state set.
+var registryStatuses = []registry.Status{
+	registry.StatusActive,
+	registry.StatusCreating,
+	registry.StatusDeleting,
+	registry.StatusGC,
+	registry.StatusError,
+	registry.StatusUnknown,
+}
+
+// family represents a metric family of the exposition.
+type family struct {
+	name    string
+	help    string
+	typ     string
+	samples []sample
+}
+
+type sample struct {
+	labels [][2]string
+	value  float64
+}
+
+func (f *family) add(value float64, labels ...[2]string) {
+	f.samples = append(f.samples, sample{labels: labels, value: value})
+}
+
+func label(name, value string) [2]string {
+	return [2]string{name, value}
+}
+
+// WriteText writes the snapshot in the Prometheus text exposition format.
+func (s *Snapshot) WriteText(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	for _, f := range s.families() {
+		fmt.Fprintf(bw, "# HELP %s %s\n", f.name, f.help)
+		fmt.Fprintf(bw, "# TYPE %s %s\n", f.name, f.typ)
+		for _, smp := range f.samples {
+			bw.WriteString(f.name)
+			if len(smp.labels) > 0 {
+				bw.WriteByte('{')
+				for i, l := range smp.labels {
+					if i > 0 {
+						bw.WriteByte(',')
+					}
+					fmt.Fprintf(bw, "%s=\"%s\"", l[0], escapeLabel(l[1]))
+				}
+				bw.WriteByte('}')
+			}
+			bw.WriteByte(' ')
+			bw.WriteString(strconv.FormatFloat(smp.value, 'g', -1, 64))
+			bw.WriteByte('\n')
+		}
+	}
+
+	return bw.Flush()
+}
+
+func (s *Snapshot) families() []*family {
+	up := &family{name: "craas_up", help: "Whether registries were listed successfully.", typ: "gauge"}
+	up.add(boolValue(s.Up))
+	lastCollection := &family{
+		name: "craas_last_collection_timestamp_seconds", help: "Time of the last collection.", typ: "gauge",
+	}
+	lastCollection.add(float64(s.CollectedAt.UnixNano()) / 1e9)
+	duration := &family{name: "craas_collection_duration_seconds", help: "Duration of the last collection.", typ: "gauge"}
+	duration.add(s.Duration.Seconds())
+
+	errors := &family{
+		name: "craas_scrape_errors_total", help: "Total number of failed API requests by operation.", typ: "counter",
+	}
+	ops := make([]string, 0, len(s.Errors))
+	for op := range s.Errors {
+		ops = append(ops, string(op))
+	}
+	sort.Strings(ops)
+	for _, op := range ops {
+		errors.add(float64(s.Errors[Operation(op)]), label("operation", op))
+	}
+
+	size := &family{name: "craas_registry_size_bytes", help: "Size of the registry.", typ: "gauge"}
+	sizeLimit := &family{name: "craas_registry_size_limit_bytes", help: "Size limit of the registry.", typ: "gauge"}
+	used := &family{name: "craas_registry_used_percent", help: "Used percentage of the registry size limit.", typ: "gauge"}
+	status := &family{name: "craas_registry_status", help: "Status of the registry.", typ: "gauge"}
+	untagged := &family{
+		name: "craas_registry_garbage_untagged_bytes", help: "Size of untagged images of the registry.", typ: "gauge",
+	}
+	nonReferenced := &family{
+		name: "craas_registry_garbage_non_referenced_bytes", help: "Size of non-referenced blobs of the registry.", typ: "gauge",
+	}
+	garbage := &family{
+		name: "craas_registry_garbage_bytes", help: "Size of the registry a garbage collection can free.", typ: "gauge",
+	}
+	repositories := &family{name: "craas_registry_repositories", help: "Number of repositories of the registry.", typ: "gauge"}
+	repositorySize := &family{name: "craas_repository_size_bytes", help: "Size of the repository.", typ: "gauge"}
+
+	for _, m := range s.Registries {
+		r := m.Registry
+		id, name := label("id", r.ID), label("registry", r.Name)
+		size.add(float64(r.Size), id, name)
+		sizeLimit.add(float64(r.SizeLimit), id, name)
+		used.add(float64(r.Used), id, name)
+		for _, st := range registryStatuses {
+			status.add(boolValue(r.Status == st), id, name, label("status", string(st)))
+		}
+		if m.GarbageSize != nil {
+			untagged.add(float64(m.GarbageSize.Untagged), id, name)
+			nonReferenced.add(float64(m.GarbageSize.NonReferenced), id, name)
+			garbage.add(float64(m.GarbageSize.Summary), id, name)
+		}
+		if m.Repositories != nil {
+			repositories.add(float64(len(m.Repositories)), id, name)
+			for _, repo := range m.Repositories {
+				repositorySize.add(float64(repo.Size), id, name, label("repository", repo.Name))
+			}
+		}
+	}
+
+	return []*family{
+		up, lastCollection, duration, errors,
+		size, sizeLimit, used, status,
+		untagged, nonReferenced, garbage,
+		repositories, repositorySize,
+	}
+}
+
+func boolValue(v bool) float64 {
+	if v {
+		return 1
+	}
+
+	return 0
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabel(v string) string {
+	return labelEscaper.Replace(v)
+}
@@ -0,0 +1,49 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/selectel/craas-go/pkg/v1/gc"
+	"github.com/selectel/craas-go/pkg/v1/registry"
+	"github.com/selectel/craas-go/pkg/v1/repository"
+)
+
+// Operation represents a request of the collection.
+type Operation string
+
+const (
+	OperationListRegistries   Operation = "list_registries"
+	OperationGarbageSize      Operation = "garbage_size"
+	OperationListRepositories Operation = "list_repositories"
+)
+
+// Snapshot represents a result of the collection.
+type Snapshot struct {
+	// CollectedAt is a time the collection started.
+	CollectedAt time.Time
+
+	// Duration is a duration of the collection.
+	Duration time.Duration
+
+	// Up reports whether registries were listed successfully.
+	Up bool
+
+	// Registries contains metrics of every registry.
+	Registries []*RegistryMetrics
+
+	// Errors contains a total number of failed requests by operation
+	// since the collector was created.
+	Errors map[Operation]int64
+}
+
+// RegistryMetrics represents usage of a registry.
+type RegistryMetrics struct {
+	// Registry is the registry as returned by registry.List.
+	Registry *registry.Registry
+
+	// GarbageSize is nil if it wasn't collected.
+	GarbageSize *gc.GarbageSize
+
+	// Repositories is nil if they weren't collected.
+	Repositories []*repository.Repository
+}
@@ -0,0 +1,87 @@
+package testing
+
+const testListRegistriesResponse = `
+[
+    {
+        "createdAt": "2022-01-28T08:50:34.123Z",
+        "id": "888af692-c646-4b76-a234-81ca9b5bcafe",
+        "name": "test-registry",
+        "size": 1024,
+        "sizeLimit": 2048,
+        "status": "ACTIVE",
+        "used": 50
+    }
+]
+`
+
+const testGarbageSizeResponse = `
+{
+    "sizeNonReferenced": 100,
+    "sizeUntagged": 200,
+    "sizeSummary": 300
+}
+`
+
+const testListRepositoriesResponse = `
+[
+    {
+        "name": "alpine",
+        "size": 512,
+        "updatedAt": "2022-01-28T08:51:34.123Z"
+    },
+    {
+        "name": "team/\"quoted\"",
+        "size": 256,
+        "updatedAt": "2022-01-28T08:51:34.123Z"
+    }
+]
+`
+
+const expectedMetrics = `# HELP craas_up Whether registries were listed successfully.
+# TYPE craas_up gauge
+craas_up 1
+# HELP craas_last_collection_timestamp_seconds Time of the last collection.
+# TYPE craas_last_collection_timestamp_seconds gauge
+craas_last_collection_timestamp_seconds 1.6432128e+09
+# HELP craas_collection_duration_seconds Duration of the last collection.
+# TYPE craas_collection_duration_seconds gauge
+craas_collection_duration_seconds 0
+# HELP craas_scrape_errors_total Total number of failed API requests by operation.
+# TYPE craas_scrape_errors_total counter
+craas_scrape_errors_total{operation="garbage_size"} 0
+craas_scrape_errors_total{operation="list_registries"} 0
+craas_scrape_errors_total{operation="list_repositories"} 0
+# HELP craas_registry_size_bytes Size of the registry.
+# TYPE craas_registry_size_bytes gauge
+craas_registry_size_bytes{id="888af692-c646-4b76-a234-81ca9b5bcafe",registry="test-registry"} 1024
+# HELP craas_registry_size_limit_bytes Size limit of the registry.
+# TYPE craas_registry_size_limit_bytes gauge
+craas_registry_size_limit_bytes{id="888af692-c646-4b76-a234-81ca9b5bcafe",registry="test-registry"} 2048
+# HELP craas_registry_used_percent Used percentage of the registry size limit.
+# TYPE craas_registry_used_percent gauge
+craas_registry_used_percent{id="888af692-c646-4b76-a234-81ca9b5bcafe",registry="test-registry"} 50
+# HELP craas_registry_status Status of the registry.
+# TYPE craas_registry_status gauge
+craas_registry_status{id="888af692-c646-4b76-a234-81ca9b5bcafe",registry="test-registry",status="ACTIVE"} 1
+craas_registry_status{id="888af692-c646-4b76-a234-81ca9b5bcafe",registry="test-registry",status="CREATING"} 0
+craas_registry_status{id="888af692-c646-4b76-a234-81ca9b5bcafe",registry="test-registry",status="DELETING"} 0
+craas_registry_status{id="888af692-c646-4b76-a234-81ca9b5bcafe",registry="test-registry",status="GARBAGE_COLLECTION"} 0
+craas_registry_status{id="888af692-c646-4b76-a234-81ca9b5bcafe",registry="test-registry",status="ERROR"} 0
+craas_registry_status{id="888af692-c646-4b76-a234-81ca9b5bcafe",registry="test-registry",status="UNKNOWN"} 0
+# HELP craas_registry_garbage_untagged_bytes Size of untagged images of the registry.
+# TYPE craas_registry_garbage_untagged_bytes gauge
+craas_registry_garbage_untagged_bytes{id="888af692-c646-4b76-a234-81ca9b5bcafe",registry="test-registry"} 200
+# HELP craas_registry_garbage_non_referenced_bytes Size of non-referenced blobs of the registry.
+# TYPE craas_registry_garbage_non_referenced_bytes gauge
+craas_registry_garbage_non_referenced_bytes{id="888af692-c646-4b76-a234-81ca9b5bcafe",registry="test-registry"} 100
+# HELP craas_registry_garbage_bytes Size of the registry a garbage collection can free.
+# TYPE craas_registry_garbage_bytes gauge
+craas_registry_garbage_bytes{id="888af692-c646-4b76-a234-81ca9b5bcafe",registry="test-registry"} 300
+# HELP craas_registry_repositories Number of repositories of the registry.
+# TYPE craas_registry_repositories gauge
+craas_registry_repositories{id="888af692-c646-4b76-a234-81ca9b5bcafe",registry="test-registry"} 2
+# HELP craas_repository_size_bytes Size of the repository.
+# TYPE craas_repository_size_bytes gauge
+craas_repository_size_bytes{id="888af692-c646-4b76-a234-81ca9b5bcafe",registry="test-registry",repository="alpine"} 512
+craas_repository_size_bytes{id="888af692-c646-4b76-a234-81ca9b5bcafe",registry="test-registry",repository="team/\"quoted\""} 256
+`
@@ -0,0 +1,162 @@
+package testing
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/selectel/craas-go/pkg/metrics"
+	"github.com/selectel/craas-go/pkg/testutils"
+	"github.com/selectel/craas-go/pkg/v1/client"
+)
+
+const testRegistryURL = "/api/v1/registries/888af692-c646-4b76-a234-81ca9b5bcafe"
+
+var testNow = time.Date(2022, 1, 26, 16, 0, 0, 0, time.UTC)
+
+func newTestCollector(t *testing.T, endpoint string) *metrics.Collector {
+	t.Helper()
+
+	craasClient, err := client.NewCRaaSClientV1(testutils.TokenID, endpoint)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return metrics.NewCollector(craasClient, &metrics.CollectorOpts{
+		Now: func() time.Time { return testNow },
+	})
+}
+
+func TestCollect(t *testing.T) {
+	registriesCalled, garbageCalled, repositoriesCalled := false, false, false
+	testEnv := testutils.SetupTestEnv()
+	defer testEnv.TearDownTestEnv()
+
+	testutils.HandleReqWithoutBody(t, &testutils.HandleReqOpts{
+		Mux:         testEnv.Mux,
+		URL:         "/api/v1/registries",
+		RawResponse: testListRegistriesResponse,
+		Method:      http.MethodGet,
+		Status:      http.StatusOK,
+		CallFlag:    &registriesCalled,
+	})
+	testutils.HandleReqWithoutBody(t, &testutils.HandleReqOpts{
+		Mux:         testEnv.Mux,
+		URL:         testRegistryURL + "/garbage-collection/size",
+		RawResponse: testGarbageSizeResponse,
+		Method:      http.MethodGet,
+		Status:      http.StatusOK,
+		CallFlag:    &garbageCalled,
+	})
+	testutils.HandleReqWithoutBody(t, &testutils.HandleReqOpts{
+		Mux:         testEnv.Mux,
+		URL:         testRegistryURL + "/repositories",
+		RawResponse: testListRepositoriesResponse,
+		Method:      http.MethodGet,
+		Status:      http.StatusOK,
+		CallFlag:    &repositoriesCalled,
+	})
+
+	snapshot := newTestCollector(t, testEnv.Server.URL+"/api/v1").Collect(context.Background())
+	if !registriesCalled || !garbageCalled || !repositoriesCalled {
+		t.Fatal("endpoints weren't called")
+	}
+
+	var buf bytes.Buffer
+	if err := snapshot.WriteText(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if actual := buf.String(); actual != expectedMetrics {
+		t.Fatalf("expected %s, but got %s", expectedMetrics, actual)
+	}
+}
+
+func TestCollectCountsErrors(t *testing.T) {
+	registriesCalled := false
+	testEnv := testutils.SetupTestEnv()
+	defer testEnv.TearDownTestEnv()
+
+	testutils.HandleReqWithoutBody(t, &testutils.HandleReqOpts{
+		Mux:         testEnv.Mux,
+		URL:         "/api/v1/registries",
+		RawResponse: testListRegistriesResponse,
+		Method:      http.MethodGet,
+		Status:      http.StatusOK,
+		CallFlag:    &registriesCalled,
+	})
+	testEnv.Mux.HandleFunc(testRegistryURL+"/garbage-collection/size", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+	testEnv.Mux.HandleFunc(testRegistryURL+"/repositories", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+
+	collector := newTestCollector(t, testEnv.Server.URL+"/api/v1")
+	collector.Collect(context.Background())
+	snapshot := collector.Collect(context.Background())
+
+	if !snapshot.Up {
+		t.Fatal("expected the collection to be up")
+	}
+	if snapshot.Errors[metrics.OperationGarbageSize] != 2 || snapshot.Errors[metrics.OperationListRepositories] != 2 {
+		t.Fatalf("expected 2 errors of each operation, but got %v", snapshot.Errors)
+	}
+
+	var buf bytes.Buffer
+	if err := snapshot.WriteText(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(buf.String(), "craas_registry_garbage_bytes{") {
+		t.Fatal("expected no garbage samples for a failed request")
+	}
+	if !strings.Contains(buf.String(), `craas_scrape_errors_total{operation="garbage_size"} 2`) {
+		t.Fatalf("expected the error counter, but got %s", buf.String())
+	}
+}
+
+func TestWriteTextFile(t *testing.T) {
+	snapshot := &metrics.Snapshot{
+		CollectedAt: testNow,
+		Up:          true,
+		Errors:      map[metrics.Operation]int64{metrics.OperationListRegistries: 1},
+	}
+	dir := t.TempDir()
+	path := filepath.Join(dir, "craas.prom")
+	if err := os.WriteFile(path, []byte("stale"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := snapshot.WriteTextFile(path); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var expected bytes.Buffer
+	if err := snapshot.WriteText(&expected); err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != expected.String() {
+		t.Fatalf("expected %s, but got %s", expected.String(), data)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0o644 {
+		t.Fatalf("expected 0644 file mode, but got %v", info.Mode().Perm())
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("expected no temporary files, but got %d entries", len(entries))
+	}
+}
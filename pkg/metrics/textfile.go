@@ -0,0 +1,23 @@
+package metrics
+
+import (
+	"bytes"
+
+	"github.com/selectel/craas-go/pkg/atomicfile"
+)
+
+// textfileMode is a file mode of written textfiles, node-exporter may run
+// as another user.
+const textfileMode = 0o644
+
+// WriteTextFile atomically writes the snapshot to a file read by the
+// node-exporter textfile collector. The file is written next to the target
+// and renamed, so the collector never reads a partial file.
+func (s *Snapshot) WriteTextFile(path string) error {
+	var buf bytes.Buffer
+	if err := s.WriteText(&buf); err != nil {
+		return err
+	}
+
+	return atomicfile.WriteFile(path, buf.Bytes(), textfileMode)
+}
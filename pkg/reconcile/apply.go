@@ -0,0 +1,100 @@
+package reconcile
+
+import (
+	"context"
+	"fmt"
+
+	clientv1 "github.com/selectel/craas-go/pkg/v1/client"
+	"github.com/selectel/craas-go/pkg/v1/registry"
+	clientv2 "github.com/selectel/craas-go/pkg/v2/client"
+	tokenv2 "github.com/selectel/craas-go/pkg/v2/token"
+)
+
+// Apply performs the changes in order and stops at the first failure.
+// Changes applied before the failure are returned along with the error.
+func (p *Plan) Apply(ctx context.Context, clientV1 *clientv1.ServiceClient, clientV2 *clientv2.ServiceClient) (*Result, error) {
+	result := &Result{CreatedTokens: make(map[string]*tokenv2.TokenV2)}
+	registryIDs := make(map[string]string, len(p.registryIDs))
+	for name, id := range p.registryIDs {
+		registryIDs[name] = id
+	}
+
+	for _, c := range p.Changes {
+		if err := p.applyChange(ctx, clientV1, clientV2, c, registryIDs, result); err != nil {
+			return result, fmt.Errorf("unable to %s %s %s: %w", c.Action, c.Kind, c.Name, err)
+		}
+		result.Applied = append(result.Applied, c)
+	}
+
+	return result, nil
+}
+
+func (p *Plan) applyChange(
+	ctx context.Context,
+	clientV1 *clientv1.ServiceClient,
+	clientV2 *clientv2.ServiceClient,
+	c *Change,
+	registryIDs map[string]string,
+	result *Result,
+) error {
+	switch {
+	case c.Kind == KindRegistry && c.Action == ActionCreate:
+		r, _, err := registry.Create(ctx, clientV1, c.Name)
+		if err != nil {
+			return err
+		}
+		c.ID = r.ID
+		registryIDs[r.Name] = r.ID
+	case c.Kind == KindRegistry && c.Action == ActionDelete:
+		_, err := registry.Delete(ctx, clientV1, c.ID)
+
+		return err
+	case c.Kind == KindToken && c.Action == ActionCreate:
+		scope, exp, err := tokenState(c.token, registryIDs)
+		if err != nil {
+			return err
+		}
+		t, _, err := tokenv2.Create(ctx, clientV2, &tokenv2.TokenV2{Name: c.Name, Scope: scope, Expiration: exp}, nil)
+		if err != nil {
+			return err
+		}
+		c.ID = t.ID
+		result.CreatedTokens[c.Name] = t
+	case c.Kind == KindToken && c.Action == ActionUpdate:
+		scope, exp, err := tokenState(c.token, registryIDs)
+		if err != nil {
+			return err
+		}
+		_, _, err = tokenv2.Patch(ctx, clientV2, c.ID, c.Name, scope, exp)
+
+		return err
+	case c.Kind == KindToken && c.Action == ActionDelete:
+		_, err := tokenv2.Delete(ctx, clientV2, c.ID)
+
+		return err
+	}
+
+	return nil
+}
+
+// tokenState returns a scope and an expiration of the token specification.
+func tokenState(ts *TokenSpec, registryIDs map[string]string) (tokenv2.Scope, tokenv2.Expiration, error) {
+	scope := tokenv2.Scope{
+		ModeRW:        ts.Mode == tokenv2.ScopeModeReadWrite,
+		AllRegistries: ts.AllRegistries,
+	}
+	for _, name := range ts.Registries {
+		id, ok := registryIDs[name]
+		if !ok {
+			return scope, tokenv2.Expiration{}, fmt.Errorf("%w: %s", ErrUnknownRegistry, name)
+		}
+		scope.RegistryIDs = append(scope.RegistryIDs, id)
+	}
+
+	exp := tokenv2.Expiration{}
+	if ts.ExpiresAt != nil {
+		exp = tokenv2.Expiration{IsSet: true, ExpiresAt: ts.ExpiresAt.UTC()}
+	}
+
+	return scope, exp, nil
+}
@@ -0,0 +1,44 @@
+/*
+Package `reconcile` provides a set of functions for keeping registries and
+v2 tokens in sync with a declarative YAML specification.
+
+A specification lists registries and tokens by name:
+
+	registries:
+	  - name: backend
+	    sizeLimit: 10737418240
+	  - name: legacy
+	    absent: true
+	tokens:
+	  - name: ci
+	    mode: rw
+	    registries: [backend]
+	    expiresAt: 2030-01-01T00:00:00Z
+
+NewPlan reads the current state and computes changes, Apply performs them.
+Resources missing from the specification are unmanaged: they are kept unless
+pruning is enabled, and protected names are never deleted.
+
+Example of planning and applying changes:
+
+	spec, err := reconcile.Load("craas.yaml")
+	if err != nil {
+	    log.Fatal(err)
+	}
+	plan, err := reconcile.NewPlan(ctx, clientV1, clientV2, spec, &reconcile.Opts{
+	    PruneTokens: true,
+	    Protected:   []string{"prod-*"},
+	})
+	if err != nil {
+	    log.Fatal(err)
+	}
+	plan.Write(os.Stdout)
+	result, err := plan.Apply(ctx, clientV1, clientV2)
+	if err != nil {
+	    log.Fatal(err)
+	}
+	for name, tkn := range result.CreatedTokens {
+	    fmt.Println(name, tkn.Token)
+	}
+*/
+package reconcile
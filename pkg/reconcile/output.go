@@ -0,0 +1,62 @@
+package reconcile
+
+import (
+	"fmt"
+	"io"
+)
+
+// actionSigns are prefixes of changes in the plan output.
+var actionSigns = map[Action]string{
+	ActionCreate: "+",
+	ActionUpdate: "~",
+	ActionDelete: "-",
+}
+
+// Counts returns numbers of changes by action.
+func (p *Plan) Counts() map[Action]int {
+	counts := map[Action]int{ActionCreate: 0, ActionUpdate: 0, ActionDelete: 0}
+	for _, c := range p.Changes {
+		counts[c.Action]++
+	}
+
+	return counts
+}
+
+// IsEmpty reports whether the plan has no changes.
+func (p *Plan) IsEmpty() bool {
+	return len(p.Changes) == 0
+}
+
+// Write renders the plan in a human-readable form.
+func (p *Plan) Write(w io.Writer) error {
+	for _, c := range p.Changes {
+		line := fmt.Sprintf("%s %s %s", actionSigns[c.Action], c.Kind, c.Name)
+		if c.ID != "" {
+			line += " (" + c.ID + ")"
+		}
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+		for _, d := range c.Details {
+			if _, err := fmt.Fprintf(w, "    %s\n", d); err != nil {
+				return err
+			}
+		}
+	}
+	for _, warning := range p.Warnings {
+		if _, err := fmt.Fprintf(w, "! %s\n", warning); err != nil {
+			return err
+		}
+	}
+	if len(p.Unmanaged) > 0 {
+		if _, err := fmt.Fprintf(w, "%d unmanaged resources are kept, enable pruning to delete them.\n", len(p.Unmanaged)); err != nil {
+			return err
+		}
+	}
+
+	counts := p.Counts()
+	_, err := fmt.Fprintf(w, "Plan: %d to create, %d to update, %d to delete.\n",
+		counts[ActionCreate], counts[ActionUpdate], counts[ActionDelete])
+
+	return err
+}
@@ -0,0 +1,319 @@
+package reconcile
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	clientv1 "github.com/selectel/craas-go/pkg/v1/client"
+	"github.com/selectel/craas-go/pkg/v1/registry"
+	clientv2 "github.com/selectel/craas-go/pkg/v2/client"
+	tokenv2 "github.com/selectel/craas-go/pkg/v2/token"
+)
+
+var (
+	ErrNameEmpty          = errors.New("name is empty")
+	ErrDuplicateName      = errors.New("duplicate name")
+	ErrInvalidMode        = errors.New("invalid token mode")
+	ErrScopeConflict      = errors.New("allRegistries and registries are mutually exclusive")
+	ErrScopeEmpty         = errors.New("token has no registries")
+	ErrUnknownRegistry    = errors.New("token refers to a registry that won't exist")
+	ErrAmbiguousToken     = errors.New("several tokens have the same name")
+	ErrInvalidProtectName = errors.New("invalid protected name pattern")
+)
+
+// Load reads the specification from a YAML file.
+func Load(filename string) (*Spec, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	spec, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse %s: %w", filename, err)
+	}
+
+	return spec, nil
+}
+
+// Parse parses and validates the specification.
+func Parse(data []byte) (*Spec, error) {
+	spec := &Spec{}
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(spec); err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	if err := spec.Validate(); err != nil {
+		return nil, err
+	}
+
+	return spec, nil
+}
+
+// Validate checks names and token scopes of the specification.
+func (s *Spec) Validate() error {
+	registries := make(map[string]struct{}, len(s.Registries))
+	for _, r := range s.Registries {
+		if r.Name == "" {
+			return fmt.Errorf("registry: %w", ErrNameEmpty)
+		}
+		if _, ok := registries[r.Name]; ok {
+			return fmt.Errorf("registry %q: %w", r.Name, ErrDuplicateName)
+		}
+		registries[r.Name] = struct{}{}
+	}
+
+	tokens := make(map[string]struct{}, len(s.Tokens))
+	for _, t := range s.Tokens {
+		if t.Name == "" {
+			return fmt.Errorf("token: %w", ErrNameEmpty)
+		}
+		if _, ok := tokens[t.Name]; ok {
+			return fmt.Errorf("token %q: %w", t.Name, ErrDuplicateName)
+		}
+		tokens[t.Name] = struct{}{}
+		if t.Absent {
+			continue
+		}
+		switch t.Mode {
+		case "", tokenv2.ScopeModeRead, tokenv2.ScopeModeReadWrite:
+		default:
+			return fmt.Errorf("token %q: %w: %q", t.Name, ErrInvalidMode, t.Mode)
+		}
+		if t.AllRegistries && len(t.Registries) > 0 {
+			return fmt.Errorf("token %q: %w", t.Name, ErrScopeConflict)
+		}
+		if !t.AllRegistries && len(t.Registries) == 0 {
+			return fmt.Errorf("token %q: %w", t.Name, ErrScopeEmpty)
+		}
+	}
+
+	return nil
+}
+
+// NewPlan reads the current registries and tokens and computes changes
+// needed to reach the specification.
+func NewPlan(ctx context.Context, clientV1 *clientv1.ServiceClient, clientV2 *clientv2.ServiceClient, spec *Spec, opts *Opts) (*Plan, error) {
+	if err := spec.Validate(); err != nil {
+		return nil, err
+	}
+	o := Opts{}
+	if opts != nil {
+		o = *opts
+	}
+	for _, pattern := range o.Protected {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("%w %q: %v", ErrInvalidProtectName, pattern, err)
+		}
+	}
+
+	registries, _, err := registry.List(ctx, clientV1)
+	if err != nil {
+		return nil, err
+	}
+	tokens, err := tokenv2.ListAll(ctx, clientV2, tokenv2.Opts{})
+	if err != nil {
+		return nil, err
+	}
+
+	return newPlan(spec, registries, tokens, o)
+}
+
+func newPlan(spec *Spec, registries []*registry.Registry, tokens []tokenv2.TokenV2, o Opts) (*Plan, error) {
+	p := &Plan{registryIDs: make(map[string]string, len(registries))}
+	current := make(map[string]*registry.Registry, len(registries))
+	for _, r := range registries {
+		current[r.Name] = r
+		p.registryIDs[r.Name] = r.ID
+	}
+
+	// Registries that will exist after the plan is applied.
+	desired := make(map[string]bool, len(registries)+len(spec.Registries))
+	for name := range current {
+		desired[name] = true
+	}
+	managedRegistries := make(map[string]struct{}, len(spec.Registries))
+	for i := range spec.Registries {
+		rs := spec.Registries[i]
+		managedRegistries[rs.Name] = struct{}{}
+		r, exists := current[rs.Name]
+		switch {
+		case rs.Absent && exists:
+			if p.deletable(o, KindRegistry, r.Name) {
+				p.Changes = append(p.Changes, &Change{Kind: KindRegistry, Action: ActionDelete, Name: r.Name, ID: r.ID})
+				desired[r.Name] = false
+			}
+		case !rs.Absent && !exists:
+			p.Changes = append(p.Changes, &Change{Kind: KindRegistry, Action: ActionCreate, Name: rs.Name})
+			desired[rs.Name] = true
+		case !rs.Absent && exists && rs.SizeLimit != 0 && rs.SizeLimit != r.SizeLimit:
+			p.Warnings = append(p.Warnings, fmt.Sprintf(
+				"registry %s: size limit is %d instead of %d and can't be changed through the API",
+				r.Name, r.SizeLimit, rs.SizeLimit))
+		}
+	}
+	for _, r := range registries {
+		if _, ok := managedRegistries[r.Name]; ok {
+			continue
+		}
+		if !o.PruneRegistries {
+			p.Unmanaged = append(p.Unmanaged, string(KindRegistry)+" "+r.Name)
+
+			continue
+		}
+		if p.deletable(o, KindRegistry, r.Name) {
+			p.Changes = append(p.Changes, &Change{Kind: KindRegistry, Action: ActionDelete, Name: r.Name, ID: r.ID})
+			desired[r.Name] = false
+		}
+	}
+
+	byName := make(map[string][]tokenv2.TokenV2, len(tokens))
+	for _, t := range tokens {
+		if t.Status == tokenv2.StatusRevoked {
+			continue
+		}
+		byName[t.Name] = append(byName[t.Name], t)
+	}
+	managedTokens := make(map[string]struct{}, len(spec.Tokens))
+	for i := range spec.Tokens {
+		ts := &spec.Tokens[i]
+		managedTokens[ts.Name] = struct{}{}
+		existing := byName[ts.Name]
+		if len(existing) > 1 {
+			return nil, fmt.Errorf("token %q: %w", ts.Name, ErrAmbiguousToken)
+		}
+		if ts.Absent {
+			if len(existing) == 1 && p.deletable(o, KindToken, ts.Name) {
+				p.Changes = append(p.Changes, &Change{Kind: KindToken, Action: ActionDelete, Name: ts.Name, ID: existing[0].ID})
+			}
+
+			continue
+		}
+		for _, name := range ts.Registries {
+			if !desired[name] {
+				return nil, fmt.Errorf("token %q: %w: %s", ts.Name, ErrUnknownRegistry, name)
+			}
+		}
+		if len(existing) == 0 {
+			p.Changes = append(p.Changes, &Change{Kind: KindToken, Action: ActionCreate, Name: ts.Name, token: ts})
+
+			continue
+		}
+		if details := p.tokenDiff(&existing[0], ts); len(details) > 0 {
+			p.Changes = append(p.Changes, &Change{
+				Kind: KindToken, Action: ActionUpdate, Name: ts.Name, ID: existing[0].ID, Details: details, token: ts,
+			})
+		}
+	}
+	for _, t := range tokens {
+		if _, ok := managedTokens[t.Name]; ok || t.Status == tokenv2.StatusRevoked {
+			continue
+		}
+		if !o.PruneTokens {
+			p.Unmanaged = append(p.Unmanaged, string(KindToken)+" "+t.Name)
+
+			continue
+		}
+		if p.deletable(o, KindToken, t.Name) {
+			p.Changes = append(p.Changes, &Change{Kind: KindToken, Action: ActionDelete, Name: t.Name, ID: t.ID})
+		}
+	}
+
+	sort.SliceStable(p.Changes, func(i, j int) bool {
+		return changeOrder(p.Changes[i]) < changeOrder(p.Changes[j])
+	})
+
+	return p, nil
+}
+
+// changeOrder returns a position of the change in the plan: registries are
+// created before tokens refer to them and deleted after tokens are updated.
+func changeOrder(c *Change) int {
+	switch {
+	case c.Kind == KindRegistry && c.Action != ActionDelete:
+		return 0
+	case c.Kind == KindToken && c.Action != ActionDelete:
+		return 1
+	case c.Kind == KindToken:
+		return 2
+	default:
+		return 3
+	}
+}
+
+// deletable reports whether the resource isn't protected and records a warning otherwise.
+func (p *Plan) deletable(o Opts, kind Kind, name string) bool {
+	for _, pattern := range o.Protected {
+		if ok, _ := path.Match(pattern, name); ok {
+			p.Warnings = append(p.Warnings, fmt.Sprintf("%s %s is protected and won't be deleted", kind, name))
+
+			return false
+		}
+	}
+
+	return true
+}
+
+// tokenDiff describes differences between the token and the specification.
+func (p *Plan) tokenDiff(t *tokenv2.TokenV2, ts *TokenSpec) []string {
+	var details []string
+
+	currentMode := modeName(t.Scope.ModeRW)
+	if desiredMode := modeName(ts.Mode == tokenv2.ScopeModeReadWrite); currentMode != desiredMode {
+		details = append(details, fmt.Sprintf("mode: %s -> %s", currentMode, desiredMode))
+	}
+	if t.Scope.AllRegistries != ts.AllRegistries {
+		details = append(details, fmt.Sprintf("allRegistries: %t -> %t", t.Scope.AllRegistries, ts.AllRegistries))
+	}
+
+	names := make(map[string]string, len(p.registryIDs))
+	for name, id := range p.registryIDs {
+		names[id] = name
+	}
+	currentRegistries := make([]string, 0, len(t.Scope.RegistryIDs))
+	for _, id := range t.Scope.RegistryIDs {
+		if name, ok := names[id]; ok {
+			currentRegistries = append(currentRegistries, name)
+		} else {
+			currentRegistries = append(currentRegistries, id)
+		}
+	}
+	desiredRegistries := append([]string(nil), ts.Registries...)
+	sort.Strings(currentRegistries)
+	sort.Strings(desiredRegistries)
+	if strings.Join(currentRegistries, ",") != strings.Join(desiredRegistries, ",") {
+		details = append(details, fmt.Sprintf("registries: [%s] -> [%s]",
+			strings.Join(currentRegistries, ", "), strings.Join(desiredRegistries, ", ")))
+	}
+
+	currentExp, desiredExp := "never", "never"
+	if t.Expiration.IsSet {
+		currentExp = t.Expiration.ExpiresAt.UTC().Format(time.RFC3339)
+	}
+	if ts.ExpiresAt != nil {
+		desiredExp = ts.ExpiresAt.UTC().Format(time.RFC3339)
+	}
+	if currentExp != desiredExp {
+		details = append(details, fmt.Sprintf("expiresAt: %s -> %s", currentExp, desiredExp))
+	}
+
+	return details
+}
+
+func modeName(rw bool) string {
+	if rw {
+		return tokenv2.ScopeModeReadWrite
+	}
+
+	return tokenv2.ScopeModeRead
+}
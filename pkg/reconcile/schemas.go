@@ -0,0 +1,118 @@
+package reconcile
+
+import (
+	"time"
+
+	tokenv2 "github.com/selectel/craas-go/pkg/v2/token"
+)
+
+// Spec represents the desired state.
+type Spec struct {
+	// Registries lists managed registries.
+	Registries []RegistrySpec `yaml:"registries" json:"registries"`
+
+	// Tokens lists managed v2 tokens.
+	Tokens []TokenSpec `yaml:"tokens" json:"tokens"`
+}
+
+// RegistrySpec represents a desired registry.
+type RegistrySpec struct {
+	// Name is a name of the registry.
+	Name string `yaml:"name" json:"name"`
+
+	// SizeLimit is an expected size limit in bytes, it's only checked
+	// because the API doesn't allow changing it.
+	SizeLimit int64 `yaml:"sizeLimit" json:"sizeLimit,omitempty"`
+
+	// Absent marks the registry to be deleted.
+	Absent bool `yaml:"absent" json:"absent,omitempty"`
+}
+
+// TokenSpec represents a desired v2 token.
+type TokenSpec struct {
+	// Name is a name of the token.
+	Name string `yaml:"name" json:"name"`
+
+	// Mode is an access mode, read-only by default.
+	Mode string `yaml:"mode" json:"mode,omitempty"`
+
+	// AllRegistries grants access to all registries.
+	AllRegistries bool `yaml:"allRegistries" json:"allRegistries,omitempty"`
+
+	// Registries are names of registries the token has access to.
+	Registries []string `yaml:"registries" json:"registries,omitempty"`
+
+	// ExpiresAt is an expiration time, the token never expires if it's not set.
+	ExpiresAt *time.Time `yaml:"expiresAt" json:"expiresAt,omitempty"`
+
+	// Absent marks the token to be deleted.
+	Absent bool `yaml:"absent" json:"absent,omitempty"`
+}
+
+// Kind represents a kind of resources.
+type Kind string
+
+const (
+	KindRegistry Kind = "registry"
+	KindToken    Kind = "token"
+)
+
+// Action represents a change of a resource.
+type Action string
+
+const (
+	ActionCreate Action = "create"
+	ActionUpdate Action = "update"
+	ActionDelete Action = "delete"
+)
+
+// Opts represents options of the planning.
+type Opts struct {
+	// PruneRegistries deletes registries missing from the specification.
+	PruneRegistries bool
+
+	// PruneTokens deletes tokens missing from the specification.
+	PruneTokens bool
+
+	// Protected is a list of path.Match patterns of resource names that are never deleted.
+	Protected []string
+}
+
+// Change represents a planned change of a resource.
+type Change struct {
+	Kind   Kind   `json:"kind"`
+	Action Action `json:"action"`
+	Name   string `json:"name"`
+
+	// ID is empty for resources that don't exist yet.
+	ID string `json:"id,omitempty"`
+
+	// Details describe updated fields, for example "mode: r -> rw".
+	Details []string `json:"details,omitempty"`
+
+	token *TokenSpec
+}
+
+// Plan represents changes needed to reach the desired state.
+type Plan struct {
+	Changes []*Change `json:"changes"`
+
+	// Warnings describe differences that can't be reconciled and skipped deletions.
+	Warnings []string `json:"warnings,omitempty"`
+
+	// Unmanaged are names of resources kept because pruning is disabled.
+	Unmanaged []string `json:"unmanaged,omitempty"`
+
+	// registryIDs maps names of existing registries to their IDs.
+	registryIDs map[string]string
+}
+
+// Result represents applied changes.
+type Result struct {
+	// Applied are changes applied before an error, if any.
+	Applied []*Change
+
+	// CreatedTokens maps names of created tokens to them, it's the only
+	// time the token values are available.
+	CreatedTokens map[string]*tokenv2.TokenV2
+}
@@ -0,0 +1,76 @@
+package testing
+
+const testSpec = `
+registries:
+  - name: backend
+    sizeLimit: 2048
+  - name: frontend
+  - name: legacy
+    absent: true
+tokens:
+  - name: ci
+    mode: rw
+    registries: [backend, frontend]
+    expiresAt: 2030-01-01T00:00:00Z
+  - name: old
+    absent: true
+  - name: reader
+    allRegistries: true
+`
+
+const testListRegistriesResponse = `
+[
+    {"id": "r-backend", "name": "backend", "size": 0, "sizeLimit": 1024, "status": "ACTIVE"},
+    {"id": "r-legacy", "name": "legacy", "size": 0, "sizeLimit": 1024, "status": "ACTIVE"},
+    {"id": "r-other", "name": "other", "size": 0, "sizeLimit": 1024, "status": "ACTIVE"}
+]
+`
+
+const testListTokensResponse = `
+{
+    "tokens": [
+        {"id": "t-ci", "name": "ci", "status": "active", "expiration": {"isSet": false}, "scope": {"modeRW": false, "allRegistries": false, "registryIds": ["r-backend"]}},
+        {"id": "t-old", "name": "old", "status": "active", "expiration": {"isSet": false}, "scope": {"modeRW": false, "allRegistries": true}},
+        {"id": "t-stray", "name": "stray", "status": "active", "expiration": {"isSet": false}, "scope": {"modeRW": false, "allRegistries": true}},
+        {"id": "t-prod", "name": "prod-deploy", "status": "active", "expiration": {"isSet": false}, "scope": {"modeRW": true, "allRegistries": true}},
+        {"id": "t-revoked", "name": "revoked", "status": "revoked", "expiration": {"isSet": false}, "scope": {"modeRW": false, "allRegistries": true}}
+    ],
+    "totalCount": 5
+}
+`
+
+const testCreateRegistryResponse = `
+{"id": "r-frontend", "name": "frontend", "size": 0, "sizeLimit": 1024, "status": "CREATING"}
+`
+
+const testCreateTokenResponse = `
+{"id": "t-reader", "name": "reader", "token": "secret-value", "status": "active", "expiration": {"isSet": false}, "scope": {"modeRW": false, "allRegistries": true}}
+`
+
+const testPatchTokenResponse = `
+{"id": "t-ci", "name": "ci", "status": "active", "expiration": {"isSet": true, "expiresAt": "2030-01-01T00:00:00Z"}, "scope": {"modeRW": true, "allRegistries": false, "registryIds": ["r-backend", "r-frontend"]}}
+`
+
+const expectedPlan = `+ registry frontend
+~ token ci (t-ci)
+    mode: r -> rw
+    registries: [backend] -> [backend, frontend]
+    expiresAt: never -> 2030-01-01T00:00:00Z
++ token reader
+- token old (t-old)
+- token stray (t-stray)
+- registry legacy (r-legacy)
+! registry backend: size limit is 1024 instead of 2048 and can't be changed through the API
+! token prod-deploy is protected and won't be deleted
+1 unmanaged resources are kept, enable pruning to delete them.
+Plan: 2 to create, 1 to update, 3 to delete.
+`
+
+var expectedCalls = []string{
+	"POST /api/v1/registries",
+	"PATCH /api/v2/tokens/t-ci",
+	"POST /api/v2/tokens",
+	"DELETE /api/v2/tokens/t-old",
+	"DELETE /api/v2/tokens/t-stray",
+	"DELETE /api/v1/registries/r-legacy",
+}
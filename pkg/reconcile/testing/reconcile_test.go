@@ -0,0 +1,180 @@
+package testing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/selectel/craas-go/pkg/reconcile"
+	"github.com/selectel/craas-go/pkg/testutils"
+	clientv1 "github.com/selectel/craas-go/pkg/v1/client"
+	clientv2 "github.com/selectel/craas-go/pkg/v2/client"
+)
+
+// fakeAPI records mutating requests and serves fixtures.
+type fakeAPI struct {
+	t *testing.T
+
+	mu        sync.Mutex
+	calls     []string
+	patchBody map[string]interface{}
+}
+
+func (f *fakeAPI) record(r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.calls = append(f.calls, r.Method+" "+r.URL.Path)
+}
+
+func (f *fakeAPI) register(mux *http.ServeMux) {
+	respond := func(w http.ResponseWriter, status int, body string) {
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(status)
+		fmt.Fprint(w, body)
+	}
+	mux.HandleFunc("/api/v1/registries", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			respond(w, http.StatusOK, testListRegistriesResponse)
+
+			return
+		}
+		f.record(r)
+		respond(w, http.StatusCreated, testCreateRegistryResponse)
+	})
+	mux.HandleFunc("/api/v1/registries/", func(w http.ResponseWriter, r *http.Request) {
+		f.record(r)
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("/api/v2/tokens", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			respond(w, http.StatusOK, testListTokensResponse)
+
+			return
+		}
+		f.record(r)
+		respond(w, http.StatusCreated, testCreateTokenResponse)
+	})
+	mux.HandleFunc("/api/v2/tokens/", func(w http.ResponseWriter, r *http.Request) {
+		f.record(r)
+		if r.Method == http.MethodPatch {
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				f.t.Errorf("unable to read the request body: %v", err)
+			}
+			f.mu.Lock()
+			if err := json.Unmarshal(body, &f.patchBody); err != nil {
+				f.t.Errorf("unable to decode the request body: %v", err)
+			}
+			f.mu.Unlock()
+			respond(w, http.StatusOK, testPatchTokenResponse)
+
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+func newTestClients(t *testing.T, serverURL string) (*clientv1.ServiceClient, *clientv2.ServiceClient) {
+	t.Helper()
+
+	c1, err := clientv1.NewCRaaSClientV1(testutils.TokenID, serverURL+"/api/v1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c2, err := clientv2.NewCRaaSClientV2(testutils.TokenID, serverURL+"/api/v2")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return c1, c2
+}
+
+func TestPlanAndApply(t *testing.T) {
+	testEnv := testutils.SetupTestEnv()
+	defer testEnv.TearDownTestEnv()
+	api := &fakeAPI{t: t}
+	api.register(testEnv.Mux)
+	c1, c2 := newTestClients(t, testEnv.Server.URL)
+
+	spec, err := reconcile.Parse([]byte(testSpec))
+	if err != nil {
+		t.Fatal(err)
+	}
+	plan, err := reconcile.NewPlan(context.Background(), c1, c2, spec, &reconcile.Opts{
+		PruneTokens: true,
+		Protected:   []string{"prod-*"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := plan.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != expectedPlan {
+		t.Fatalf("expected %s, but got %s", expectedPlan, buf.String())
+	}
+
+	result, err := plan.Apply(context.Background(), c1, c2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(expectedCalls, api.calls) {
+		t.Fatalf("expected %#v, but got %#v", expectedCalls, api.calls)
+	}
+	if len(result.Applied) != len(expectedCalls) {
+		t.Fatalf("expected %d applied changes, but got %d", len(expectedCalls), len(result.Applied))
+	}
+	if tkn := result.CreatedTokens["reader"]; tkn == nil || tkn.Token != "secret-value" {
+		t.Fatalf("expected the created token value, but got %#v", tkn)
+	}
+
+	scope, _ := api.patchBody["scope"].(map[string]interface{})
+	expectedIDs := []interface{}{"r-backend", "r-frontend"}
+	if !reflect.DeepEqual(expectedIDs, scope["registryIds"]) {
+		t.Fatalf("expected %#v, but got %#v", expectedIDs, scope["registryIds"])
+	}
+}
+
+func TestParseValidation(t *testing.T) {
+	testCases := map[string]error{
+		"tokens:\n  - name: a\n    mode: rx\n    allRegistries: true\n": reconcile.ErrInvalidMode,
+		"tokens:\n  - name: a\n": reconcile.ErrScopeEmpty,
+		"tokens:\n  - name: a\n    allRegistries: true\n    registries: [b]\n": reconcile.ErrScopeConflict,
+		"registries:\n  - name: a\n  - name: a\n":                              reconcile.ErrDuplicateName,
+	}
+	for spec, expected := range testCases {
+		if _, err := reconcile.Parse([]byte(spec)); !errors.Is(err, expected) {
+			t.Fatalf("expected %v, but got %v", expected, err)
+		}
+	}
+	if _, err := reconcile.Parse([]byte("registries:\n  - name: a\n    size: 1\n")); err == nil {
+		t.Fatal("expected an error for an unknown field")
+	}
+}
+
+func TestPlanUnknownRegistry(t *testing.T) {
+	testEnv := testutils.SetupTestEnv()
+	defer testEnv.TearDownTestEnv()
+	api := &fakeAPI{t: t}
+	api.register(testEnv.Mux)
+	c1, c2 := newTestClients(t, testEnv.Server.URL)
+
+	spec, err := reconcile.Parse([]byte("tokens:\n  - name: ci\n    registries: [missing]\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = reconcile.NewPlan(context.Background(), c1, c2, spec, nil)
+	if !errors.Is(err, reconcile.ErrUnknownRegistry) {
+		t.Fatalf("expected %v, but got %v", reconcile.ErrUnknownRegistry, err)
+	}
+}
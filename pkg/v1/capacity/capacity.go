@@ -0,0 +1,126 @@
+package capacity
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/selectel/craas-go/pkg/format"
+	"github.com/selectel/craas-go/pkg/v1/client"
+	"github.com/selectel/craas-go/pkg/v1/registry"
+	"github.com/selectel/craas-go/pkg/v1/repository"
+)
+
+var ErrInsufficientSpace = errors.New("insufficient registry space")
+
+// Opts represents options of the capacity check.
+type Opts struct {
+	// Margin is a space in bytes that must stay free after the push.
+	Margin int64
+}
+
+// Check compares new layers of the image with the free space of
+// the registry. If the image doesn't fit, the result is returned with an
+// error wrapping ErrInsufficientSpace. A missing repository has no layers.
+func Check(
+	ctx context.Context,
+	client *client.ServiceClient,
+	registryID, repositoryName string,
+	image *Image,
+	opts *Opts,
+) (*Result, error) {
+	if repositoryName == "" {
+		return nil, repository.ErrRepositoryNameEmpty
+	}
+	r, _, err := registry.Get(ctx, client, registryID)
+	if err != nil {
+		return nil, err
+	}
+	existing, err := repositoryLayers(ctx, client, registryID, repositoryName)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{
+		RegistryID:     r.ID,
+		Registry:       r.Name,
+		Repository:     repositoryName,
+		ImageSize:      image.Size(),
+		Free:           r.SizeLimit - r.Size,
+		NewLayers:      make([]Layer, 0),
+		ExistingLayers: make([]Layer, 0),
+	}
+	if opts != nil {
+		result.Margin = opts.Margin
+	}
+
+	seen := make(map[string]struct{})
+	for _, layer := range image.Layers {
+		if layer.Digest != "" {
+			if _, ok := seen[layer.Digest]; ok {
+				continue
+			}
+			seen[layer.Digest] = struct{}{}
+		}
+		if _, ok := existing[layer.Digest]; ok && layer.Digest != "" {
+			result.ExistingLayers = append(result.ExistingLayers, layer)
+			result.ExistingSize += layer.Size
+
+			continue
+		}
+		result.NewLayers = append(result.NewLayers, layer)
+		result.UploadSize += layer.Size
+	}
+
+	result.Fits = r.SizeLimit <= 0 || result.UploadSize+result.Margin <= result.Free
+	if !result.Fits {
+		return result, fmt.Errorf("%w: pushing %s to %s/%s needs %s but only %s is free",
+			ErrInsufficientSpace, format.HumanSize(result.ImageSize), r.Name, repositoryName,
+			needed(result), format.HumanSize(result.Free))
+	}
+
+	return result, nil
+}
+
+func needed(result *Result) string {
+	text := format.HumanSize(result.UploadSize)
+	if result.Margin > 0 {
+		text += " plus " + format.HumanSize(result.Margin) + " margin"
+	}
+
+	return text
+}
+
+// repositoryLayers returns digests of layers of all images in the repository.
+// Layers are requested separately for images listed without them.
+func repositoryLayers(ctx context.Context, client *client.ServiceClient, registryID, repositoryName string) (map[string]struct{}, error) {
+	layers := make(map[string]struct{})
+
+	images, responseResult, err := repository.ListImages(ctx, client, registryID, repositoryName)
+	if err != nil {
+		if responseResult != nil && responseResult.Response != nil && responseResult.StatusCode == http.StatusNotFound {
+			return layers, nil
+		}
+
+		return nil, err
+	}
+	for _, image := range images {
+		if len(image.Layers) > 0 {
+			for _, layer := range image.Layers {
+				layers[layer.Digest] = struct{}{}
+			}
+
+			continue
+		}
+		imageLayers, _, err := repository.ListImageLayers(ctx, client, registryID, repositoryName, image.Digest)
+		if err != nil {
+			return nil, err
+		}
+		for _, layer := range imageLayers {
+			layers[layer.Digest] = struct{}{}
+		}
+	}
+
+	return layers, nil
+}
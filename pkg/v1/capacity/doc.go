@@ -0,0 +1,30 @@
+/*
+Package `capacity` provides a set of functions for checking whether a local
+image fits into a registry before pushing it.
+
+Layers already present in the target repository are not uploaded again,
+so only the size of new layers is compared with the free space of
+the registry.
+
+Example of checking an image saved with "docker save":
+
+	image, err := capacity.Load("app.tar")
+	if err != nil {
+	    log.Fatal(err)
+	}
+	result, err := capacity.Check(ctx, client, registryID, "app", image, &capacity.Opts{
+	    Margin: 100 << 20,
+	})
+	if err != nil {
+	    log.Fatal(err)
+	}
+	fmt.Printf("%d bytes to upload, %d bytes free\n", result.UploadSize, result.Free)
+
+Example of checking an image of a known size:
+
+	_, err := capacity.Check(ctx, client, registryID, "app", capacity.ImageOfSize(2<<30), nil)
+	if errors.Is(err, capacity.ErrInsufficientSpace) {
+	    log.Fatal(err)
+	}
+*/
+package capacity
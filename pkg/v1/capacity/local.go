@@ -0,0 +1,228 @@
+package capacity
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// ociIndexFile is a name of the OCI image layout index.
+	ociIndexFile = "index.json"
+
+	// dockerManifestFile is a name of the "docker save" manifest.
+	dockerManifestFile = "manifest.json"
+
+	// maxMetadataSize limits blobs of a tarball kept in memory as possible manifests.
+	maxMetadataSize = 4 << 20
+)
+
+var (
+	ErrUnknownImageFormat = errors.New("neither an OCI layout nor a docker save tarball")
+	ErrInvalidDigest      = errors.New("invalid blob digest")
+)
+
+// descriptor represents an OCI content descriptor.
+type descriptor struct {
+	MediaType string `json:"mediaType"`
+	Digest    string `json:"digest"`
+	Size      int64  `json:"size"`
+}
+
+// manifest represents an OCI image index or manifest.
+type manifest struct {
+	Manifests []descriptor `json:"manifests"`
+	Config    *descriptor  `json:"config"`
+	Layers    []descriptor `json:"layers"`
+}
+
+// dockerManifest represents an entry of the "docker save" manifest.
+type dockerManifest struct {
+	Config string   `json:"Config"`
+	Layers []string `json:"Layers"`
+}
+
+// Load reads an OCI layout directory or a "docker save" tarball,
+// which may be compressed with gzip.
+func Load(filename string) (*Image, error) {
+	info, err := os.Stat(filename)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return LoadOCILayout(filename)
+	}
+
+	return LoadTarball(filename)
+}
+
+// LoadOCILayout reads blobs of all images of an OCI layout directory.
+func LoadOCILayout(dir string) (*Image, error) {
+	index, err := os.ReadFile(filepath.Join(dir, ociIndexFile))
+	if err != nil {
+		return nil, err
+	}
+
+	return fromOCIIndex(index, func(digest string) ([]byte, error) {
+		name, err := blobPath(digest)
+		if err != nil {
+			return nil, err
+		}
+
+		return os.ReadFile(filepath.Join(dir, filepath.FromSlash(name)))
+	})
+}
+
+// LoadTarball reads a "docker save" tarball. Tarballs with an OCI layout are
+// read by blob digests. Layers of legacy tarballs have no digests
+// comparable with the registry, so they are always counted as new.
+func LoadTarball(filename string) (*Image, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	reader := bufio.NewReader(f)
+	var r io.Reader = reader
+	if magic, _ := reader.Peek(2); bytes.Equal(magic, []byte{0x1f, 0x8b}) {
+		gz, err := gzip.NewReader(reader)
+		if err != nil {
+			return nil, err
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	sizes := make(map[string]int64)
+	files := make(map[string][]byte)
+	archive := tar.NewReader(r)
+	for {
+		header, err := archive.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("unable to read %s: %w", filename, err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		name := path.Clean(strings.TrimPrefix(header.Name, "./"))
+		sizes[name] = header.Size
+		if header.Size > maxMetadataSize {
+			continue
+		}
+		data, err := io.ReadAll(archive)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read %s: %w", filename, err)
+		}
+		files[name] = data
+	}
+
+	if index, ok := files[ociIndexFile]; ok {
+		return fromOCIIndex(index, func(digest string) ([]byte, error) {
+			name, err := blobPath(digest)
+			if err != nil {
+				return nil, err
+			}
+			data, ok := files[name]
+			if !ok {
+				return nil, fmt.Errorf("blob %s not found in %s", digest, filename)
+			}
+
+			return data, nil
+		})
+	}
+	if data, ok := files[dockerManifestFile]; ok {
+		return fromDockerManifest(data, sizes)
+	}
+
+	return nil, fmt.Errorf("%s: %w", filename, ErrUnknownImageFormat)
+}
+
+// fromOCIIndex collects config and layer blobs of all manifests reachable
+// from the index, nested indexes of multi-platform images are followed.
+func fromOCIIndex(index []byte, readBlob func(digest string) ([]byte, error)) (*Image, error) {
+	image := &Image{}
+	seen := make(map[string]struct{})
+	add := func(d descriptor) {
+		if _, ok := seen[d.Digest]; ok {
+			return
+		}
+		seen[d.Digest] = struct{}{}
+		image.Layers = append(image.Layers, Layer{Digest: d.Digest, Size: d.Size})
+	}
+
+	var walk func(data []byte) error
+	walk = func(data []byte) error {
+		m := &manifest{}
+		if err := json.Unmarshal(data, m); err != nil {
+			return err
+		}
+		for _, d := range m.Manifests {
+			blob, err := readBlob(d.Digest)
+			if err != nil {
+				return err
+			}
+			if err := walk(blob); err != nil {
+				return fmt.Errorf("manifest %s: %w", d.Digest, err)
+			}
+		}
+		if m.Config != nil {
+			add(*m.Config)
+		}
+		for _, d := range m.Layers {
+			add(d)
+		}
+
+		return nil
+	}
+	if err := walk(index); err != nil {
+		return nil, err
+	}
+
+	return image, nil
+}
+
+// fromDockerManifest collects layers of a legacy "docker save" tarball.
+func fromDockerManifest(data []byte, sizes map[string]int64) (*Image, error) {
+	var manifests []dockerManifest
+	if err := json.Unmarshal(data, &manifests); err != nil {
+		return nil, err
+	}
+
+	image := &Image{}
+	seen := make(map[string]struct{})
+	for _, m := range manifests {
+		for _, name := range append([]string{m.Config}, m.Layers...) {
+			name = path.Clean(name)
+			if _, ok := seen[name]; ok || name == "." {
+				continue
+			}
+			seen[name] = struct{}{}
+			image.Layers = append(image.Layers, Layer{Size: sizes[name]})
+		}
+	}
+
+	return image, nil
+}
+
+// blobPath returns a path of the blob in an OCI layout.
+func blobPath(digest string) (string, error) {
+	algorithm, hex, ok := strings.Cut(digest, ":")
+	if !ok || algorithm == "" || hex == "" || strings.ContainsAny(digest, "/\\.") {
+		return "", fmt.Errorf("%w: %q", ErrInvalidDigest, digest)
+	}
+
+	return path.Join("blobs", algorithm, hex), nil
+}
@@ -0,0 +1,59 @@
+package capacity
+
+// Layer represents a blob of a local image.
+type Layer struct {
+	// Digest is empty if the blob digest is unknown, such layers are
+	// always counted as new.
+	Digest string `json:"digest"`
+
+	// Size is a blob size in bytes.
+	Size int64 `json:"size"`
+}
+
+// Image represents blobs of a local image.
+type Image struct {
+	Layers []Layer `json:"layers"`
+}
+
+// Size returns a sum of layer sizes.
+func (i *Image) Size() int64 {
+	var size int64
+	for _, layer := range i.Layers {
+		size += layer.Size
+	}
+
+	return size
+}
+
+// ImageOfSize returns an image with a single layer of an unknown digest.
+func ImageOfSize(size int64) *Image {
+	return &Image{Layers: []Layer{{Size: size}}}
+}
+
+// Result represents a result of the capacity check.
+type Result struct {
+	RegistryID string `json:"registryId"`
+	Registry   string `json:"registry"`
+	Repository string `json:"repository"`
+
+	// ImageSize is a size of all image layers.
+	ImageSize int64 `json:"imageSize"`
+
+	// ExistingSize is a size of layers already present in the repository.
+	ExistingSize int64 `json:"existingSize"`
+
+	// UploadSize is a size of layers to push.
+	UploadSize int64 `json:"uploadSize"`
+
+	// Free is the registry size limit minus its size.
+	Free int64 `json:"free"`
+
+	// Margin is a space kept free after the push.
+	Margin int64 `json:"margin"`
+
+	// Fits reports whether the upload fits into the free space minus margin.
+	Fits bool `json:"fits"`
+
+	NewLayers      []Layer `json:"newLayers"`
+	ExistingLayers []Layer `json:"existingLayers"`
+}
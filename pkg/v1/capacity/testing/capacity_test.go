@@ -0,0 +1,152 @@
+package testing
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/selectel/craas-go/pkg/testutils"
+	"github.com/selectel/craas-go/pkg/v1/capacity"
+	"github.com/selectel/craas-go/pkg/v1/client"
+)
+
+// writeTestLayout writes an OCI layout with the test blobs and returns its files.
+func writeTestLayout(t *testing.T, dir string) map[string][]byte {
+	t.Helper()
+
+	files := map[string][]byte{
+		"oci-layout": []byte(`{"imageLayoutVersion": "1.0.0"}`),
+		"index.json": []byte(testIndexRaw),
+		"blobs/sha256/" + strings.TrimPrefix(testManifestDigest, "sha256:"): []byte(testManifestRaw),
+	}
+	for digest, size := range testBlobs {
+		files["blobs/sha256/"+strings.TrimPrefix(digest, "sha256:")] = make([]byte, size)
+	}
+	for name, data := range files {
+		filename := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(filename), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filename, data, 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return files
+}
+
+func TestLoadOCILayout(t *testing.T) {
+	dir := t.TempDir()
+	writeTestLayout(t, dir)
+
+	actual, err := capacity.Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(expectedImage, actual) {
+		t.Fatalf("expected %#v, but got %#v", expectedImage, actual)
+	}
+}
+
+func TestLoadGzipTarball(t *testing.T) {
+	files := writeTestLayout(t, t.TempDir())
+
+	filename := filepath.Join(t.TempDir(), "image.tar.gz")
+	f, err := os.Create(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gz := gzip.NewWriter(f)
+	archive := tar.NewWriter(gz)
+	for name, data := range files {
+		if err := archive.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: int64(len(data))}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := archive.Write(data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, closer := range []interface{ Close() error }{archive, gz, f} {
+		if err := closer.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	actual, err := capacity.Load(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(expectedImage, actual) {
+		t.Fatalf("expected %#v, but got %#v", expectedImage, actual)
+	}
+}
+
+func newTestClient(t *testing.T, testEnv *testutils.TestEnv, imagesResponse string, imagesStatus int) *client.ServiceClient {
+	t.Helper()
+
+	registryCalled, imagesCalled := false, false
+	testutils.HandleReqWithoutBody(t, &testutils.HandleReqOpts{
+		Mux:         testEnv.Mux,
+		URL:         "/api/v1/registries/" + testRegistryID,
+		RawResponse: testGetRegistryResponseRaw,
+		Method:      http.MethodGet,
+		Status:      http.StatusOK,
+		CallFlag:    &registryCalled,
+	})
+	testutils.HandleReqWithoutBody(t, &testutils.HandleReqOpts{
+		Mux:         testEnv.Mux,
+		URL:         "/api/v1/registries/" + testRegistryID + "/repositories/app/images",
+		RawResponse: imagesResponse,
+		Method:      http.MethodGet,
+		Status:      imagesStatus,
+		CallFlag:    &imagesCalled,
+	})
+
+	testClient, err := client.NewCRaaSClientV1(testutils.TokenID, testEnv.Server.URL+"/api/v1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return testClient
+}
+
+func TestCheckSkipsExistingLayers(t *testing.T) {
+	testEnv := testutils.SetupTestEnv()
+	defer testEnv.TearDownTestEnv()
+	testClient := newTestClient(t, testEnv, testListImagesResponseRaw, http.StatusOK)
+
+	result, err := capacity.Check(context.Background(), testClient, testRegistryID, "app", expectedImage, &capacity.Opts{
+		Margin: 200,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Fits || result.UploadSize != 700 || result.ExistingSize != 3000 || result.Free != 1000 {
+		t.Fatalf("unexpected result %#v", result)
+	}
+}
+
+func TestCheckInsufficientSpace(t *testing.T) {
+	testEnv := testutils.SetupTestEnv()
+	defer testEnv.TearDownTestEnv()
+	testClient := newTestClient(t, testEnv, testNotFoundResponseRaw, http.StatusNotFound)
+
+	result, err := capacity.Check(context.Background(), testClient, testRegistryID, "app", expectedImage, nil)
+	if !errors.Is(err, capacity.ErrInsufficientSpace) {
+		t.Fatalf("expected %v, but got %v", capacity.ErrInsufficientSpace, err)
+	}
+	expected := "insufficient registry space: pushing 3.6 KiB to test-registry/app needs 3.6 KiB but only 1000 B is free"
+	if err.Error() != expected {
+		t.Fatalf("expected %q, but got %q", expected, err.Error())
+	}
+	if result == nil || result.Fits || len(result.ExistingLayers) != 0 {
+		t.Fatalf("unexpected result %#v", result)
+	}
+}
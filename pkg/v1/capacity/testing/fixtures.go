@@ -0,0 +1,85 @@
+package testing
+
+import (
+	"github.com/selectel/craas-go/pkg/v1/capacity"
+)
+
+const testRegistryID = "fc43e322-b084-4b3c-a04a-1ab2a28cd860"
+
+const testGetRegistryResponseRaw = `{
+    "id": "fc43e322-b084-4b3c-a04a-1ab2a28cd860",
+    "name": "test-registry",
+    "createdAt": "2022-10-25T10:25:22.556Z",
+    "status": "ACTIVE",
+    "size": 9000,
+    "sizeLimit": 10000,
+    "used": 90
+}`
+
+const testListImagesResponseRaw = `[
+    {
+        "createdAt": "2022-05-17T22:37:17.011072851Z",
+        "digest": "sha256:a76df3b4f1478766631c794de7ff466aca466f995fd5bb216bb9643a3dd2a6bb",
+        "layers": [
+            {
+                "digest": "sha256:1111111111111111111111111111111111111111111111111111111111111111",
+                "size": 3000
+            }
+        ],
+        "size": 3000,
+        "tags": ["latest"]
+    }
+]`
+
+const testNotFoundResponseRaw = `{"error": {"message": "Repository not found"}}`
+
+// testBlobs are blobs of the OCI layout: a base layer present in
+// the registry, a new layer and a config.
+var testBlobs = map[string]int64{
+	"sha256:1111111111111111111111111111111111111111111111111111111111111111": 3000,
+	"sha256:2222222222222222222222222222222222222222222222222222222222222222": 600,
+	"sha256:3333333333333333333333333333333333333333333333333333333333333333": 100,
+}
+
+const testManifestDigest = "sha256:4444444444444444444444444444444444444444444444444444444444444444"
+
+const testManifestRaw = `{
+    "schemaVersion": 2,
+    "mediaType": "application/vnd.oci.image.manifest.v1+json",
+    "config": {
+        "mediaType": "application/vnd.oci.image.config.v1+json",
+        "digest": "sha256:3333333333333333333333333333333333333333333333333333333333333333",
+        "size": 100
+    },
+    "layers": [
+        {
+            "mediaType": "application/vnd.oci.image.layer.v1.tar+gzip",
+            "digest": "sha256:1111111111111111111111111111111111111111111111111111111111111111",
+            "size": 3000
+        },
+        {
+            "mediaType": "application/vnd.oci.image.layer.v1.tar+gzip",
+            "digest": "sha256:2222222222222222222222222222222222222222222222222222222222222222",
+            "size": 600
+        }
+    ]
+}`
+
+const testIndexRaw = `{
+    "schemaVersion": 2,
+    "manifests": [
+        {
+            "mediaType": "application/vnd.oci.image.manifest.v1+json",
+            "digest": "sha256:4444444444444444444444444444444444444444444444444444444444444444",
+            "size": 512
+        }
+    ]
+}`
+
+var expectedImage = &capacity.Image{
+	Layers: []capacity.Layer{
+		{Digest: "sha256:3333333333333333333333333333333333333333333333333333333333333333", Size: 100},
+		{Digest: "sha256:1111111111111111111111111111111111111111111111111111111111111111", Size: 3000},
+		{Digest: "sha256:2222222222222222222222222222222222222222222222222222222222222222", Size: 600},
+	},
+}
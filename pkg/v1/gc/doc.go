@@ -18,5 +18,17 @@ Example of getting a garbage size:
 	    log.Fatal(err)
 	}
 	fmt.Printf("Garbage size: %+v", gcSize)
+
+Example of previewing untagged images that would be removed:
+
+	preview, err := gc.PreviewUntagged(ctx, client, registryID)
+	if err != nil {
+	    log.Fatal(err)
+	}
+	for _, repo := range preview.Repositories {
+	    for _, image := range repo.Images {
+	        fmt.Printf("%s@%s: %d bytes", repo.Name, image.Digest, image.Size)
+	    }
+	}
 */
 package gc
@@ -9,6 +9,7 @@ import (
 	v1 "github.com/selectel/craas-go/pkg"
 	"github.com/selectel/craas-go/pkg/svc"
 	"github.com/selectel/craas-go/pkg/v1/client"
+	"github.com/selectel/craas-go/pkg/v1/repository"
 )
 
 var ErrRegistryIDEmpty = errors.New("registry id is empty")
@@ -64,3 +65,50 @@ func GetGarbageSize(ctx context.Context, client *client.ServiceClient, registryI
 
 	return &size, responseResult, nil
 }
+
+// PreviewUntagged returns untagged images that a garbage collection started
+// with the DeleteUntagged option would remove, grouped by repository.
+// Registry ID is a required parameter.
+func PreviewUntagged(ctx context.Context, client *client.ServiceClient, registryID string) (*UntaggedPreview, error) {
+	if registryID == "" {
+		return nil, ErrRegistryIDEmpty
+	}
+
+	repositories, _, err := repository.ListRepositories(ctx, client, registryID)
+	if err != nil {
+		return nil, err
+	}
+
+	preview := &UntaggedPreview{
+		RegistryID:   registryID,
+		Repositories: make([]*UntaggedRepository, 0),
+	}
+	for _, repo := range repositories {
+		images, _, err := repository.ListImages(ctx, client, registryID, repo.Name)
+		if err != nil {
+			return nil, err
+		}
+
+		untagged := &UntaggedRepository{
+			Name:   repo.Name,
+			Images: make([]*repository.Image, 0),
+		}
+		for _, image := range images {
+			if len(image.Tags) != 0 {
+				continue
+			}
+			untagged.Images = append(untagged.Images, image)
+			untagged.Size += image.Size
+		}
+
+		// Skip repositories that have nothing to collect.
+		if len(untagged.Images) == 0 {
+			continue
+		}
+		preview.Repositories = append(preview.Repositories, untagged)
+		preview.ImagesCount += len(untagged.Images)
+		preview.Size += untagged.Size
+	}
+
+	return preview, nil
+}
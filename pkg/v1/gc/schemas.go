@@ -1,5 +1,7 @@
 package gc
 
+import "github.com/selectel/craas-go/pkg/v1/repository"
+
 // GarbageSize represents an unmarshalled garbage size from an API response.
 type GarbageSize struct {
 	// NonReferenced is a size of the layers non-referenced to any repository digests.
@@ -11,3 +13,33 @@ type GarbageSize struct {
 	// Summary is a size of the sum of Untagged and NonReferenced image layers.
 	Summary int64 `json:"sizeSummary"`
 }
+
+// UntaggedPreview represents untagged images of a registry that would be
+// removed by a garbage collection with the DeleteUntagged option.
+type UntaggedPreview struct {
+	// RegistryID is an identifier of the previewed registry.
+	RegistryID string
+
+	// Repositories is a list of repositories that have untagged images.
+	Repositories []*UntaggedRepository
+
+	// ImagesCount is a total number of untagged images in the registry.
+	ImagesCount int
+
+	// Size is a sum of the untagged images sizes in bytes.
+	// Layers shared between images are counted for every image, so the value
+	// may be greater than the Untagged value of the GarbageSize.
+	Size int64
+}
+
+// UntaggedRepository represents untagged images of a single repository.
+type UntaggedRepository struct {
+	// Name is the name of the repository.
+	Name string
+
+	// Images is the list of untagged images of the repository.
+	Images []*repository.Image
+
+	// Size is a sum of the untagged images sizes in bytes.
+	Size int64
+}
@@ -1,6 +1,11 @@
 package testing
 
-import "github.com/selectel/craas-go/pkg/v1/gc"
+import (
+	"time"
+
+	"github.com/selectel/craas-go/pkg/v1/gc"
+	"github.com/selectel/craas-go/pkg/v1/repository"
+)
 
 const testRegistryID = "fc43e322-b084-4b3c-a04a-1ab2a28cd860"
 
@@ -15,3 +20,88 @@ var expectedGetGarbageSizeResponse = &gc.GarbageSize{
 	Untagged:      30915818,
 	Summary:       87639320,
 }
+
+const testListRepositoriesResponseRaw = `[
+    {
+        "name": "nginx",
+        "size": 87634084,
+        "updatedAt": "2022-09-22T10:15:40.362702Z"
+    },
+    {
+        "name": "alpine",
+        "size": 2814559,
+        "updatedAt": "2022-09-22T10:15:40.362702Z"
+    }
+]`
+
+const testListNginxImagesResponseRaw = `[
+    {
+        "createdAt": "2022-05-17T22:37:17.011072851Z",
+        "digest": "sha256:a76df3b4f1478766631c794de7ff466aca466f995fd5bb216bb9643a3dd2a6bb",
+        "layers": [
+            {
+                "digest": "sha256:214ca5fb90323fe769c63a12af092f2572bf1c6b300263e09883909fc865d260",
+                "size": 31379476
+            }
+        ],
+        "size": 31379476,
+        "tags": []
+    },
+    {
+        "createdAt": "2022-05-18T22:37:17.011072851Z",
+        "digest": "sha256:0c2777301ee83e106586099533312b684b3782760d59d303865b64b90330a3e4",
+        "layers": [
+            {
+                "digest": "sha256:50836501937ff210a4ee8eedcb17b49b3b7627c5b7104397b2a6198c569d9231",
+                "size": 25338790
+            }
+        ],
+        "size": 25338790,
+        "tags": [
+            "latest"
+        ]
+    }
+]`
+
+const testListAlpineImagesResponseRaw = `[
+    {
+        "createdAt": "2022-05-17T22:37:17.011072851Z",
+        "digest": "sha256:df9b9388f04ad6279a7410b85cedfdcb2208c0a003da7ab5613af71079148139",
+        "layers": [
+            {
+                "digest": "sha256:df9b9388f04ad6279a7410b85cedfdcb2208c0a003da7ab5613af71079148139",
+                "size": 2814559
+            }
+        ],
+        "size": 2814559,
+        "tags": [
+            "3.16"
+        ]
+    }
+]`
+
+var expectedPreviewUntaggedResponse = &gc.UntaggedPreview{
+	RegistryID: testRegistryID,
+	Repositories: []*gc.UntaggedRepository{
+		{
+			Name: "nginx",
+			Images: []*repository.Image{
+				{
+					Digest:    "sha256:a76df3b4f1478766631c794de7ff466aca466f995fd5bb216bb9643a3dd2a6bb",
+					CreatedAt: time.Date(2022, 5, 17, 22, 37, 17, 11072851, time.UTC),
+					Tags:      []string{},
+					Size:      31379476,
+					Layers: []repository.Layer{
+						{
+							Digest: "sha256:214ca5fb90323fe769c63a12af092f2572bf1c6b300263e09883909fc865d260",
+							Size:   31379476,
+						},
+					},
+				},
+			},
+			Size: 31379476,
+		},
+	},
+	ImagesCount: 1,
+	Size:        31379476,
+}
@@ -2,6 +2,7 @@ package testing
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"reflect"
 	"testing"
@@ -82,3 +83,63 @@ func TestGetGarbageSize(t *testing.T) {
 		t.Fatalf("expected %#v, but got %#v", expectedGetGarbageSizeResponse, actual)
 	}
 }
+
+func TestPreviewUntagged(t *testing.T) {
+	repositoriesCalled := false
+	nginxImagesCalled := false
+	alpineImagesCalled := false
+	testEnv := testutils.SetupTestEnv()
+	defer testEnv.TearDownTestEnv()
+
+	testutils.HandleReqWithoutBody(t, &testutils.HandleReqOpts{
+		Mux:         testEnv.Mux,
+		URL:         "/api/v1/registries/" + testRegistryID + "/repositories",
+		RawResponse: testListRepositoriesResponseRaw,
+		Method:      http.MethodGet,
+		Status:      http.StatusOK,
+		CallFlag:    &repositoriesCalled,
+	})
+	testutils.HandleReqWithoutBody(t, &testutils.HandleReqOpts{
+		Mux:         testEnv.Mux,
+		URL:         "/api/v1/registries/" + testRegistryID + "/repositories/nginx/images",
+		RawResponse: testListNginxImagesResponseRaw,
+		Method:      http.MethodGet,
+		Status:      http.StatusOK,
+		CallFlag:    &nginxImagesCalled,
+	})
+	testutils.HandleReqWithoutBody(t, &testutils.HandleReqOpts{
+		Mux:         testEnv.Mux,
+		URL:         "/api/v1/registries/" + testRegistryID + "/repositories/alpine/images",
+		RawResponse: testListAlpineImagesResponseRaw,
+		Method:      http.MethodGet,
+		Status:      http.StatusOK,
+		CallFlag:    &alpineImagesCalled,
+	})
+
+	ctx := context.Background()
+	testClient, err := client.NewCRaaSClientV1(testutils.TokenID, testEnv.Server.URL+"/api/v1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	actual, err := gc.PreviewUntagged(ctx, testClient, testRegistryID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !repositoriesCalled || !nginxImagesCalled || !alpineImagesCalled {
+		t.Fatal("endpoint wasn't called")
+	}
+	if !reflect.DeepEqual(expectedPreviewUntaggedResponse, actual) {
+		t.Fatalf("expected %#v, but got %#v", expectedPreviewUntaggedResponse, actual)
+	}
+}
+
+func TestPreviewUntaggedEmptyRegistryID(t *testing.T) {
+	testClient, err := client.NewCRaaSClientV1(testutils.TokenID, "http://localhost/api/v1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = gc.PreviewUntagged(context.Background(), testClient, "")
+	if !errors.Is(err, gc.ErrRegistryIDEmpty) {
+		t.Fatalf("expected %v error, but got %v", gc.ErrRegistryIDEmpty, err)
+	}
+}
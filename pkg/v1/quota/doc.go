@@ -0,0 +1,30 @@
+/*
+Package `quota` provides a set of functions for checking registry storage
+usage against thresholds before pushes start to fail.
+
+A registry is reported with the warning or critical level if its usage
+exceeds a percentage of the size limit or its free space drops below
+an absolute headroom. For registries above a threshold the garbage size is
+requested to suggest a garbage collection if it would bring usage back
+under the thresholds.
+
+Example of checking all registries in CI:
+
+	report, err := quota.Check(ctx, client, &quota.Opts{
+	    Thresholds: quota.Thresholds{
+	        WarningPercent:   75,
+	        CriticalPercent:  90,
+	        CriticalHeadroom: 1 << 30,
+	    },
+	})
+	if err != nil {
+	    log.Fatal(err)
+	}
+	for _, finding := range report.Findings {
+	    if finding.Level != quota.LevelOK {
+	        fmt.Printf("%s: %s %v\n", finding.Registry, finding.Level, finding.Reasons)
+	    }
+	}
+	os.Exit(report.ExitCode())
+*/
+package quota
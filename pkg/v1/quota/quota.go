@@ -0,0 +1,267 @@
+package quota
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/selectel/craas-go/pkg/format"
+	"github.com/selectel/craas-go/pkg/v1/client"
+	"github.com/selectel/craas-go/pkg/v1/gc"
+	"github.com/selectel/craas-go/pkg/v1/registry"
+)
+
+const (
+	// DefaultWarningPercent is a default usage reported as a warning.
+	DefaultWarningPercent = 80
+
+	// DefaultCriticalPercent is a default usage reported as critical.
+	DefaultCriticalPercent = 90
+)
+
+var ErrInvalidThresholds = errors.New("invalid quota thresholds")
+
+// Opts represents options of the check.
+type Opts struct {
+	Thresholds Thresholds
+
+	// Registries limits the check to registries with these names or IDs,
+	// all registries are checked by default.
+	Registries []string
+
+	// SkipGarbageSize disables garbage size requests and GC suggestions.
+	SkipGarbageSize bool
+
+	// Now is a time of the check, the current time is used if not set.
+	Now time.Time
+}
+
+// Validate checks that percentages are in the (0, 100] range, the warning
+// thresholds don't exceed the critical ones and headrooms aren't negative.
+func (t Thresholds) Validate() error {
+	switch {
+	case t.WarningPercent <= 0 || t.WarningPercent > 100 || t.CriticalPercent <= 0 || t.CriticalPercent > 100:
+		return fmt.Errorf("%w: percentages must be in the (0, 100] range", ErrInvalidThresholds)
+	case t.WarningPercent > t.CriticalPercent:
+		return fmt.Errorf("%w: warning percent is above the critical one", ErrInvalidThresholds)
+	case t.WarningHeadroom < 0 || t.CriticalHeadroom < 0:
+		return fmt.Errorf("%w: headroom is negative", ErrInvalidThresholds)
+	case t.WarningHeadroom != 0 && t.WarningHeadroom < t.CriticalHeadroom:
+		return fmt.Errorf("%w: warning headroom is below the critical one", ErrInvalidThresholds)
+	}
+
+	return nil
+}
+
+func withDefaults(opts *Opts) Opts {
+	o := Opts{}
+	if opts != nil {
+		o = *opts
+	}
+	if o.Thresholds.WarningPercent == 0 {
+		o.Thresholds.WarningPercent = DefaultWarningPercent
+	}
+	if o.Thresholds.CriticalPercent == 0 {
+		o.Thresholds.CriticalPercent = DefaultCriticalPercent
+	}
+	if o.Now.IsZero() {
+		o.Now = time.Now()
+	}
+
+	return o
+}
+
+// Check lists registries and evaluates their usage. Garbage sizes are
+// requested for registries above a threshold.
+func Check(ctx context.Context, client *client.ServiceClient, opts *Opts) (*Report, error) {
+	o := withDefaults(opts)
+	if err := o.Thresholds.Validate(); err != nil {
+		return nil, err
+	}
+
+	registries, _, err := registry.List(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+
+	garbage := make(map[string]*gc.GarbageSize)
+	for _, r := range registries {
+		if o.SkipGarbageSize || !selected(o.Registries, r) {
+			continue
+		}
+		if l, _ := level(usedPercent(r), r.Size, r.SizeLimit, o.Thresholds); l == LevelOK {
+			continue
+		}
+		size, _, err := gc.GetGarbageSize(ctx, client, r.ID)
+		if err != nil {
+			return nil, fmt.Errorf("registry %s: %w", r.Name, err)
+		}
+		garbage[r.ID] = size
+	}
+
+	return Evaluate(registries, garbage, &o)
+}
+
+// Evaluate checks usage of the registries without requests.
+// Garbage sizes are keyed by registry ID and may be nil.
+func Evaluate(registries []*registry.Registry, garbage map[string]*gc.GarbageSize, opts *Opts) (*Report, error) {
+	o := withDefaults(opts)
+	if err := o.Thresholds.Validate(); err != nil {
+		return nil, err
+	}
+
+	report := &Report{CheckedAt: o.Now, Findings: make([]*Finding, 0, len(registries))}
+	for _, r := range registries {
+		if !selected(o.Registries, r) {
+			continue
+		}
+		finding := evaluate(r, o.Thresholds)
+		if g, ok := garbage[r.ID]; ok && finding.Level != LevelOK {
+			suggestGC(finding, g, o.Thresholds)
+		}
+		report.Findings = append(report.Findings, finding)
+	}
+	sortFindings(report.Findings)
+
+	return report, nil
+}
+
+func selected(filter []string, r *registry.Registry) bool {
+	if len(filter) == 0 {
+		return true
+	}
+	for _, v := range filter {
+		if v == r.Name || v == r.ID {
+			return true
+		}
+	}
+
+	return false
+}
+
+// evaluate returns a finding of the registry. Registries without
+// a size limit are always OK.
+func evaluate(r *registry.Registry, t Thresholds) *Finding {
+	finding := &Finding{
+		RegistryID: r.ID,
+		Registry:   r.Name,
+		Size:       r.Size,
+		SizeLimit:  r.SizeLimit,
+		Headroom:   r.SizeLimit - r.Size,
+	}
+	if r.SizeLimit > 0 {
+		finding.UsedPercent = usedPercent(r)
+	}
+	finding.Level, finding.Reasons = level(finding.UsedPercent, r.Size, r.SizeLimit, t)
+
+	return finding
+}
+
+// usedPercent returns the usage reported by the API,
+// it's computed from the sizes if the API doesn't report it.
+func usedPercent(r *registry.Registry) float64 {
+	if r.Used > 0 {
+		return float64(r.Used)
+	}
+
+	return percent(r.Size, r.SizeLimit)
+}
+
+func percent(size, limit int64) float64 {
+	if limit <= 0 {
+		return 0
+	}
+
+	return float64(size) / float64(limit) * 100
+}
+
+// level returns the level of the usage in percent and the size and exceeded thresholds.
+func level(used float64, size, limit int64, t Thresholds) (Level, []string) {
+	if limit <= 0 {
+		return LevelOK, nil
+	}
+
+	result := LevelOK
+	var reasons []string
+	raise := func(l Level, reason string) {
+		if l.severity() > result.severity() {
+			result = l
+		}
+		reasons = append(reasons, reason)
+	}
+
+	switch {
+	case used >= t.CriticalPercent:
+		raise(LevelCritical, fmt.Sprintf("usage %.1f%% is at or above %.1f%%", used, t.CriticalPercent))
+	case used >= t.WarningPercent:
+		raise(LevelWarning, fmt.Sprintf("usage %.1f%% is at or above %.1f%%", used, t.WarningPercent))
+	}
+
+	headroom := limit - size
+	switch {
+	case t.CriticalHeadroom > 0 && headroom < t.CriticalHeadroom:
+		raise(LevelCritical, fmt.Sprintf("headroom %s is below %s",
+			format.HumanSize(headroom), format.HumanSize(t.CriticalHeadroom)))
+	case t.WarningHeadroom > 0 && headroom < t.WarningHeadroom:
+		raise(LevelWarning, fmt.Sprintf("headroom %s is below %s",
+			format.HumanSize(headroom), format.HumanSize(t.WarningHeadroom)))
+	}
+
+	return result, reasons
+}
+
+// suggestGC sets GC suggestions if collecting non-referenced layers, or
+// also untagged images, would bring the registry to the OK level.
+func suggestGC(finding *Finding, garbage *gc.GarbageSize, t Thresholds) {
+	finding.Garbage = garbage
+	if garbage == nil {
+		return
+	}
+	// Usage after the collection is computed from the sizes.
+	afterGC := func(freed int64) Level {
+		size := finding.Size - freed
+		l, _ := level(percent(size, finding.SizeLimit), size, finding.SizeLimit, t)
+
+		return l
+	}
+	if afterGC(garbage.NonReferenced) == LevelOK && garbage.NonReferenced > 0 {
+		finding.SuggestGC = true
+
+		return
+	}
+	if afterGC(garbage.Summary) == LevelOK && garbage.Summary > 0 {
+		finding.SuggestGC = true
+		finding.SuggestDeleteUntagged = true
+	}
+}
+
+// sortFindings orders findings by level from critical and then by name.
+func sortFindings(findings []*Finding) {
+	sort.SliceStable(findings, func(i, j int) bool {
+		if a, b := findings[i].Level.severity(), findings[j].Level.severity(); a != b {
+			return a > b
+		}
+
+		return findings[i].Registry < findings[j].Registry
+	})
+}
+
+// Level returns the most severe level of the findings.
+func (r *Report) Level() Level {
+	result := LevelOK
+	for _, finding := range r.Findings {
+		if finding.Level.severity() > result.severity() {
+			result = finding.Level
+		}
+	}
+
+	return result
+}
+
+// ExitCode returns 0 for the OK level, 1 for warnings and 2 for critical
+// findings, following the monitoring plugins convention.
+func (r *Report) ExitCode() int {
+	return r.Level().severity()
+}
@@ -0,0 +1,82 @@
+package quota
+
+import (
+	"time"
+
+	"github.com/selectel/craas-go/pkg/v1/gc"
+)
+
+// Level represents a severity of registry usage.
+type Level string
+
+const (
+	LevelOK       Level = "OK"
+	LevelWarning  Level = "WARNING"
+	LevelCritical Level = "CRITICAL"
+)
+
+// severity returns an order of the level for comparisons.
+func (l Level) severity() int {
+	switch l {
+	case LevelWarning:
+		return 1
+	case LevelCritical:
+		return 2
+	default:
+		return 0
+	}
+}
+
+// Thresholds represents limits of registry usage.
+// Zero values of headroom thresholds disable them.
+type Thresholds struct {
+	// WarningPercent is a usage of the size limit reported as a warning.
+	// DefaultWarningPercent is used if not set.
+	WarningPercent float64
+
+	// CriticalPercent is a usage of the size limit reported as critical.
+	// DefaultCriticalPercent is used if not set.
+	CriticalPercent float64
+
+	// WarningHeadroom is a free space in bytes below which a registry
+	// is reported as a warning.
+	WarningHeadroom int64
+
+	// CriticalHeadroom is a free space in bytes below which a registry
+	// is reported as critical.
+	CriticalHeadroom int64
+}
+
+// Finding represents a result of checking a registry.
+type Finding struct {
+	RegistryID string `json:"registryId"`
+	Registry   string `json:"registry"`
+	Level      Level  `json:"level"`
+
+	// Reasons describe exceeded thresholds.
+	Reasons []string `json:"reasons,omitempty"`
+
+	Size        int64   `json:"size"`
+	SizeLimit   int64   `json:"sizeLimit"`
+	UsedPercent float64 `json:"usedPercent"`
+
+	// Headroom is a free space in bytes.
+	Headroom int64 `json:"headroom"`
+
+	// Garbage is requested only for registries above a threshold.
+	Garbage *gc.GarbageSize `json:"garbage,omitempty"`
+
+	// SuggestGC is set if a garbage collection would bring the registry
+	// back to the OK level.
+	SuggestGC bool `json:"suggestGC"`
+
+	// SuggestDeleteUntagged is set if the garbage collection needs to delete
+	// untagged images to free enough space.
+	SuggestDeleteUntagged bool `json:"suggestDeleteUntagged"`
+}
+
+// Report represents findings of all checked registries.
+type Report struct {
+	CheckedAt time.Time  `json:"checkedAt"`
+	Findings  []*Finding `json:"findings"`
+}
@@ -0,0 +1,91 @@
+package testing
+
+import (
+	"github.com/selectel/craas-go/pkg/v1/gc"
+	"github.com/selectel/craas-go/pkg/v1/quota"
+)
+
+const (
+	testFullRegistryID = "fc43e322-b084-4b3c-a04a-1ab2a28cd860"
+	testBusyRegistryID = "0cb4d1cd-5e05-4a9c-9e1c-6bcb3b7ae1a1"
+	testIdleRegistryID = "9f3b5b5e-1b5a-4b5c-9b5a-5b5c1b5a4b5c"
+)
+
+const testListRegistriesResponseRaw = `[
+    {
+        "id": "9f3b5b5e-1b5a-4b5c-9b5a-5b5c1b5a4b5c",
+        "name": "idle",
+        "createdAt": "2022-10-25T10:25:22.556Z",
+        "status": "ACTIVE",
+        "size": 1000,
+        "sizeLimit": 10000,
+        "used": 10
+    },
+    {
+        "id": "0cb4d1cd-5e05-4a9c-9e1c-6bcb3b7ae1a1",
+        "name": "busy",
+        "createdAt": "2022-10-25T10:25:22.556Z",
+        "status": "ACTIVE",
+        "size": 8500,
+        "sizeLimit": 10000,
+        "used": 85
+    },
+    {
+        "id": "fc43e322-b084-4b3c-a04a-1ab2a28cd860",
+        "name": "full",
+        "createdAt": "2022-10-25T10:25:22.556Z",
+        "status": "ACTIVE",
+        "size": 9500,
+        "sizeLimit": 10000,
+        "used": 95
+    }
+]`
+
+const testBusyGarbageSizeResponseRaw = `{
+    "sizeNonReferenced": 1000,
+    "sizeUntagged": 0,
+    "sizeSummary": 1000
+}`
+
+const testFullGarbageSizeResponseRaw = `{
+    "sizeNonReferenced": 500,
+    "sizeUntagged": 2000,
+    "sizeSummary": 2500
+}`
+
+var expectedFindings = []*quota.Finding{
+	{
+		RegistryID:            testFullRegistryID,
+		Registry:              "full",
+		Level:                 quota.LevelCritical,
+		Reasons:               []string{"usage 95.0% is at or above 90.0%", "headroom 500 B is below 1000 B"},
+		Size:                  9500,
+		SizeLimit:             10000,
+		UsedPercent:           95,
+		Headroom:              500,
+		Garbage:               &gc.GarbageSize{NonReferenced: 500, Untagged: 2000, Summary: 2500},
+		SuggestGC:             true,
+		SuggestDeleteUntagged: true,
+	},
+	{
+		RegistryID:  testBusyRegistryID,
+		Registry:    "busy",
+		Level:       quota.LevelWarning,
+		Reasons:     []string{"usage 85.0% is at or above 80.0%"},
+		Size:        8500,
+		SizeLimit:   10000,
+		UsedPercent: 85,
+		Headroom:    1500,
+		Garbage:     &gc.GarbageSize{NonReferenced: 1000, Untagged: 0, Summary: 1000},
+		SuggestGC:   true,
+	},
+	{
+		RegistryID:  testIdleRegistryID,
+		Registry:    "idle",
+		Level:       quota.LevelOK,
+		Size:        1000,
+		SizeLimit:   10000,
+		UsedPercent: 10,
+		Headroom:    9000,
+	},
+}
@@ -0,0 +1,94 @@
+package testing
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/selectel/craas-go/pkg/testutils"
+	"github.com/selectel/craas-go/pkg/v1/client"
+	"github.com/selectel/craas-go/pkg/v1/quota"
+	"github.com/selectel/craas-go/pkg/v1/registry"
+)
+
+func TestCheck(t *testing.T) {
+	registriesCalled, busyCalled, fullCalled := false, false, false
+	testEnv := testutils.SetupTestEnv()
+	defer testEnv.TearDownTestEnv()
+
+	testutils.HandleReqWithoutBody(t, &testutils.HandleReqOpts{
+		Mux:         testEnv.Mux,
+		URL:         "/api/v1/registries",
+		RawResponse: testListRegistriesResponseRaw,
+		Method:      http.MethodGet,
+		Status:      http.StatusOK,
+		CallFlag:    &registriesCalled,
+	})
+	testutils.HandleReqWithoutBody(t, &testutils.HandleReqOpts{
+		Mux:         testEnv.Mux,
+		URL:         "/api/v1/registries/" + testBusyRegistryID + "/garbage-collection/size",
+		RawResponse: testBusyGarbageSizeResponseRaw,
+		Method:      http.MethodGet,
+		Status:      http.StatusOK,
+		CallFlag:    &busyCalled,
+	})
+	testutils.HandleReqWithoutBody(t, &testutils.HandleReqOpts{
+		Mux:         testEnv.Mux,
+		URL:         "/api/v1/registries/" + testFullRegistryID + "/garbage-collection/size",
+		RawResponse: testFullGarbageSizeResponseRaw,
+		Method:      http.MethodGet,
+		Status:      http.StatusOK,
+		CallFlag:    &fullCalled,
+	})
+
+	ctx := context.Background()
+	testClient, err := client.NewCRaaSClientV1(testutils.TokenID, testEnv.Server.URL+"/api/v1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	report, err := quota.Check(ctx, testClient, &quota.Opts{
+		Thresholds: quota.Thresholds{CriticalHeadroom: 1000},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !registriesCalled || !busyCalled || !fullCalled {
+		t.Fatal("endpoint wasn't called")
+	}
+	if !reflect.DeepEqual(expectedFindings, report.Findings) {
+		t.Fatalf("expected %#v, but got %#v", expectedFindings, report.Findings)
+	}
+	if report.ExitCode() != 2 {
+		t.Fatalf("expected exit code 2, but got %d", report.ExitCode())
+	}
+}
+
+func TestEvaluateInvalidThresholds(t *testing.T) {
+	_, err := quota.Evaluate(nil, nil, &quota.Opts{
+		Thresholds: quota.Thresholds{WarningPercent: 95, CriticalPercent: 90},
+	})
+	if !errors.Is(err, quota.ErrInvalidThresholds) {
+		t.Fatalf("expected %v, but got %v", quota.ErrInvalidThresholds, err)
+	}
+}
+
+func TestEvaluateReportedUsage(t *testing.T) {
+	registries := []*registry.Registry{
+		{ID: testBusyRegistryID, Name: "busy", Size: 5000, SizeLimit: 10000, Used: 92},
+		{ID: testIdleRegistryID, Name: "idle", Size: 1000, SizeLimit: 10000},
+	}
+	report, err := quota.Evaluate(registries, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	busy, idle := report.Findings[0], report.Findings[1]
+	if busy.Level != quota.LevelCritical || busy.UsedPercent != 92 {
+		t.Fatalf("expected the reported usage to be critical, but got %#v", busy)
+	}
+	if idle.Level != quota.LevelOK || idle.UsedPercent != 10 {
+		t.Fatalf("expected the usage computed from sizes, but got %#v", idle)
+	}
+}
@@ -0,0 +1,412 @@
+package distribution
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/selectel/craas-go/pkg/dockerconfig"
+	"github.com/selectel/craas-go/pkg/svc"
+)
+
+// DefaultEndpoint is an endpoint of the Selectel Container Registry.
+const DefaultEndpoint = "https://" + dockerconfig.DefaultRegistryHost
+
+// maxManifestSize limits manifests read into memory.
+const maxManifestSize = 4 << 20
+
+var (
+	ErrEndpointEmpty        = errors.New("distribution endpoint is empty")
+	ErrCredentialsEmpty     = errors.New("registry credentials are empty")
+	ErrNameEmpty            = errors.New("repository name is empty")
+	ErrReferenceEmpty       = errors.New("manifest reference is empty")
+	ErrInvalidDigest        = errors.New("invalid digest")
+	ErrDigestMismatch       = errors.New("content doesn't match the digest")
+	ErrUnsupportedMediaType = errors.New("unsupported manifest media type")
+	ErrManifestTooLarge     = errors.New("manifest is too large")
+	ErrUnauthorized         = errors.New("registry authentication failed")
+	ErrNotFound             = errors.New("not found in the registry")
+	ErrUnexpectedStatus     = errors.New("unexpected registry response status")
+)
+
+// Opts represents options of the Client.
+type Opts struct {
+	// HTTPClient is used to send requests, svc.NewHTTPClient is used if not set.
+	HTTPClient *http.Client
+
+	// UserAgent is sent with every request if set.
+	UserAgent string
+}
+
+// Client reads manifests and blobs from the Distribution API.
+// It is safe for concurrent use.
+type Client struct {
+	endpoint   string
+	creds      dockerconfig.Credentials
+	httpClient *http.Client
+	userAgent  string
+
+	mu sync.Mutex
+
+	// tokens are bearer tokens keyed by scope.
+	tokens map[string]string
+}
+
+// NewClient returns a client of the registry endpoint, such as DefaultEndpoint.
+// An endpoint without a scheme uses HTTPS.
+func NewClient(endpoint string, creds dockerconfig.Credentials, opts *Opts) (*Client, error) {
+	if endpoint == "" {
+		return nil, ErrEndpointEmpty
+	}
+	if creds == nil {
+		return nil, ErrCredentialsEmpty
+	}
+	if !strings.Contains(endpoint, "://") {
+		endpoint = "https://" + endpoint
+	}
+
+	c := &Client{
+		endpoint:   strings.TrimSuffix(endpoint, "/"),
+		creds:      creds,
+		httpClient: svc.NewHTTPClient(),
+		tokens:     make(map[string]string),
+	}
+	if opts != nil {
+		if opts.HTTPClient != nil {
+			c.httpClient = opts.HTTPClient
+		}
+		c.userAgent = opts.UserAgent
+	}
+
+	return c, nil
+}
+
+// GetManifest returns a manifest or an index by a tag or a digest.
+// The manifest digest is verified against the requested digest and
+// the Docker-Content-Digest header.
+func (c *Client) GetManifest(ctx context.Context, name, reference string) (*Manifest, error) {
+	if name == "" {
+		return nil, ErrNameEmpty
+	}
+	if reference == "" {
+		return nil, ErrReferenceEmpty
+	}
+
+	resp, err := c.get(ctx, name, "manifests/"+reference, strings.Join(manifestMediaTypes, ", "))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	raw, err := io.ReadAll(io.LimitReader(resp.Body, maxManifestSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(raw) > maxManifestSize {
+		return nil, fmt.Errorf("%w: it exceeds %d bytes", ErrManifestTooLarge, maxManifestSize)
+	}
+
+	digest := resp.Header.Get("Docker-Content-Digest")
+	if isDigest(reference) {
+		if digest != "" && digest != reference {
+			return nil, fmt.Errorf("%w: requested %s, registry returned %s", ErrDigestMismatch, reference, digest)
+		}
+		digest = reference
+	}
+	if digest == "" {
+		digest = sha256Digest(raw)
+	}
+	if err := verifyDigest(digest, raw); err != nil {
+		return nil, err
+	}
+
+	m := &Manifest{}
+	if err := json.Unmarshal(raw, m); err != nil {
+		return nil, err
+	}
+	if m.MediaType == "" {
+		m.MediaType = mediaType(resp.Header.Get("Content-Type"))
+	}
+	if !supportedMediaType(m.MediaType) {
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedMediaType, m.MediaType)
+	}
+	m.Digest = digest
+	m.Raw = raw
+
+	return m, nil
+}
+
+// OpenBlob returns a reader of the blob content. The last Read returns
+// an error wrapping ErrDigestMismatch if the content doesn't match the digest.
+func (c *Client) OpenBlob(ctx context.Context, name, digest string) (io.ReadCloser, error) {
+	if name == "" {
+		return nil, ErrNameEmpty
+	}
+	h, expected, err := newDigester(digest)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.get(ctx, name, "blobs/"+digest, "")
+	if err != nil {
+		return nil, err
+	}
+
+	return &verifyingReader{body: resp.Body, hash: h, digest: digest, expected: expected}, nil
+}
+
+// GetBlob reads the whole blob and verifies its digest.
+func (c *Client) GetBlob(ctx context.Context, name, digest string) ([]byte, error) {
+	blob, err := c.OpenBlob(ctx, name, digest)
+	if err != nil {
+		return nil, err
+	}
+	defer blob.Close()
+
+	return io.ReadAll(blob)
+}
+
+// GetImageConfig reads the config blob of an image manifest.
+func (c *Client) GetImageConfig(ctx context.Context, name string, m *Manifest) (*ImageConfig, error) {
+	if m.Config == nil {
+		return nil, fmt.Errorf("%w: %q has no config", ErrUnsupportedMediaType, m.MediaType)
+	}
+	data, err := c.GetBlob(ctx, name, m.Config.Digest)
+	if err != nil {
+		return nil, err
+	}
+
+	config := &ImageConfig{}
+	if err := json.Unmarshal(data, config); err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
+
+// get sends an authenticated GET request to the repository path.
+func (c *Client) get(ctx context.Context, name, path, accept string) (*http.Response, error) {
+	target := c.endpoint + "/v2/" + name + "/" + path
+	scope := "repository:" + name + ":pull"
+
+	resp, err := c.do(ctx, target, accept, c.authorization(scope))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusUnauthorized {
+		challenge := resp.Header.Get("WWW-Authenticate")
+		drainAndClose(resp)
+
+		authorization, err := c.authorize(ctx, challenge, scope)
+		if err != nil {
+			return nil, err
+		}
+		if resp, err = c.do(ctx, target, accept, authorization); err != nil {
+			return nil, err
+		}
+	}
+	if resp.StatusCode == http.StatusOK {
+		return resp, nil
+	}
+	defer drainAndClose(resp)
+
+	return nil, responseError(resp)
+}
+
+func (c *Client) do(ctx context.Context, target, accept, authorization string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return nil, err
+	}
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+	if c.userAgent != "" {
+		req.Header.Set("User-Agent", c.userAgent)
+	}
+
+	return c.httpClient.Do(req)
+}
+
+// authorization returns a cached Authorization header value of the scope.
+func (c *Client) authorization(scope string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.tokens[scope]
+}
+
+// authorize answers the challenge and caches the Authorization header value.
+func (c *Client) authorize(ctx context.Context, challenge, scope string) (string, error) {
+	scheme, params := parseChallenge(challenge)
+	username, password := c.creds.RegistryCredentials()
+
+	var authorization string
+	switch strings.ToLower(scheme) {
+	case "basic":
+		req := &http.Request{Header: http.Header{}}
+		req.SetBasicAuth(username, password)
+		authorization = req.Header.Get("Authorization")
+	case "bearer":
+		token, err := c.fetchToken(ctx, params, scope, username, password)
+		if err != nil {
+			return "", err
+		}
+		authorization = "Bearer " + token
+	default:
+		return "", fmt.Errorf("%w: unsupported challenge %q", ErrUnauthorized, challenge)
+	}
+
+	c.mu.Lock()
+	c.tokens[scope] = authorization
+	c.mu.Unlock()
+
+	return authorization, nil
+}
+
+// fetchToken requests a bearer token from the realm of the challenge.
+func (c *Client) fetchToken(ctx context.Context, params map[string]string, scope, username, password string) (string, error) {
+	realm, err := url.Parse(params["realm"])
+	if err != nil || params["realm"] == "" {
+		return "", fmt.Errorf("%w: invalid realm %q", ErrUnauthorized, params["realm"])
+	}
+	query := realm.Query()
+	if service := params["service"]; service != "" {
+		query.Set("service", service)
+	}
+	if challengeScope := params["scope"]; challengeScope != "" {
+		scope = challengeScope
+	}
+	query.Set("scope", scope)
+	realm.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, realm.String(), nil)
+	if err != nil {
+		return "", err
+	}
+	req.SetBasicAuth(username, password)
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer drainAndClose(resp)
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("%w: token endpoint responded with %d", ErrUnauthorized, resp.StatusCode)
+	}
+	var body struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", err
+	}
+	if body.Token != "" {
+		return body.Token, nil
+	}
+	if body.AccessToken != "" {
+		return body.AccessToken, nil
+	}
+
+	return "", fmt.Errorf("%w: token endpoint returned no token", ErrUnauthorized)
+}
+
+// parseChallenge parses a WWW-Authenticate header value like
+// `Bearer realm="https://auth",service="registry",scope="repository:a:pull,push"`.
+func parseChallenge(header string) (string, map[string]string) {
+	scheme, rest, _ := strings.Cut(strings.TrimSpace(header), " ")
+	params := make(map[string]string)
+	for rest = strings.TrimSpace(rest); rest != ""; {
+		key, value, ok := strings.Cut(rest, "=")
+		if !ok {
+			break
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+		if strings.HasPrefix(value, `"`) {
+			end := strings.Index(value[1:], `"`)
+			if end < 0 {
+				params[key] = value[1:]
+
+				break
+			}
+			params[key] = value[1 : end+1]
+			rest = strings.TrimPrefix(strings.TrimSpace(value[end+2:]), ",")
+		} else {
+			value, rest, _ = strings.Cut(value, ",")
+			params[key] = strings.TrimSpace(value)
+		}
+		rest = strings.TrimSpace(rest)
+	}
+
+	return scheme, params
+}
+
+// responseError returns an error of a failed response with messages of
+// the Distribution API error body.
+func responseError(resp *http.Response) error {
+	var body struct {
+		Errors []struct {
+			Code    string `json:"code"`
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	messages := make([]string, 0)
+	if json.Unmarshal(data, &body) == nil {
+		for _, e := range body.Errors {
+			messages = append(messages, strings.TrimSpace(e.Code+" "+e.Message))
+		}
+	}
+	if len(messages) == 0 && len(bytes.TrimSpace(data)) > 0 {
+		messages = append(messages, string(bytes.TrimSpace(data)))
+	}
+	detail := strings.Join(messages, "; ")
+
+	var sentinel error
+	switch resp.StatusCode {
+	case http.StatusUnauthorized, http.StatusForbidden:
+		sentinel = ErrUnauthorized
+	case http.StatusNotFound:
+		sentinel = ErrNotFound
+	default:
+		sentinel = ErrUnexpectedStatus
+	}
+	if detail == "" {
+		return fmt.Errorf("%w: %d", sentinel, resp.StatusCode)
+	}
+
+	return fmt.Errorf("%w: %d %s", sentinel, resp.StatusCode, detail)
+}
+
+func drainAndClose(resp *http.Response) {
+	_, _ = io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+}
+
+// mediaType returns the media type of a Content-Type header without parameters.
+func mediaType(contentType string) string {
+	value, _, _ := strings.Cut(contentType, ";")
+
+	return strings.TrimSpace(value)
+}
+
+func supportedMediaType(value string) bool {
+	for _, supported := range manifestMediaTypes {
+		if value == supported {
+			return true
+		}
+	}
+
+	return false
+}
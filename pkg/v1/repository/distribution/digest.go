@@ -0,0 +1,77 @@
+package distribution
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"strings"
+)
+
+// newDigester returns a hash of the digest algorithm and the expected hex value.
+func newDigester(digest string) (hash.Hash, string, error) {
+	algorithm, value, ok := strings.Cut(digest, ":")
+	if !ok || value == "" {
+		return nil, "", fmt.Errorf("%w: %q", ErrInvalidDigest, digest)
+	}
+	switch algorithm {
+	case "sha256":
+		return sha256.New(), value, nil
+	case "sha512":
+		return sha512.New(), value, nil
+	default:
+		return nil, "", fmt.Errorf("%w: unsupported algorithm %q", ErrInvalidDigest, algorithm)
+	}
+}
+
+// isDigest reports whether the manifest reference is a digest rather than a tag.
+func isDigest(reference string) bool {
+	return strings.Contains(reference, ":")
+}
+
+// verifyDigest checks the content against the digest.
+func verifyDigest(digest string, content []byte) error {
+	h, expected, err := newDigester(digest)
+	if err != nil {
+		return err
+	}
+	h.Write(content)
+	if actual := hex.EncodeToString(h.Sum(nil)); actual != expected {
+		return fmt.Errorf("%w: expected %s, got %s", ErrDigestMismatch, digest, actual)
+	}
+
+	return nil
+}
+
+// verifyingReader checks the digest of the content when it's read to the end.
+type verifyingReader struct {
+	body     io.ReadCloser
+	hash     hash.Hash
+	digest   string
+	expected string
+}
+
+func (r *verifyingReader) Read(p []byte) (int, error) {
+	n, err := r.body.Read(p)
+	r.hash.Write(p[:n])
+	if err == io.EOF {
+		if actual := hex.EncodeToString(r.hash.Sum(nil)); actual != r.expected {
+			return n, fmt.Errorf("%w: expected %s, got %s", ErrDigestMismatch, r.digest, actual)
+		}
+	}
+
+	return n, err
+}
+
+func (r *verifyingReader) Close() error {
+	return r.body.Close()
+}
+
+// sha256Digest returns the sha256 digest of the content.
+func sha256Digest(content []byte) string {
+	sum := sha256.Sum256(content)
+
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,48 @@
+/*
+Package `distribution` provides a set of functions for reading manifests and
+blobs from the OCI Distribution API of a registry.
+
+The client logs in with a registry token, such as token.Token or
+tokenv2.TokenV2, and verifies digests of everything it downloads.
+Repository names include the registry name, for example "my-registry/nginx".
+
+Example of reading an image config of a platform:
+
+	client, err := distribution.NewClient(distribution.DefaultEndpoint, registryToken, nil)
+	if err != nil {
+	    log.Fatal(err)
+	}
+	manifest, err := client.GetManifest(ctx, "my-registry/nginx", "latest")
+	if err != nil {
+	    log.Fatal(err)
+	}
+	if manifest.IsIndex() {
+	    platform, ok := manifest.FindPlatform("linux", "amd64")
+	    if !ok {
+	        log.Fatal("no linux/amd64 image")
+	    }
+	    manifest, err = client.GetManifest(ctx, "my-registry/nginx", platform.Digest)
+	    if err != nil {
+	        log.Fatal(err)
+	    }
+	}
+	config, err := client.GetImageConfig(ctx, "my-registry/nginx", manifest)
+	if err != nil {
+	    log.Fatal(err)
+	}
+	fmt.Println(config.Config.Entrypoint)
+
+Example of downloading a layer:
+
+	blob, err := client.OpenBlob(ctx, "my-registry/nginx", manifest.Layers[0].Digest)
+	if err != nil {
+	    log.Fatal(err)
+	}
+	defer blob.Close()
+	// The last Read returns ErrDigestMismatch if the content doesn't match the digest.
+	_, err = io.Copy(file, blob)
+	if err != nil {
+	    log.Fatal(err)
+	}
+*/
+package distribution
@@ -0,0 +1,92 @@
+package distribution
+
+import "time"
+
+const (
+	MediaTypeDockerManifest     = "application/vnd.docker.distribution.manifest.v2+json"
+	MediaTypeDockerManifestList = "application/vnd.docker.distribution.manifest.list.v2+json"
+	MediaTypeOCIManifest        = "application/vnd.oci.image.manifest.v1+json"
+	MediaTypeOCIIndex           = "application/vnd.oci.image.index.v1+json"
+)
+
+// manifestMediaTypes are media types accepted by GetManifest.
+var manifestMediaTypes = []string{
+	MediaTypeOCIIndex,
+	MediaTypeOCIManifest,
+	MediaTypeDockerManifestList,
+	MediaTypeDockerManifest,
+}
+
+// Descriptor represents a reference to a manifest or a blob.
+type Descriptor struct {
+	MediaType   string            `json:"mediaType"`
+	Digest      string            `json:"digest"`
+	Size        int64             `json:"size"`
+	Platform    *Platform         `json:"platform,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// Platform represents a platform of an image in an index.
+type Platform struct {
+	Architecture string `json:"architecture"`
+	OS           string `json:"os"`
+	Variant      string `json:"variant,omitempty"`
+}
+
+// Manifest represents an image manifest or an index of manifests.
+type Manifest struct {
+	SchemaVersion int    `json:"schemaVersion"`
+	MediaType     string `json:"mediaType,omitempty"`
+
+	// Config and Layers are set for image manifests.
+	Config *Descriptor  `json:"config,omitempty"`
+	Layers []Descriptor `json:"layers,omitempty"`
+
+	// Manifests are set for indexes and manifest lists.
+	Manifests []Descriptor `json:"manifests,omitempty"`
+
+	Annotations map[string]string `json:"annotations,omitempty"`
+
+	// Digest is a verified digest of the manifest.
+	Digest string `json:"-"`
+
+	// Raw is the manifest as returned by the registry.
+	Raw []byte `json:"-"`
+}
+
+// IsIndex reports whether the manifest is an OCI index or a Docker manifest list.
+func (m *Manifest) IsIndex() bool {
+	return m.MediaType == MediaTypeOCIIndex || m.MediaType == MediaTypeDockerManifestList
+}
+
+// FindPlatform returns a descriptor of the index for the OS and architecture.
+func (m *Manifest) FindPlatform(os, architecture string) (*Descriptor, bool) {
+	for i := range m.Manifests {
+		p := m.Manifests[i].Platform
+		if p != nil && p.OS == os && p.Architecture == architecture {
+			return &m.Manifests[i], true
+		}
+	}
+
+	return nil, false
+}
+
+// ImageConfig represents an image configuration blob.
+type ImageConfig struct {
+	Architecture string     `json:"architecture"`
+	OS           string     `json:"os"`
+	Created      *time.Time `json:"created,omitempty"`
+	Config       struct {
+		User         string              `json:"User,omitempty"`
+		Env          []string            `json:"Env,omitempty"`
+		Entrypoint   []string            `json:"Entrypoint,omitempty"`
+		Cmd          []string            `json:"Cmd,omitempty"`
+		WorkingDir   string              `json:"WorkingDir,omitempty"`
+		Labels       map[string]string   `json:"Labels,omitempty"`
+		ExposedPorts map[string]struct{} `json:"ExposedPorts,omitempty"`
+	} `json:"config"`
+	RootFS struct {
+		Type    string   `json:"type"`
+		DiffIDs []string `json:"diff_ids"`
+	} `json:"rootfs"`
+}
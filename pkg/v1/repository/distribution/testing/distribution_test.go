@@ -0,0 +1,109 @@
+package testing
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/selectel/craas-go/pkg/v1/repository/distribution"
+	"github.com/selectel/craas-go/pkg/v1/token"
+	tokenv2 "github.com/selectel/craas-go/pkg/v2/token"
+)
+
+func TestGetManifestOfPlatform(t *testing.T) {
+	registry := newTestRegistry(t)
+	client, err := distribution.NewClient(registry.server.URL, &tokenv2.TokenV2{Token: testTokenSecret}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	index, err := client.GetManifest(ctx, testRepository, "latest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !index.IsIndex() {
+		t.Fatalf("expected an index, but got %q", index.MediaType)
+	}
+	platform, ok := index.FindPlatform("linux", "amd64")
+	if !ok {
+		t.Fatal("expected a linux/amd64 manifest")
+	}
+
+	manifest, err := client.GetManifest(ctx, testRepository, platform.Digest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if manifest.Digest != platform.Digest || len(manifest.Layers) != 1 {
+		t.Fatalf("unexpected manifest %#v", manifest)
+	}
+
+	config, err := client.GetImageConfig(ctx, testRepository, manifest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedEntrypoint := []string{"/docker-entrypoint.sh"}
+	if !reflect.DeepEqual(expectedEntrypoint, config.Config.Entrypoint) {
+		t.Fatalf("expected %#v, but got %#v", expectedEntrypoint, config.Config.Entrypoint)
+	}
+
+	layer, err := client.GetBlob(ctx, testRepository, manifest.Layers[0].Digest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(layer) != testLayerRaw {
+		t.Fatalf("expected %q, but got %q", testLayerRaw, layer)
+	}
+	if registry.tokenRequests != 1 {
+		t.Fatalf("expected the bearer token to be requested once, but got %d requests", registry.tokenRequests)
+	}
+}
+
+func TestGetBlobDigestMismatch(t *testing.T) {
+	registry := newTestRegistry(t)
+	layerDigest := digestOf([]byte(testLayerRaw))
+	registry.blobs[layerDigest] = []byte("tampered content")
+
+	client, err := distribution.NewClient(registry.server.URL, &token.Token{Token: testTokenSecret}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = client.GetBlob(context.Background(), testRepository, layerDigest)
+	if !errors.Is(err, distribution.ErrDigestMismatch) {
+		t.Fatalf("expected %v, but got %v", distribution.ErrDigestMismatch, err)
+	}
+}
+
+func TestGetManifestErrors(t *testing.T) {
+	registry := newTestRegistry(t)
+	ctx := context.Background()
+
+	client, err := distribution.NewClient(registry.server.URL, &tokenv2.TokenV2{Token: testTokenSecret}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = client.GetManifest(ctx, testRepository, "missing")
+	if !errors.Is(err, distribution.ErrNotFound) {
+		t.Fatalf("expected %v, but got %v", distribution.ErrNotFound, err)
+	}
+
+	registry.manifests["huge"] = &testManifest{
+		mediaType: "application/vnd.oci.image.manifest.v1+json",
+		content:   []byte(`{"padding": "` + strings.Repeat("a", 4<<20) + `"}`),
+	}
+	_, err = client.GetManifest(ctx, testRepository, "huge")
+	if !errors.Is(err, distribution.ErrManifestTooLarge) {
+		t.Fatalf("expected %v, but got %v", distribution.ErrManifestTooLarge, err)
+	}
+
+	client, err = distribution.NewClient(registry.server.URL, &tokenv2.TokenV2{Token: "wrong"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = client.GetManifest(ctx, testRepository, "latest")
+	if !errors.Is(err, distribution.ErrUnauthorized) {
+		t.Fatalf("expected %v, but got %v", distribution.ErrUnauthorized, err)
+	}
+}
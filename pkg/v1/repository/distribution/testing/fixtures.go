@@ -0,0 +1,62 @@
+package testing
+
+const (
+	testRepository    = "test-registry/nginx"
+	testRegistryToken = "registry-bearer-token"
+	testTokenSecret   = "craas-token-secret"
+)
+
+const testConfigRaw = `{
+    "architecture": "amd64",
+    "os": "linux",
+    "config": {
+        "Entrypoint": ["/docker-entrypoint.sh"],
+        "Cmd": ["nginx", "-g", "daemon off;"]
+    },
+    "rootfs": {
+        "type": "layers",
+        "diff_ids": ["sha256:0000000000000000000000000000000000000000000000000000000000000000"]
+    }
+}`
+
+const testLayerRaw = "layer content"
+
+// testManifestTemplate is an image manifest with %s placeholders for
+// the config digest and size and the layer digest and size.
+const testManifestTemplate = `{
+    "schemaVersion": 2,
+    "mediaType": "application/vnd.oci.image.manifest.v1+json",
+    "config": {
+        "mediaType": "application/vnd.oci.image.config.v1+json",
+        "digest": "%s",
+        "size": %d
+    },
+    "layers": [
+        {
+            "mediaType": "application/vnd.oci.image.layer.v1.tar+gzip",
+            "digest": "%s",
+            "size": %d
+        }
+    ]
+}`
+
+// testIndexTemplate is an index with %s placeholders for the manifest
+// digest and size.
+const testIndexTemplate = `{
+    "schemaVersion": 2,
+    "mediaType": "application/vnd.oci.image.index.v1+json",
+    "manifests": [
+        {
+            "mediaType": "application/vnd.oci.image.manifest.v1+json",
+            "digest": "sha256:1111111111111111111111111111111111111111111111111111111111111111",
+            "size": 100,
+            "platform": {"architecture": "arm64", "os": "linux"}
+        },
+        {
+            "mediaType": "application/vnd.oci.image.manifest.v1+json",
+            "digest": "%s",
+            "size": %d,
+            "platform": {"architecture": "amd64", "os": "linux"}
+        }
+    ]
+}`
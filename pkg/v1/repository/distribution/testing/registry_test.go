@@ -0,0 +1,116 @@
+package testing
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	tokenv2 "github.com/selectel/craas-go/pkg/v2/token"
+)
+
+// testManifest represents a manifest served by the stand-in registry.
+type testManifest struct {
+	mediaType string
+	content   []byte
+}
+
+// testRegistry is an in-process stand-in of a registry with the bearer
+// token authentication.
+type testRegistry struct {
+	server *httptest.Server
+
+	mu            sync.Mutex
+	manifests     map[string]*testManifest
+	blobs         map[string][]byte
+	tokenRequests int
+}
+
+func digestOf(content []byte) string {
+	sum := sha256.Sum256(content)
+
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// newTestRegistry serves an index tagged "latest" of an image with a config and a layer.
+func newTestRegistry(t *testing.T) *testRegistry {
+	t.Helper()
+
+	r := &testRegistry{manifests: make(map[string]*testManifest), blobs: make(map[string][]byte)}
+	config, layer := []byte(testConfigRaw), []byte(testLayerRaw)
+	r.blobs[digestOf(config)] = config
+	r.blobs[digestOf(layer)] = layer
+
+	manifest := []byte(fmt.Sprintf(testManifestTemplate, digestOf(config), len(config), digestOf(layer), len(layer)))
+	r.manifests[digestOf(manifest)] = &testManifest{mediaType: "application/vnd.oci.image.manifest.v1+json", content: manifest}
+
+	index := []byte(fmt.Sprintf(testIndexTemplate, digestOf(manifest), len(manifest)))
+	indexManifest := &testManifest{mediaType: "application/vnd.oci.image.index.v1+json", content: index}
+	r.manifests[digestOf(index)] = indexManifest
+	r.manifests["latest"] = indexManifest
+
+	r.server = httptest.NewServer(http.HandlerFunc(r.serveHTTP))
+	t.Cleanup(r.server.Close)
+
+	return r
+}
+
+func (r *testRegistry) serveHTTP(w http.ResponseWriter, req *http.Request) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if req.URL.Path == "/token" {
+		username, password, ok := req.BasicAuth()
+		if !ok || username != tokenv2.RegistryUsername || password != testTokenSecret ||
+			req.URL.Query().Get("scope") != "repository:"+testRepository+":pull" {
+			w.WriteHeader(http.StatusUnauthorized)
+
+			return
+		}
+		r.tokenRequests++
+		_ = json.NewEncoder(w).Encode(map[string]string{"token": testRegistryToken})
+
+		return
+	}
+
+	path := strings.TrimPrefix(req.URL.Path, "/v2/")
+	if req.Header.Get("Authorization") != "Bearer "+testRegistryToken {
+		w.Header().Set("WWW-Authenticate", fmt.Sprintf(
+			`Bearer realm="%s/token",service="cr.test",scope="repository:%s:pull"`, r.server.URL, testRepository))
+		w.WriteHeader(http.StatusUnauthorized)
+
+		return
+	}
+
+	if i := strings.LastIndex(path, "/manifests/"); i >= 0 {
+		manifest, ok := r.manifests[path[i+len("/manifests/"):]]
+		if !ok || path[:i] != testRepository {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"errors": [{"code": "MANIFEST_UNKNOWN", "message": "manifest unknown"}]}`))
+
+			return
+		}
+		w.Header().Set("Content-Type", manifest.mediaType)
+		w.Header().Set("Docker-Content-Digest", digestOf(manifest.content))
+		_, _ = w.Write(manifest.content)
+
+		return
+	}
+	if i := strings.LastIndex(path, "/blobs/"); i >= 0 {
+		blob, ok := r.blobs[path[i+len("/blobs/"):]]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+
+			return
+		}
+		_, _ = w.Write(blob)
+
+		return
+	}
+	w.WriteHeader(http.StatusNotFound)
+}
@@ -0,0 +1,196 @@
+package snapshot
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"time"
+)
+
+// Compare returns changes from the before snapshot to the after one.
+func Compare(before, after *Snapshot) *Diff {
+	d := &Diff{From: before.CreatedAt, To: after.CreatedAt}
+
+	beforeRegistries := make(map[string]*Registry, len(before.Registries))
+	for _, r := range before.Registries {
+		beforeRegistries[r.Name] = r
+	}
+	afterRegistries := make(map[string]*Registry, len(after.Registries))
+	for _, r := range after.Registries {
+		afterRegistries[r.Name] = r
+		prev, ok := beforeRegistries[r.Name]
+		if !ok {
+			d.AddedRegistries = append(d.AddedRegistries, r.Name)
+
+			continue
+		}
+		if rd := compareRegistries(prev, r); rd != nil {
+			d.Registries = append(d.Registries, rd)
+		}
+	}
+	for _, r := range before.Registries {
+		if _, ok := afterRegistries[r.Name]; !ok {
+			d.RemovedRegistries = append(d.RemovedRegistries, r.Name)
+		}
+	}
+	sort.Strings(d.AddedRegistries)
+	sort.Strings(d.RemovedRegistries)
+	sort.Slice(d.Registries, func(i, j int) bool { return d.Registries[i].Name < d.Registries[j].Name })
+
+	return d
+}
+
+// IsEmpty reports whether the snapshots have the same inventory.
+func (d *Diff) IsEmpty() bool {
+	return len(d.AddedRegistries) == 0 && len(d.RemovedRegistries) == 0 && len(d.Registries) == 0
+}
+
+func compareRegistries(before, after *Registry) *RegistryDiff {
+	rd := &RegistryDiff{Name: after.Name, SizeBefore: before.Size, SizeAfter: after.Size}
+
+	beforeRepositories := make(map[string]*Repository, len(before.Repositories))
+	for _, repo := range before.Repositories {
+		beforeRepositories[repo.Name] = repo
+	}
+	afterRepositories := make(map[string]struct{}, len(after.Repositories))
+	for _, repo := range after.Repositories {
+		afterRepositories[repo.Name] = struct{}{}
+		prev, ok := beforeRepositories[repo.Name]
+		if !ok {
+			rd.AddedRepositories = append(rd.AddedRepositories, repo.Name)
+
+			continue
+		}
+		if repoDiff := compareRepositories(prev, repo); repoDiff != nil {
+			rd.Repositories = append(rd.Repositories, repoDiff)
+		}
+	}
+	for _, repo := range before.Repositories {
+		if _, ok := afterRepositories[repo.Name]; !ok {
+			rd.RemovedRepositories = append(rd.RemovedRepositories, repo.Name)
+		}
+	}
+	sort.Strings(rd.AddedRepositories)
+	sort.Strings(rd.RemovedRepositories)
+	sort.Slice(rd.Repositories, func(i, j int) bool { return rd.Repositories[i].Name < rd.Repositories[j].Name })
+
+	if rd.SizeBefore == rd.SizeAfter && len(rd.AddedRepositories) == 0 &&
+		len(rd.RemovedRepositories) == 0 && len(rd.Repositories) == 0 {
+		return nil
+	}
+
+	return rd
+}
+
+func compareRepositories(before, after *Repository) *RepositoryDiff {
+	rd := &RepositoryDiff{Name: after.Name, SizeBefore: before.Size, SizeAfter: after.Size}
+
+	beforeDigests, beforeTags := index(before)
+	afterDigests, afterTags := index(after)
+	rd.AddedDigests = missing(afterDigests, beforeDigests)
+	rd.RemovedDigests = missing(beforeDigests, afterDigests)
+	rd.AddedTags = missing(afterTags, beforeTags)
+	rd.RemovedTags = missing(beforeTags, afterTags)
+	for tag, digest := range afterTags {
+		if from, ok := beforeTags[tag]; ok && from != digest {
+			rd.MovedTags = append(rd.MovedTags, &TagMove{Tag: tag, From: from, To: digest})
+		}
+	}
+	sort.Slice(rd.MovedTags, func(i, j int) bool { return rd.MovedTags[i].Tag < rd.MovedTags[j].Tag })
+
+	if rd.SizeBefore == rd.SizeAfter && len(rd.AddedDigests) == 0 && len(rd.RemovedDigests) == 0 &&
+		len(rd.AddedTags) == 0 && len(rd.RemovedTags) == 0 && len(rd.MovedTags) == 0 {
+		return nil
+	}
+
+	return rd
+}
+
+// index returns digests of the repository and digests tags point to.
+func index(repo *Repository) (map[string]string, map[string]string) {
+	digests := make(map[string]string, len(repo.Images))
+	tags := make(map[string]string)
+	for _, image := range repo.Images {
+		digests[image.Digest] = image.Digest
+		for _, tag := range image.Tags {
+			tags[tag] = image.Digest
+		}
+	}
+
+	return digests, tags
+}
+
+// missing returns sorted keys of a that are absent in b.
+func missing(a, b map[string]string) []string {
+	var keys []string
+	for k := range a {
+		if _, ok := b[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// Write renders the diff in a human-readable form.
+func (d *Diff) Write(w io.Writer) error {
+	for _, line := range d.lines() {
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (d *Diff) lines() []string {
+	lines := []string{fmt.Sprintf("Changes from %s to %s",
+		d.From.UTC().Format(time.RFC3339), d.To.UTC().Format(time.RFC3339))}
+	if d.IsEmpty() {
+		return append(lines, "No changes.")
+	}
+	for _, name := range d.AddedRegistries {
+		lines = append(lines, "+ registry "+name)
+	}
+	for _, name := range d.RemovedRegistries {
+		lines = append(lines, "- registry "+name)
+	}
+	for _, rd := range d.Registries {
+		lines = append(lines, "~ registry "+rd.Name+sizeChange(rd.SizeBefore, rd.SizeAfter))
+		for _, name := range rd.AddedRepositories {
+			lines = append(lines, "    + repository "+name)
+		}
+		for _, name := range rd.RemovedRepositories {
+			lines = append(lines, "    - repository "+name)
+		}
+		for _, repo := range rd.Repositories {
+			lines = append(lines, "    ~ repository "+repo.Name+sizeChange(repo.SizeBefore, repo.SizeAfter))
+			for _, digest := range repo.AddedDigests {
+				lines = append(lines, "        + "+digest)
+			}
+			for _, digest := range repo.RemovedDigests {
+				lines = append(lines, "        - "+digest)
+			}
+			for _, tag := range repo.AddedTags {
+				lines = append(lines, "        + tag "+tag)
+			}
+			for _, tag := range repo.RemovedTags {
+				lines = append(lines, "        - tag "+tag)
+			}
+			for _, move := range repo.MovedTags {
+				lines = append(lines, fmt.Sprintf("        ~ tag %s: %s -> %s", move.Tag, move.From, move.To))
+			}
+		}
+	}
+
+	return lines
+}
+
+func sizeChange(before, after int64) string {
+	if before == after {
+		return ""
+	}
+
+	return fmt.Sprintf(" (size %d -> %d, %+d)", before, after, after-before)
+}
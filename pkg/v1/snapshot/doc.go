@@ -0,0 +1,33 @@
+/*
+Package `snapshot` provides a set of functions for capturing a full inventory
+of registries, repositories and images as versioned JSON and comparing
+two inventories.
+
+Example of taking a snapshot and saving it:
+
+	snap, err := snapshot.Take(ctx, client, nil)
+	if err != nil {
+	    log.Fatal(err)
+	}
+	err = snap.Save("release-1.2.json")
+	if err != nil {
+	    log.Fatal(err)
+	}
+
+Example of comparing two snapshots:
+
+	before, err := snapshot.Load("release-1.1.json")
+	if err != nil {
+	    log.Fatal(err)
+	}
+	after, err := snapshot.Load("release-1.2.json")
+	if err != nil {
+	    log.Fatal(err)
+	}
+	diff := snapshot.Compare(before, after)
+	err = diff.Write(os.Stdout)
+	if err != nil {
+	    log.Fatal(err)
+	}
+*/
+package snapshot
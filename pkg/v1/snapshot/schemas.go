@@ -0,0 +1,91 @@
+package snapshot
+
+import (
+	"time"
+
+	"github.com/selectel/craas-go/pkg/v1/registry"
+	"github.com/selectel/craas-go/pkg/v1/repository"
+)
+
+// Version is a version of the snapshot format written by this package.
+const Version = 1
+
+// Snapshot represents an inventory of registries.
+type Snapshot struct {
+	// Version is a version of the snapshot format.
+	Version int `json:"version"`
+
+	// CreatedAt is a time the snapshot was taken.
+	CreatedAt time.Time `json:"createdAt"`
+
+	// Registries are sorted by name.
+	Registries []*Registry `json:"registries"`
+}
+
+// Registry represents a registry with its repositories.
+type Registry struct {
+	ID        string          `json:"id"`
+	Name      string          `json:"name"`
+	Status    registry.Status `json:"status"`
+	Size      int64           `json:"size"`
+	SizeLimit int64           `json:"sizeLimit"`
+
+	// Repositories are sorted by name.
+	Repositories []*Repository `json:"repositories"`
+}
+
+// Repository represents a repository with its images.
+type Repository struct {
+	Name      string    `json:"name"`
+	Size      int64     `json:"size"`
+	UpdatedAt time.Time `json:"updatedAt"`
+
+	// Images are sorted by digest.
+	Images []*repository.Image `json:"images"`
+}
+
+// Diff represents changes between two snapshots.
+type Diff struct {
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
+
+	AddedRegistries   []string `json:"addedRegistries,omitempty"`
+	RemovedRegistries []string `json:"removedRegistries,omitempty"`
+
+	// Registries contains changes of registries present in both snapshots.
+	Registries []*RegistryDiff `json:"registries,omitempty"`
+}
+
+// RegistryDiff represents changes of a registry.
+type RegistryDiff struct {
+	Name       string `json:"name"`
+	SizeBefore int64  `json:"sizeBefore"`
+	SizeAfter  int64  `json:"sizeAfter"`
+
+	AddedRepositories   []string `json:"addedRepositories,omitempty"`
+	RemovedRepositories []string `json:"removedRepositories,omitempty"`
+
+	// Repositories contains changes of repositories present in both snapshots.
+	Repositories []*RepositoryDiff `json:"repositories,omitempty"`
+}
+
+// RepositoryDiff represents changes of a repository.
+type RepositoryDiff struct {
+	Name       string `json:"name"`
+	SizeBefore int64  `json:"sizeBefore"`
+	SizeAfter  int64  `json:"sizeAfter"`
+
+	AddedDigests   []string `json:"addedDigests,omitempty"`
+	RemovedDigests []string `json:"removedDigests,omitempty"`
+
+	AddedTags   []string   `json:"addedTags,omitempty"`
+	RemovedTags []string   `json:"removedTags,omitempty"`
+	MovedTags   []*TagMove `json:"movedTags,omitempty"`
+}
+
+// TagMove represents a tag pointing to another digest.
+type TagMove struct {
+	Tag  string `json:"tag"`
+	From string `json:"from"`
+	To   string `json:"to"`
+}
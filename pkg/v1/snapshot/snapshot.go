@@ -0,0 +1,160 @@
+package snapshot
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/selectel/craas-go/pkg/atomicfile"
+	"github.com/selectel/craas-go/pkg/v1/client"
+	"github.com/selectel/craas-go/pkg/v1/registry"
+	"github.com/selectel/craas-go/pkg/v1/repository"
+)
+
+var ErrUnsupportedVersion = errors.New("unsupported snapshot version")
+
+// TakeOpts represents options of taking a snapshot.
+type TakeOpts struct {
+	// Registries limits the snapshot to registries with these names or IDs,
+	// all registries are captured by default.
+	Registries []string
+
+	// Now is a time of the snapshot, the current time is used if not set.
+	Now time.Time
+}
+
+// Take captures registries, repositories and images.
+func Take(ctx context.Context, client *client.ServiceClient, opts *TakeOpts) (*Snapshot, error) {
+	o := TakeOpts{}
+	if opts != nil {
+		o = *opts
+	}
+	if o.Now.IsZero() {
+		o.Now = time.Now()
+	}
+
+	registries, _, err := registry.List(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+
+	snap := &Snapshot{
+		Version:    Version,
+		CreatedAt:  o.Now.UTC(),
+		Registries: make([]*Registry, 0, len(registries)),
+	}
+	for _, r := range registries {
+		if !selected(o.Registries, r) {
+			continue
+		}
+		reg, err := takeRegistry(ctx, client, r)
+		if err != nil {
+			return nil, fmt.Errorf("registry %s: %w", r.Name, err)
+		}
+		snap.Registries = append(snap.Registries, reg)
+	}
+	sort.Slice(snap.Registries, func(i, j int) bool { return snap.Registries[i].Name < snap.Registries[j].Name })
+
+	return snap, nil
+}
+
+func selected(filter []string, r *registry.Registry) bool {
+	if len(filter) == 0 {
+		return true
+	}
+	for _, v := range filter {
+		if v == r.Name || v == r.ID {
+			return true
+		}
+	}
+
+	return false
+}
+
+func takeRegistry(ctx context.Context, client *client.ServiceClient, r *registry.Registry) (*Registry, error) {
+	repositories, _, err := repository.ListRepositories(ctx, client, r.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	reg := &Registry{
+		ID:           r.ID,
+		Name:         r.Name,
+		Status:       r.Status,
+		Size:         r.Size,
+		SizeLimit:    r.SizeLimit,
+		Repositories: make([]*Repository, 0, len(repositories)),
+	}
+	for _, repo := range repositories {
+		images, _, err := repository.ListImages(ctx, client, r.ID, repo.Name)
+		if err != nil {
+			return nil, fmt.Errorf("repository %s: %w", repo.Name, err)
+		}
+		for _, image := range images {
+			sort.Strings(image.Tags)
+		}
+		sort.Slice(images, func(i, j int) bool { return images[i].Digest < images[j].Digest })
+		reg.Repositories = append(reg.Repositories, &Repository{
+			Name:      repo.Name,
+			Size:      repo.Size,
+			UpdatedAt: repo.UpdatedAt,
+			Images:    images,
+		})
+	}
+	sort.Slice(reg.Repositories, func(i, j int) bool { return reg.Repositories[i].Name < reg.Repositories[j].Name })
+
+	return reg, nil
+}
+
+// Write writes the snapshot as indented JSON.
+func (s *Snapshot) Write(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(s)
+}
+
+// Read reads a snapshot and checks its version.
+func Read(r io.Reader) (*Snapshot, error) {
+	var snap Snapshot
+	if err := json.NewDecoder(r).Decode(&snap); err != nil {
+		return nil, err
+	}
+	if snap.Version != Version {
+		return nil, fmt.Errorf("%w: %d", ErrUnsupportedVersion, snap.Version)
+	}
+
+	return &snap, nil
+}
+
+// Save atomically writes the snapshot to a file.
+func (s *Snapshot) Save(filename string) error {
+	var buf bytes.Buffer
+	if err := s.Write(&buf); err != nil {
+		return err
+	}
+
+	return atomicfile.WriteFile(filename, buf.Bytes(), 0o600)
+}
+
+// Load reads a snapshot from a file.
+func Load(filename string) (*Snapshot, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	snap, err := Read(f)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read %s: %w", filename, err)
+	}
+
+	return snap, nil
+}
@@ -0,0 +1,117 @@
+package testing
+
+import (
+	"time"
+
+	"github.com/selectel/craas-go/pkg/v1/repository"
+	"github.com/selectel/craas-go/pkg/v1/snapshot"
+)
+
+const testRegistryID = "fc43e322-b084-4b3c-a04a-1ab2a28cd860"
+
+const testListRegistriesResponseRaw = `[
+    {
+        "id": "fc43e322-b084-4b3c-a04a-1ab2a28cd860",
+        "name": "test-registry",
+        "createdAt": "2022-10-25T10:25:22.556Z",
+        "status": "ACTIVE",
+        "size": 56723502,
+        "sizeLimit": 21474836480,
+        "used": 0.26
+    },
+    {
+        "id": "0cb4d1cd-5e05-4a9c-9e1c-6bcb3b7ae1a1",
+        "name": "skipped-registry",
+        "createdAt": "2022-10-25T10:25:22.556Z",
+        "status": "ACTIVE",
+        "size": 0,
+        "sizeLimit": 21474836480,
+        "used": 0
+    }
+]`
+
+const testListRepositoriesResponseRaw = `[
+    {
+        "name": "nginx",
+        "size": 56723502,
+        "updatedAt": "2022-09-22T10:15:40.362702Z"
+    }
+]`
+
+const testListImagesResponseRaw = `[
+    {
+        "createdAt": "2022-05-18T22:37:17.011072851Z",
+        "digest": "sha256:bbbb",
+        "layers": [
+            {
+                "digest": "sha256:layer-b",
+                "size": 25338790
+            }
+        ],
+        "size": 25338790,
+        "tags": ["latest", "1.23"]
+    },
+    {
+        "createdAt": "2022-05-17T22:37:17.011072851Z",
+        "digest": "sha256:aaaa",
+        "layers": [
+            {
+                "digest": "sha256:layer-a",
+                "size": 31384712
+            }
+        ],
+        "size": 31384712,
+        "tags": ["1.22"]
+    }
+]`
+
+var testSnapshotTime = time.Date(2023, 3, 1, 12, 0, 0, 0, time.UTC)
+
+var expectedSnapshot = &snapshot.Snapshot{
+	Version:   snapshot.Version,
+	CreatedAt: testSnapshotTime,
+	Registries: []*snapshot.Registry{
+		{
+			ID:        testRegistryID,
+			Name:      "test-registry",
+			Status:    "ACTIVE",
+			Size:      56723502,
+			SizeLimit: 21474836480,
+			Repositories: []*snapshot.Repository{
+				{
+					Name:      "nginx",
+					Size:      56723502,
+					UpdatedAt: time.Date(2022, 9, 22, 10, 15, 40, 362702000, time.UTC),
+					Images: []*repository.Image{
+						{
+							Digest:    "sha256:aaaa",
+							CreatedAt: time.Date(2022, 5, 17, 22, 37, 17, 11072851, time.UTC),
+							Tags:      []string{"1.22"},
+							Size:      31384712,
+							Layers:    []repository.Layer{{Digest: "sha256:layer-a", Size: 31384712}},
+						},
+						{
+							Digest:    "sha256:bbbb",
+							CreatedAt: time.Date(2022, 5, 18, 22, 37, 17, 11072851, time.UTC),
+							Tags:      []string{"1.23", "latest"},
+							Size:      25338790,
+							Layers:    []repository.Layer{{Digest: "sha256:layer-b", Size: 25338790}},
+						},
+					},
+				},
+			},
+		},
+	},
+}
+
+const expectedDiffOutput = `Changes from 2023-03-01T12:00:00Z to 2023-03-02T12:00:00Z
++ registry new-registry
+~ registry test-registry (size 56723502 -> 60000000, +3276498)
+    + repository redis
+    ~ repository nginx (size 56723502 -> 60000000, +3276498)
+        + sha256:cccc
+        - sha256:aaaa
+        + tag 1.24
+        - tag 1.22
+        ~ tag latest: sha256:bbbb -> sha256:cccc
+`
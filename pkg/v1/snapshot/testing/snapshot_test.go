@@ -0,0 +1,136 @@
+package testing
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/selectel/craas-go/pkg/testutils"
+	"github.com/selectel/craas-go/pkg/v1/client"
+	"github.com/selectel/craas-go/pkg/v1/repository"
+	"github.com/selectel/craas-go/pkg/v1/snapshot"
+)
+
+func TestTake(t *testing.T) {
+	registriesCalled, repositoriesCalled, imagesCalled := false, false, false
+	testEnv := testutils.SetupTestEnv()
+	defer testEnv.TearDownTestEnv()
+
+	testutils.HandleReqWithoutBody(t, &testutils.HandleReqOpts{
+		Mux:         testEnv.Mux,
+		URL:         "/api/v1/registries",
+		RawResponse: testListRegistriesResponseRaw,
+		Method:      http.MethodGet,
+		Status:      http.StatusOK,
+		CallFlag:    &registriesCalled,
+	})
+	testutils.HandleReqWithoutBody(t, &testutils.HandleReqOpts{
+		Mux:         testEnv.Mux,
+		URL:         "/api/v1/registries/" + testRegistryID + "/repositories",
+		RawResponse: testListRepositoriesResponseRaw,
+		Method:      http.MethodGet,
+		Status:      http.StatusOK,
+		CallFlag:    &repositoriesCalled,
+	})
+	testutils.HandleReqWithoutBody(t, &testutils.HandleReqOpts{
+		Mux:         testEnv.Mux,
+		URL:         "/api/v1/registries/" + testRegistryID + "/repositories/nginx/images",
+		RawResponse: testListImagesResponseRaw,
+		Method:      http.MethodGet,
+		Status:      http.StatusOK,
+		CallFlag:    &imagesCalled,
+	})
+
+	ctx := context.Background()
+	testClient, err := client.NewCRaaSClientV1(testutils.TokenID, testEnv.Server.URL+"/api/v1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	actual, err := snapshot.Take(ctx, testClient, &snapshot.TakeOpts{
+		Registries: []string{"test-registry"},
+		Now:        testSnapshotTime,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !registriesCalled || !repositoriesCalled || !imagesCalled {
+		t.Fatal("endpoint wasn't called")
+	}
+	if !reflect.DeepEqual(expectedSnapshot, actual) {
+		t.Fatalf("expected %#v, but got %#v", expectedSnapshot, actual)
+	}
+}
+
+func TestSaveLoad(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "snapshot.json")
+	if err := expectedSnapshot.Save(filename); err != nil {
+		t.Fatal(err)
+	}
+	actual, err := snapshot.Load(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(expectedSnapshot, actual) {
+		t.Fatalf("expected %#v, but got %#v", expectedSnapshot, actual)
+	}
+}
+
+func TestReadUnsupportedVersion(t *testing.T) {
+	_, err := snapshot.Read(strings.NewReader(`{"version": 99, "registries": []}`))
+	if !errors.Is(err, snapshot.ErrUnsupportedVersion) {
+		t.Fatalf("expected %v, but got %v", snapshot.ErrUnsupportedVersion, err)
+	}
+}
+
+func TestCompare(t *testing.T) {
+	after := &snapshot.Snapshot{
+		Version:   snapshot.Version,
+		CreatedAt: testSnapshotTime.Add(24 * time.Hour),
+		Registries: []*snapshot.Registry{
+			{Name: "new-registry"},
+			{
+				ID:   testRegistryID,
+				Name: "test-registry",
+				Size: 60000000,
+				Repositories: []*snapshot.Repository{
+					{
+						Name: "nginx",
+						Size: 60000000,
+						Images: []*repository.Image{
+							{Digest: "sha256:bbbb", Tags: []string{"1.23"}},
+							{Digest: "sha256:cccc", Tags: []string{"1.24", "latest"}},
+						},
+					},
+					{Name: "redis"},
+				},
+			},
+		},
+	}
+
+	diff := snapshot.Compare(expectedSnapshot, after)
+	expectedMoves := []*snapshot.TagMove{{Tag: "latest", From: "sha256:bbbb", To: "sha256:cccc"}}
+	if len(diff.Registries) != 1 || len(diff.Registries[0].Repositories) != 1 {
+		t.Fatalf("unexpected diff %#v", diff)
+	}
+	if actual := diff.Registries[0].Repositories[0].MovedTags; !reflect.DeepEqual(expectedMoves, actual) {
+		t.Fatalf("expected %#v, but got %#v", expectedMoves, actual)
+	}
+
+	var buf bytes.Buffer
+	if err := diff.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != expectedDiffOutput {
+		t.Fatalf("expected %q, but got %q", expectedDiffOutput, buf.String())
+	}
+
+	if !snapshot.Compare(expectedSnapshot, expectedSnapshot).IsEmpty() {
+		t.Fatal("expected an empty diff for the same snapshot")
+	}
+}
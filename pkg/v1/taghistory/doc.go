@@ -0,0 +1,43 @@
+/*
+Package `taghistory` provides a set of functions for recording which digests
+repository tags point to over time and querying that history.
+
+A Watcher periodically lists images of repositories and appends changed
+tag mappings to a Store, an append-only file of JSON lines.
+
+Example of watching all repositories of a registry:
+
+	store, err := taghistory.Open("tag-history.jsonl")
+	if err != nil {
+	    log.Fatal(err)
+	}
+	defer store.Close()
+
+	watcher := taghistory.NewWatcher(client, store, &taghistory.WatcherOpts{
+	    Targets:  []taghistory.Target{{RegistryID: registryID}},
+	    Interval: 5 * time.Minute,
+	})
+	err = watcher.Run(ctx)
+	if err != nil {
+	    log.Fatal(err)
+	}
+
+Example of finding the digest a tag pointed to yesterday in a store file
+another process is appending to:
+
+	store, err := taghistory.Load("tag-history.jsonl")
+	if err != nil {
+	    log.Fatal(err)
+	}
+	record, ok := store.At(registryID, "nginx", "latest", time.Now().Add(-24*time.Hour))
+	if ok {
+	    fmt.Println(record.Digest)
+	}
+
+Example of printing the history of a tag:
+
+	for _, record := range store.History(registryID, "nginx", "latest") {
+	    fmt.Println(record.Time, record.Digest)
+	}
+*/
+package taghistory
@@ -0,0 +1,37 @@
+package taghistory
+
+import "time"
+
+// Record represents a tag pointing to a digest since the moment it was seen.
+type Record struct {
+	// Time is a time the mapping was observed.
+	Time time.Time `json:"time"`
+
+	// RegistryID is an ID of the registry.
+	RegistryID string `json:"registryId"`
+
+	// Repository is a name of the repository.
+	Repository string `json:"repository"`
+
+	// Tag is a name of the tag.
+	Tag string `json:"tag"`
+
+	// Digest is a digest of the tagged image.
+	// It's empty if the tag was removed.
+	Digest string `json:"digest"`
+}
+
+// Removed reports whether the record marks a removed tag.
+func (r *Record) Removed() bool {
+	return r.Digest == ""
+}
+
+// Target represents repositories to watch.
+type Target struct {
+	// RegistryID is an ID of the registry.
+	RegistryID string
+
+	// Repository is a name of the repository.
+	// All repositories of the registry are watched if it's empty.
+	Repository string
+}
@@ -0,0 +1,239 @@
+package taghistory
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+var (
+	ErrStoreClosed   = errors.New("tag history store is closed")
+	ErrStoreReadOnly = errors.New("tag history store is read-only")
+)
+
+// tagKey identifies a tag across registries.
+type tagKey struct {
+	registryID string
+	repository string
+	tag        string
+}
+
+// Store keeps tag records in an append-only file of JSON lines and indexes
+// them in memory. It is safe for concurrent use.
+type Store struct {
+	mu     sync.RWMutex
+	file   *os.File
+	closed bool
+
+	// history contains records of every tag in the order they were appended.
+	history map[tagKey][]*Record
+}
+
+// Open opens the store file for appending, creating it if needed, and reads
+// its records. An incomplete last line left by an interrupted write is
+// discarded. Records are always written at the end of the file, so records of
+// other writers are never overwritten.
+func Open(filename string) (*Store, error) {
+	file, err := os.OpenFile(filename, os.O_RDWR|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Store{file: file, history: make(map[tagKey][]*Record)}
+	size, err := s.load(file)
+	if err == nil {
+		err = file.Truncate(size)
+	}
+	if err != nil {
+		file.Close()
+
+		return nil, fmt.Errorf("unable to read %s: %w", filename, err)
+	}
+
+	return s, nil
+}
+
+// Load reads records of the store file for queries. It neither creates nor
+// modifies the file, and an incomplete last line is ignored, so it is safe to
+// use while a watcher is appending to the file. Append of the returned store
+// fails with ErrStoreReadOnly.
+func Load(filename string) (*Store, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	s := &Store{history: make(map[tagKey][]*Record)}
+	if _, err := s.load(file); err != nil {
+		return nil, fmt.Errorf("unable to read %s: %w", filename, err)
+	}
+
+	return s, nil
+}
+
+// load reads complete lines and returns their total size.
+func (s *Store) load(r io.Reader) (int64, error) {
+	reader := bufio.NewReader(r)
+	var size int64
+	for line := 1; ; line++ {
+		data, err := reader.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
+			return size, nil
+		}
+		if err != nil {
+			return 0, err
+		}
+		size += int64(len(data))
+
+		data = bytes.TrimSpace(data)
+		if len(data) == 0 {
+			continue
+		}
+		record := &Record{}
+		if err := json.Unmarshal(data, record); err != nil {
+			return 0, fmt.Errorf("line %d: %w", line, err)
+		}
+		s.index(record)
+	}
+}
+
+func (s *Store) index(record *Record) {
+	key := tagKey{record.RegistryID, record.Repository, record.Tag}
+	s.history[key] = append(s.history[key], record)
+}
+
+// Append writes records to the file and syncs it.
+func (s *Store) Append(records ...*Record) error {
+	if len(records) == 0 {
+		return nil
+	}
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	for _, record := range records {
+		if err := encoder.Encode(record); err != nil {
+			return err
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return ErrStoreClosed
+	}
+	if s.file == nil {
+		return ErrStoreReadOnly
+	}
+	if _, err := s.file.Write(buf.Bytes()); err != nil {
+		return err
+	}
+	if err := s.file.Sync(); err != nil {
+		return err
+	}
+	for _, record := range records {
+		s.index(record)
+	}
+
+	return nil
+}
+
+// Current returns digests the tags of the repository point to according to
+// the latest records. Removed tags are omitted.
+func (s *Store) Current(registryID, repository string) map[string]string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	current := make(map[string]string)
+	for key, records := range s.history {
+		if key.registryID != registryID || key.repository != repository {
+			continue
+		}
+		if last := latest(records, time.Time{}); last != nil && !last.Removed() {
+			current[key.tag] = last.Digest
+		}
+	}
+
+	return current
+}
+
+// At returns the record of the digest the tag pointed to at the given time.
+// It returns false if the tag wasn't seen yet or was removed at that time.
+func (s *Store) At(registryID, repository, tag string, t time.Time) (*Record, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	record := latest(s.history[tagKey{registryID, repository, tag}], t)
+	if record == nil || record.Removed() {
+		return nil, false
+	}
+
+	return record, true
+}
+
+// History returns records of the tag sorted by time.
+func (s *Store) History(registryID, repository, tag string) []*Record {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	records := append([]*Record(nil), s.history[tagKey{registryID, repository, tag}]...)
+	sort.SliceStable(records, func(i, j int) bool { return records[i].Time.Before(records[j].Time) })
+
+	return records
+}
+
+// Tags returns sorted names of all tags ever seen in the repository.
+func (s *Store) Tags(registryID, repository string) []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var tags []string
+	for key := range s.history {
+		if key.registryID == registryID && key.repository == repository {
+			tags = append(tags, key.tag)
+		}
+	}
+	sort.Strings(tags)
+
+	return tags
+}
+
+// Close closes the store file.
+func (s *Store) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return ErrStoreClosed
+	}
+	s.closed = true
+	if s.file == nil {
+		return nil
+	}
+
+	return s.file.Close()
+}
+
+// latest returns the last record observed not after t.
+// All records are considered if t is zero.
+func latest(records []*Record, t time.Time) *Record {
+	var result *Record
+	for _, record := range records {
+		if !t.IsZero() && record.Time.After(t) {
+			continue
+		}
+		if result == nil || !record.Time.Before(result.Time) {
+			result = record
+		}
+	}
+
+	return result
+}
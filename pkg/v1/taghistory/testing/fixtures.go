@@ -0,0 +1,45 @@
+package testing
+
+import (
+	"time"
+
+	"github.com/selectel/craas-go/pkg/v1/taghistory"
+)
+
+const testRegistryID = "fc43e322-b084-4b3c-a04a-1ab2a28cd860"
+
+const testListImagesResponseRaw = `[
+    {
+        "createdAt": "2022-05-18T22:37:17.011072851Z",
+        "digest": "sha256:bbbb",
+        "layers": [],
+        "size": 25338790,
+        "tags": ["latest", "1.23"]
+    },
+    {
+        "createdAt": "2022-05-17T22:37:17.011072851Z",
+        "digest": "sha256:aaaa",
+        "layers": [],
+        "size": 31384712,
+        "tags": ["1.22"]
+    }
+]`
+
+var (
+	testFirstPoll  = time.Date(2023, 3, 1, 12, 0, 0, 0, time.UTC)
+	testSecondPoll = time.Date(2023, 3, 2, 12, 0, 0, 0, time.UTC)
+)
+
+// testInitialRecords are records of the first poll.
+var testInitialRecords = []*taghistory.Record{
+	{Time: testFirstPoll, RegistryID: testRegistryID, Repository: "nginx", Tag: "1.21", Digest: "sha256:0000"},
+	{Time: testFirstPoll, RegistryID: testRegistryID, Repository: "nginx", Tag: "1.22", Digest: "sha256:aaaa"},
+	{Time: testFirstPoll, RegistryID: testRegistryID, Repository: "nginx", Tag: "latest", Digest: "sha256:aaaa"},
+}
+
+// expectedPollRecords are records of the second poll.
+var expectedPollRecords = []*taghistory.Record{
+	{Time: testSecondPoll, RegistryID: testRegistryID, Repository: "nginx", Tag: "1.21"},
+	{Time: testSecondPoll, RegistryID: testRegistryID, Repository: "nginx", Tag: "1.23", Digest: "sha256:bbbb"},
+	{Time: testSecondPoll, RegistryID: testRegistryID, Repository: "nginx", Tag: "latest", Digest: "sha256:bbbb"},
+}
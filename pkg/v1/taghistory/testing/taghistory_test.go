@@ -0,0 +1,189 @@
+package testing
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/selectel/craas-go/pkg/testutils"
+	"github.com/selectel/craas-go/pkg/v1/client"
+	"github.com/selectel/craas-go/pkg/v1/taghistory"
+)
+
+func openTestStore(t *testing.T, filename string) *taghistory.Store {
+	t.Helper()
+
+	store, err := taghistory.Open(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { store.Close() })
+
+	return store
+}
+
+func TestStoreReopen(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "history.jsonl")
+	store := openTestStore(t, filename)
+	if err := store.Append(testInitialRecords...); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Simulate a write interrupted in the middle of a line.
+	f, err := os.OpenFile(filename, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.WriteString(`{"time": "2023-03-0`); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	store = openTestStore(t, filename)
+	if err := store.Append(expectedPollRecords[0]); err != nil {
+		t.Fatal(err)
+	}
+	expected := []*taghistory.Record{testInitialRecords[0], expectedPollRecords[0]}
+	if actual := store.History(testRegistryID, "nginx", "1.21"); !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("expected %#v, but got %#v", expected, actual)
+	}
+}
+
+func TestStoreAppendTwoWriters(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "history.jsonl")
+	first := openTestStore(t, filename)
+	second := openTestStore(t, filename)
+	if err := first.Append(testInitialRecords[0]); err != nil {
+		t.Fatal(err)
+	}
+	if err := second.Append(expectedPollRecords[0]); err != nil {
+		t.Fatal(err)
+	}
+
+	store := openTestStore(t, filename)
+	expected := []*taghistory.Record{testInitialRecords[0], expectedPollRecords[0]}
+	if actual := store.History(testRegistryID, "nginx", "1.21"); !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("expected %#v, but got %#v", expected, actual)
+	}
+}
+
+func TestStoreLoad(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "history.jsonl")
+	if _, err := taghistory.Load(filename); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("expected %#v, but got %#v", os.ErrNotExist, err)
+	}
+	if _, err := os.Stat(filename); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("expected the store file not to be created, but got %v", err)
+	}
+
+	store := openTestStore(t, filename)
+	if err := store.Append(testInitialRecords...); err != nil {
+		t.Fatal(err)
+	}
+	// Simulate a record another writer is still writing.
+	const partial = `{"time": "2023-03-0`
+	f, err := os.OpenFile(filename, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.WriteString(partial); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	before, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := taghistory.Load(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer loaded.Close()
+	expected := []*taghistory.Record{testInitialRecords[0]}
+	if actual := loaded.History(testRegistryID, "nginx", "1.21"); !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("expected %#v, but got %#v", expected, actual)
+	}
+	if err := loaded.Append(expectedPollRecords[0]); !errors.Is(err, taghistory.ErrStoreReadOnly) {
+		t.Fatalf("expected %#v, but got %#v", taghistory.ErrStoreReadOnly, err)
+	}
+	after, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(after) != string(before) {
+		t.Fatalf("expected the store file to be left as is, but got %q", after)
+	}
+}
+
+func TestWatcherPoll(t *testing.T) {
+	endpointCalled := false
+	testEnv := testutils.SetupTestEnv()
+	defer testEnv.TearDownTestEnv()
+
+	testutils.HandleReqWithoutBody(t, &testutils.HandleReqOpts{
+		Mux:         testEnv.Mux,
+		URL:         "/api/v1/registries/" + testRegistryID + "/repositories/nginx/images",
+		RawResponse: testListImagesResponseRaw,
+		Method:      http.MethodGet,
+		Status:      http.StatusOK,
+		CallFlag:    &endpointCalled,
+	})
+
+	store := openTestStore(t, filepath.Join(t.TempDir(), "history.jsonl"))
+	if err := store.Append(testInitialRecords...); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	testClient, err := client.NewCRaaSClientV1(testutils.TokenID, testEnv.Server.URL+"/api/v1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	watcher := taghistory.NewWatcher(testClient, store, &taghistory.WatcherOpts{
+		Targets: []taghistory.Target{{RegistryID: testRegistryID, Repository: "nginx"}},
+		Now:     func() time.Time { return testSecondPoll },
+	})
+	actual, err := watcher.Poll(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !endpointCalled {
+		t.Fatal("endpoint wasn't called")
+	}
+	if !reflect.DeepEqual(expectedPollRecords, actual) {
+		t.Fatalf("expected %#v, but got %#v", expectedPollRecords, actual)
+	}
+
+	record, ok := store.At(testRegistryID, "nginx", "latest", testSecondPoll.Add(-time.Hour))
+	if !ok || record.Digest != "sha256:aaaa" {
+		t.Fatalf("expected latest to point to sha256:aaaa before the poll, but got %#v", record)
+	}
+	record, ok = store.At(testRegistryID, "nginx", "latest", testSecondPoll)
+	if !ok || record.Digest != "sha256:bbbb" {
+		t.Fatalf("expected latest to point to sha256:bbbb after the poll, but got %#v", record)
+	}
+	if _, ok := store.At(testRegistryID, "nginx", "1.21", testSecondPoll); ok {
+		t.Fatal("expected the removed tag to be absent")
+	}
+	if _, ok := store.At(testRegistryID, "nginx", "latest", testFirstPoll.Add(-time.Hour)); ok {
+		t.Fatal("expected the tag to be absent before it was seen")
+	}
+
+	// Nothing changed since the last poll.
+	actual, err = watcher.Poll(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(actual) != 0 {
+		t.Fatalf("expected no records, but got %#v", actual)
+	}
+}
@@ -0,0 +1,173 @@
+package taghistory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/selectel/craas-go/pkg/v1/client"
+	"github.com/selectel/craas-go/pkg/v1/registry"
+	"github.com/selectel/craas-go/pkg/v1/repository"
+)
+
+// DefaultInterval is a delay between polls of the Watcher.
+const DefaultInterval = 5 * time.Minute
+
+// WatcherOpts represents options of the Watcher.
+type WatcherOpts struct {
+	// Targets are repositories to watch.
+	// All repositories of all registries are watched if not set.
+	Targets []Target
+
+	// Interval is a delay between polls. DefaultInterval is used if not set.
+	Interval time.Duration
+
+	// OnRecords is called with records appended by every poll.
+	OnRecords func(records []*Record)
+
+	// OnError is called for every failed poll.
+	OnError func(err error)
+
+	// Now returns the current time, time.Now is used by default.
+	Now func() time.Time
+}
+
+// Watcher records tag mappings of repositories into a Store.
+type Watcher struct {
+	client *client.ServiceClient
+	store  *Store
+	opts   WatcherOpts
+}
+
+// NewWatcher returns a watcher using the v1 client.
+func NewWatcher(client *client.ServiceClient, store *Store, opts *WatcherOpts) *Watcher {
+	w := &Watcher{client: client, store: store}
+	if opts != nil {
+		w.opts = *opts
+	}
+	if w.opts.Interval <= 0 {
+		w.opts.Interval = DefaultInterval
+	}
+	if w.opts.Now == nil {
+		w.opts.Now = time.Now
+	}
+
+	return w
+}
+
+// Run polls repositories until the context is canceled.
+// Failed polls are reported to OnError and retried after the interval.
+func (w *Watcher) Run(ctx context.Context) error {
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-timer.C:
+		}
+
+		records, err := w.Poll(ctx)
+		if err != nil && ctx.Err() == nil && w.opts.OnError != nil {
+			w.opts.OnError(err)
+		}
+		if len(records) > 0 && w.opts.OnRecords != nil {
+			w.opts.OnRecords(records)
+		}
+		timer.Reset(w.opts.Interval)
+	}
+}
+
+// Poll lists images of watched repositories once and appends records of
+// new, moved and removed tags. Repositories that can't be listed are skipped
+// and the first error is returned along with the appended records.
+func (w *Watcher) Poll(ctx context.Context) ([]*Record, error) {
+	targets, err := w.targets(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var records []*Record
+	var firstErr error
+	for _, target := range targets {
+		images, _, err := repository.ListImages(ctx, w.client, target.RegistryID, target.Repository)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = fmt.Errorf("repository %s: %w", target.Repository, err)
+			}
+
+			continue
+		}
+		records = append(records, w.changes(target, images)...)
+	}
+	if err := w.store.Append(records...); err != nil {
+		return nil, err
+	}
+
+	return records, firstErr
+}
+
+// targets returns repositories with resolved names.
+func (w *Watcher) targets(ctx context.Context) ([]Target, error) {
+	targets := w.opts.Targets
+	if len(targets) == 0 {
+		registries, _, err := registry.List(ctx, w.client)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range registries {
+			targets = append(targets, Target{RegistryID: r.ID})
+		}
+	}
+
+	resolved := make([]Target, 0, len(targets))
+	for _, target := range targets {
+		if target.Repository != "" {
+			resolved = append(resolved, target)
+
+			continue
+		}
+		repositories, _, err := repository.ListRepositories(ctx, w.client, target.RegistryID)
+		if err != nil {
+			return nil, fmt.Errorf("registry %s: %w", target.RegistryID, err)
+		}
+		for _, repo := range repositories {
+			resolved = append(resolved, Target{RegistryID: target.RegistryID, Repository: repo.Name})
+		}
+	}
+
+	return resolved, nil
+}
+
+// changes compares listed images with the latest records of the repository.
+func (w *Watcher) changes(target Target, images []*repository.Image) []*Record {
+	now := w.opts.Now().UTC()
+	observed := make(map[string]string)
+	for _, image := range images {
+		for _, tag := range image.Tags {
+			observed[tag] = image.Digest
+		}
+	}
+	current := w.store.Current(target.RegistryID, target.Repository)
+
+	var records []*Record
+	for tag, digest := range observed {
+		if current[tag] != digest {
+			records = append(records, &Record{
+				Time: now, RegistryID: target.RegistryID, Repository: target.Repository, Tag: tag, Digest: digest,
+			})
+		}
+	}
+	for tag := range current {
+		if _, ok := observed[tag]; !ok {
+			records = append(records, &Record{
+				Time: now, RegistryID: target.RegistryID, Repository: target.Repository, Tag: tag,
+			})
+		}
+	}
+	sort.Slice(records, func(i, j int) bool { return records[i].Tag < records[j].Tag })
+
+	return records
+}
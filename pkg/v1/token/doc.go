@@ -33,5 +33,22 @@ Example of refreshing a token by its ID:
 	if err != nil {
 	    log.Fatal(err)
 	}
+
+Example of keeping a token refreshed in the background:
+
+	keeper, err := token.NewKeeper(ctx, client, &token.KeeperOpts{
+	    CreateOpts: &token.CreateOpts{TokenTTL: token.TTL12Hours},
+	})
+	if err != nil {
+	    log.Fatal(err)
+	}
+	defer keeper.Close(ctx)
+
+	go func() {
+	    for refreshed := range keeper.Subscribe() {
+	        fmt.Printf("Token expires at: %s", refreshed.ExpirationTime())
+	    }
+	}()
+	fmt.Printf("Current token: %s", keeper.Token().Token)
 */
 package token
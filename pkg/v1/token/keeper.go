@@ -0,0 +1,213 @@
+package token
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/selectel/craas-go/pkg/v1/client"
+)
+
+const (
+	// DefaultRefreshFraction is a part of the token lifetime after which
+	// the Keeper refreshes the token.
+	DefaultRefreshFraction = 0.8
+
+	// DefaultRetryInterval is a delay between refresh attempts after a failure.
+	DefaultRetryInterval = 30 * time.Second
+)
+
+var (
+	ErrKeeperClosed           = errors.New("token keeper is closed")
+	ErrInvalidRefreshFraction = errors.New("refresh fraction must be in the (0, 1) range")
+)
+
+// KeeperOpts represents options for the token Keeper.
+type KeeperOpts struct {
+	// CreateOpts are used to create the kept token.
+	// The token is created with the 12 hours TTL if not set.
+	CreateOpts *CreateOpts
+
+	// RefreshFraction is a part of the token lifetime after which the token
+	// is refreshed. DefaultRefreshFraction is used if not set.
+	RefreshFraction float64
+
+	// RetryInterval is a delay between refresh attempts after a failure.
+	// DefaultRetryInterval is used if not set.
+	RetryInterval time.Duration
+
+	// OnError is called for every failed refresh attempt.
+	OnError func(err error)
+}
+
+// Keeper holds a token created by Create and refreshes it in the background
+// before it expires. It is safe for concurrent use.
+type Keeper struct {
+	client *client.ServiceClient
+	opts   KeeperOpts
+
+	mu          sync.RWMutex
+	token       Token
+	refreshedAt time.Time
+	subscribers []chan Token
+	closed      bool
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewKeeper creates a new token and starts refreshing it in the background.
+// Keeper must be closed with Close to stop refreshing and revoke the token.
+func NewKeeper(ctx context.Context, client *client.ServiceClient, opts *KeeperOpts) (*Keeper, error) {
+	if opts == nil {
+		opts = &KeeperOpts{}
+	}
+	keeperOpts := *opts
+	if keeperOpts.RefreshFraction == 0 {
+		keeperOpts.RefreshFraction = DefaultRefreshFraction
+	}
+	if keeperOpts.RefreshFraction <= 0 || keeperOpts.RefreshFraction >= 1 {
+		return nil, ErrInvalidRefreshFraction
+	}
+	if keeperOpts.RetryInterval <= 0 {
+		keeperOpts.RetryInterval = DefaultRetryInterval
+	}
+
+	token, _, err := Create(ctx, client, keeperOpts.CreateOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	loopCtx, cancel := context.WithCancel(context.Background())
+	keeper := &Keeper{
+		client:      client,
+		opts:        keeperOpts,
+		token:       *token,
+		refreshedAt: time.Now(),
+		cancel:      cancel,
+		done:        make(chan struct{}),
+	}
+	go keeper.loop(loopCtx)
+
+	return keeper, nil
+}
+
+// Token returns a copy of the current token.
+func (k *Keeper) Token() Token {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	return k.token
+}
+
+// Subscribe returns a channel that receives the token after every refresh.
+// The channel keeps only the latest token, so slow readers skip intermediate
+// values. The channel is closed when the Keeper is closed.
+func (k *Keeper) Subscribe() <-chan Token {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	ch := make(chan Token, 1)
+	if k.closed {
+		close(ch)
+
+		return ch
+	}
+	k.subscribers = append(k.subscribers, ch)
+
+	return ch
+}
+
+// Close stops refreshing, closes subscriber channels and revokes the token.
+func (k *Keeper) Close(ctx context.Context) error {
+	k.mu.Lock()
+	if k.closed {
+		k.mu.Unlock()
+
+		return ErrKeeperClosed
+	}
+	k.closed = true
+	k.mu.Unlock()
+
+	k.cancel()
+	<-k.done
+
+	k.mu.Lock()
+	for _, ch := range k.subscribers {
+		close(ch)
+	}
+	k.subscribers = nil
+	tokenID := k.token.Token
+	k.mu.Unlock()
+
+	_, err := Revoke(ctx, k.client, tokenID)
+
+	return err
+}
+
+// loop refreshes the token until the context is canceled.
+func (k *Keeper) loop(ctx context.Context) {
+	defer close(k.done)
+
+	timer := time.NewTimer(k.nextRefresh())
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+
+		token, _, err := Refresh(ctx, k.client, k.Token().Token)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			if k.opts.OnError != nil {
+				k.opts.OnError(err)
+			}
+			timer.Reset(k.opts.RetryInterval)
+
+			continue
+		}
+
+		k.update(*token)
+		timer.Reset(k.nextRefresh())
+	}
+}
+
+// update stores the refreshed token and notifies subscribers.
+func (k *Keeper) update(token Token) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	k.token = token
+	k.refreshedAt = time.Now()
+	for _, ch := range k.subscribers {
+		// Drop a value that hasn't been read yet to keep only the latest one.
+		select {
+		case <-ch:
+		default:
+		}
+		ch <- token
+	}
+}
+
+// nextRefresh returns a delay before the next token refresh.
+func (k *Keeper) nextRefresh() time.Duration {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	lifetime := k.token.Lifetime()
+	if lifetime <= 0 {
+		lifetime = time.Until(k.token.ExpirationTime())
+	}
+	delay := time.Duration(float64(lifetime)*k.opts.RefreshFraction) - time.Since(k.refreshedAt)
+	if delay < 0 {
+		return 0
+	}
+
+	return delay
+}
@@ -1,5 +1,7 @@
 package token
 
+import "time"
+
 // Token represents  an unmarshalled token body from an API response.
 type Token struct {
 	// Token is a token string.
@@ -11,3 +13,22 @@ type Token struct {
 	// ExpiresIn is a token expiration time in seconds.
 	ExpiresIn int64 `json:"expireIn"`
 }
+
+// ExpirationTime returns the token expiration time.
+func (t *Token) ExpirationTime() time.Time {
+	return time.Unix(t.ExpiresAt, 0)
+}
+
+// Lifetime returns the token lifetime reported by the API.
+func (t *Token) Lifetime() time.Duration {
+	return time.Duration(t.ExpiresIn) * time.Second
+}
+
+// RegistryUsername is a username used to log in to a registry with a token.
+const RegistryUsername = "token"
+
+// RegistryCredentials returns username and password to log in to a registry
+// with the token.
+func (t *Token) RegistryCredentials() (username, password string) {
+	return RegistryUsername, t.Token
+}
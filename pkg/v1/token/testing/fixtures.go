@@ -41,3 +41,30 @@ var expectedRefreshTokenResponse = &token.Token{
 	ExpiresAt: 1666649999,
 	ExpiresIn: 43200,
 }
+
+// testCreateShortTokenResponseRaw represents a raw response for a token
+// that expires in one second.
+const testCreateShortTokenResponseRaw = `{
+    "expireAt": 1666644533,
+    "expireIn": 1,
+    "token": "CRgAAAAAWiMnwN63eyASwQk8a3DBPRPirT9fWQTx"
+}`
+
+var expectedCreateShortTokenResponse = token.Token{
+	Token:     testTokenID,
+	ExpiresAt: 1666644533,
+	ExpiresIn: 1,
+}
+
+// testRefreshShortTokenResponseRaw represents a raw refresh response for
+// a token that expires in one second.
+const testRefreshShortTokenResponseRaw = `{
+    "expireAt": 1666644534,
+    "expireIn": 1
+}`
+
+var expectedRefreshShortTokenResponse = token.Token{
+	Token:     testTokenID,
+	ExpiresAt: 1666644534,
+	ExpiresIn: 1,
+}
@@ -0,0 +1,102 @@
+package testing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/selectel/craas-go/pkg/testutils"
+	"github.com/selectel/craas-go/pkg/v1/client"
+	"github.com/selectel/craas-go/pkg/v1/token"
+)
+
+func TestKeeper(t *testing.T) {
+	var refreshCalls, revokeCalls int32
+	testEnv := testutils.SetupTestEnv()
+	defer testEnv.TearDownTestEnv()
+
+	testEnv.Mux.HandleFunc("/api/v1/token", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Errorf("expected %s method but got %s", http.MethodPost, r.Method)
+		}
+		w.Header().Add("Content-Type", "application/json")
+		fmt.Fprint(w, testCreateShortTokenResponseRaw)
+	})
+	testEnv.Mux.HandleFunc("/api/v1/token/"+testTokenID+"/refresh", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Errorf("expected %s method but got %s", http.MethodPost, r.Method)
+		}
+		atomic.AddInt32(&refreshCalls, 1)
+		w.Header().Add("Content-Type", "application/json")
+		fmt.Fprint(w, testRefreshShortTokenResponseRaw)
+	})
+	testEnv.Mux.HandleFunc("/api/v1/token/"+testTokenID, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodDelete {
+			t.Errorf("expected %s method but got %s", http.MethodDelete, r.Method)
+		}
+		atomic.AddInt32(&revokeCalls, 1)
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	ctx := context.Background()
+	testClient, err := client.NewCRaaSClientV1(testutils.TokenID, testEnv.Server.URL+"/api/v1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	keeper, err := token.NewKeeper(ctx, testClient, &token.KeeperOpts{
+		RefreshFraction: 0.05,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(expectedCreateShortTokenResponse, keeper.Token()) {
+		t.Fatalf("expected %#v, but got %#v", expectedCreateShortTokenResponse, keeper.Token())
+	}
+
+	updates := keeper.Subscribe()
+	select {
+	case actual := <-updates:
+		if !reflect.DeepEqual(expectedRefreshShortTokenResponse, actual) {
+			t.Fatalf("expected %#v, but got %#v", expectedRefreshShortTokenResponse, actual)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("token wasn't refreshed")
+	}
+	if !reflect.DeepEqual(expectedRefreshShortTokenResponse, keeper.Token()) {
+		t.Fatalf("expected %#v, but got %#v", expectedRefreshShortTokenResponse, keeper.Token())
+	}
+
+	if err := keeper.Close(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := <-updates; ok {
+		t.Fatal("expected subscriber channel to be closed")
+	}
+	if atomic.LoadInt32(&refreshCalls) == 0 {
+		t.Fatal("refresh endpoint wasn't called")
+	}
+	if atomic.LoadInt32(&revokeCalls) != 1 {
+		t.Fatalf("expected revoke endpoint to be called once, but got %d", revokeCalls)
+	}
+	if err := keeper.Close(ctx); !errors.Is(err, token.ErrKeeperClosed) {
+		t.Fatalf("expected %v error, but got %v", token.ErrKeeperClosed, err)
+	}
+}
+
+func TestKeeperInvalidRefreshFraction(t *testing.T) {
+	testClient, err := client.NewCRaaSClientV1(testutils.TokenID, "http://localhost/api/v1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = token.NewKeeper(context.Background(), testClient, &token.KeeperOpts{
+		RefreshFraction: 1.5,
+	})
+	if !errors.Is(err, token.ErrInvalidRefreshFraction) {
+		t.Fatalf("expected %v error, but got %v", token.ErrInvalidRefreshFraction, err)
+	}
+}
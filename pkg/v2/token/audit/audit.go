@@ -0,0 +1,157 @@
+package audit
+
+import (
+	"context"
+	"math"
+	"time"
+
+	clientv1 "github.com/selectel/craas-go/pkg/v1/client"
+	"github.com/selectel/craas-go/pkg/v1/registry"
+	clientv2 "github.com/selectel/craas-go/pkg/v2/client"
+	tokenv2 "github.com/selectel/craas-go/pkg/v2/token"
+)
+
+const (
+	// DefaultUnusedDays is a default number of days a token is considered unused after.
+	DefaultUnusedDays = 90
+
+	// DefaultExpiringDays is a default number of days a token is considered expiring within.
+	DefaultExpiringDays = 14
+
+	day = 24 * time.Hour
+)
+
+// Opts represents options of a tokens audit.
+type Opts struct {
+	// UnusedDays is a number of days without usage a token is reported
+	// after, DefaultUnusedDays is used if not set.
+	UnusedDays int
+
+	// ExpiringDays is a number of days before expiration a token is reported
+	// within, DefaultExpiringDays is used if not set.
+	ExpiringDays int
+
+	// PageSize is a number of tokens requested per page,
+	// tokenv2.DefaultListPageSize is used if not set.
+	PageSize int
+
+	// Now is a reference time of the audit, the current time is used if not set.
+	Now time.Time
+}
+
+// Run pages through all tokens and returns the audit report.
+// The v1 client is optional, registries existence isn't checked without it.
+func Run(ctx context.Context, clientV2 *clientv2.ServiceClient, clientV1 *clientv1.ServiceClient, opts *Opts) (*Report, error) {
+	o := withDefaults(opts)
+
+	pageSize := o.PageSize
+	tokens, err := tokenv2.ListAll(ctx, clientV2, tokenv2.Opts{Limit: &pageSize})
+	if err != nil {
+		return nil, err
+	}
+
+	var registryIDs map[string]struct{}
+	if clientV1 != nil {
+		registries, _, err := registry.List(ctx, clientV1)
+		if err != nil {
+			return nil, err
+		}
+		registryIDs = make(map[string]struct{}, len(registries))
+		for _, r := range registries {
+			registryIDs[r.ID] = struct{}{}
+		}
+	}
+
+	return Evaluate(tokens, registryIDs, &o), nil
+}
+
+// Evaluate builds the audit report for the tokens.
+// If registryIDs is nil, registries existence isn't checked.
+func Evaluate(tokens []tokenv2.TokenV2, registryIDs map[string]struct{}, opts *Opts) *Report {
+	o := withDefaults(opts)
+	report := &Report{
+		GeneratedAt: o.Now,
+		TotalTokens: len(tokens),
+		Entries:     make([]*Entry, 0),
+	}
+	for _, tkn := range tokens {
+		entry := evaluateToken(tkn, registryIDs, o)
+		if len(entry.Findings) != 0 {
+			report.Entries = append(report.Entries, entry)
+		}
+	}
+
+	return report
+}
+
+// evaluateToken returns findings of a single token.
+func evaluateToken(tkn tokenv2.TokenV2, registryIDs map[string]struct{}, o Opts) *Entry {
+	// Secrets are never a part of the report.
+	tkn.Token = ""
+	entry := &Entry{
+		Token:    tkn,
+		Findings: make([]Finding, 0),
+	}
+
+	lastUsed := tkn.LastUsedAt
+	if lastUsed == nil || lastUsed.IsZero() {
+		entry.Findings = append(entry.Findings, FindingNeverUsed)
+		lastUsed = tkn.CreatedAt
+	}
+	if lastUsed != nil {
+		entry.UnusedDays = daysBetween(*lastUsed, o.Now)
+		if entry.UnusedDays >= o.UnusedDays && !entry.Has(FindingNeverUsed) {
+			entry.Findings = append(entry.Findings, FindingUnused)
+		}
+	}
+
+	if tkn.Expiration.IsSet {
+		left := daysBetween(o.Now, tkn.Expiration.ExpiresAt)
+		entry.ExpiresInDays = &left
+		switch {
+		case !tkn.Expiration.ExpiresAt.After(o.Now):
+			entry.Findings = append(entry.Findings, FindingExpired)
+		case left <= o.ExpiringDays:
+			entry.Findings = append(entry.Findings, FindingExpiring)
+		}
+	} else if tkn.Scope.ModeRW {
+		entry.Findings = append(entry.Findings, FindingNonExpiringRW)
+	}
+
+	if registryIDs != nil && !tkn.Scope.AllRegistries {
+		for _, id := range tkn.Scope.RegistryIDs {
+			if _, ok := registryIDs[id]; !ok {
+				entry.MissingRegistryIDs = append(entry.MissingRegistryIDs, id)
+			}
+		}
+		if len(entry.MissingRegistryIDs) != 0 {
+			entry.Findings = append(entry.Findings, FindingMissingRegistry)
+		}
+	}
+
+	return entry
+}
+
+// withDefaults returns a copy of the options with defaults applied.
+func withDefaults(opts *Opts) Opts {
+	var o Opts
+	if opts != nil {
+		o = *opts
+	}
+	if o.UnusedDays <= 0 {
+		o.UnusedDays = DefaultUnusedDays
+	}
+	if o.ExpiringDays <= 0 {
+		o.ExpiringDays = DefaultExpiringDays
+	}
+	if o.Now.IsZero() {
+		o.Now = time.Now()
+	}
+
+	return o
+}
+
+// daysBetween returns a number of whole days between two times.
+func daysBetween(from, to time.Time) int {
+	return int(math.Floor(to.Sub(from).Hours() / day.Hours()))
+}
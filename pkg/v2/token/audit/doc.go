@@ -0,0 +1,22 @@
+/*
+Package `audit` provides a report of stale, expiring and risky CRaaS v2 tokens.
+
+The report pages through all tokens and flags tokens that were never used,
+weren't used for a number of days, expire soon, never expire while having
+read-write access, or are scoped to registries that no longer exist.
+
+Example of printing an audit report as a table:
+
+	report, err := audit.Run(ctx, clientV2, clientV1, &audit.Opts{
+	    UnusedDays:   90,
+	    ExpiringDays: 14,
+	})
+	if err != nil {
+	    log.Fatal(err)
+	}
+	err = report.Write(os.Stdout, audit.FormatTable)
+	if err != nil {
+	    log.Fatal(err)
+	}
+*/
+package audit
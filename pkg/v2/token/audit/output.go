@@ -0,0 +1,100 @@
+package audit
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// Format represents an output format of the report.
+type Format string
+
+const (
+	FormatTable Format = "table"
+	FormatJSON  Format = "json"
+	FormatCSV   Format = "csv"
+)
+
+// ErrUnsupportedFormat is returned for unknown output formats.
+var ErrUnsupportedFormat = errors.New("unsupported report format")
+
+// reportColumns is a header of table and CSV outputs.
+var reportColumns = []string{
+	"ID", "NAME", "STATUS", "MODE", "REGISTRIES", "LAST USED", "UNUSED DAYS", "EXPIRES IN DAYS", "FINDINGS",
+}
+
+// Write renders the report in the format.
+func (r *Report) Write(w io.Writer, format Format) error {
+	switch format {
+	case FormatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+
+		return encoder.Encode(r)
+	case FormatCSV:
+		writer := csv.NewWriter(w)
+		if err := writer.Write(reportColumns); err != nil {
+			return err
+		}
+		for _, entry := range r.Entries {
+			if err := writer.Write(entry.row()); err != nil {
+				return err
+			}
+		}
+		writer.Flush()
+
+		return writer.Error()
+	case FormatTable, "":
+		writer := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(writer, strings.Join(reportColumns, "\t"))
+		for _, entry := range r.Entries {
+			fmt.Fprintln(writer, strings.Join(entry.row(), "\t"))
+		}
+
+		return writer.Flush()
+	}
+
+	return fmt.Errorf("%w: %s", ErrUnsupportedFormat, format)
+}
+
+// row returns the entry columns for table and CSV outputs.
+func (e *Entry) row() []string {
+	mode := "RO"
+	if e.Token.Scope.ModeRW {
+		mode = "RW"
+	}
+	registries := strings.Join(e.Token.Scope.RegistryIDs, ",")
+	if e.Token.Scope.AllRegistries {
+		registries = "*"
+	}
+	lastUsed := "never"
+	if e.Token.LastUsedAt != nil && !e.Token.LastUsedAt.IsZero() {
+		lastUsed = e.Token.LastUsedAt.UTC().Format(time.RFC3339)
+	}
+	expiresIn := "never"
+	if e.ExpiresInDays != nil {
+		expiresIn = strconv.Itoa(*e.ExpiresInDays)
+	}
+	findings := make([]string, 0, len(e.Findings))
+	for _, f := range e.Findings {
+		findings = append(findings, string(f))
+	}
+
+	return []string{
+		e.Token.ID,
+		e.Token.Name,
+		string(e.Token.Status),
+		mode,
+		registries,
+		lastUsed,
+		strconv.Itoa(e.UnusedDays),
+		expiresIn,
+		strings.Join(findings, ","),
+	}
+}
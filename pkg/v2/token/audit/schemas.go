@@ -0,0 +1,73 @@
+package audit
+
+import (
+	"time"
+
+	tokenv2 "github.com/selectel/craas-go/pkg/v2/token"
+)
+
+// Finding represents a kind of an audit finding.
+type Finding string
+
+const (
+	// FindingNeverUsed is reported for tokens that have never been used.
+	FindingNeverUsed Finding = "NEVER_USED"
+
+	// FindingUnused is reported for tokens unused for Opts.UnusedDays.
+	FindingUnused Finding = "UNUSED"
+
+	// FindingExpiring is reported for tokens expiring within Opts.ExpiringDays.
+	FindingExpiring Finding = "EXPIRING"
+
+	// FindingExpired is reported for tokens that have already expired.
+	FindingExpired Finding = "EXPIRED"
+
+	// FindingNonExpiringRW is reported for read-write tokens without expiration.
+	FindingNonExpiringRW Finding = "NON_EXPIRING_RW"
+
+	// FindingMissingRegistry is reported for tokens scoped to deleted registries.
+	FindingMissingRegistry Finding = "MISSING_REGISTRY"
+)
+
+// Report represents a result of a tokens audit.
+type Report struct {
+	// GeneratedAt is a time the report has been generated at.
+	GeneratedAt time.Time `json:"generatedAt"`
+
+	// TotalTokens is a number of audited tokens.
+	TotalTokens int `json:"totalTokens"`
+
+	// Entries is a list of tokens with at least one finding.
+	Entries []*Entry `json:"entries"`
+}
+
+// Entry represents audit findings of a single token.
+type Entry struct {
+	// Token is the audited token.
+	Token tokenv2.TokenV2 `json:"token"`
+
+	// Findings is a list of the token findings.
+	Findings []Finding `json:"findings"`
+
+	// UnusedDays is a number of days since the token was used last time
+	// or created if it has never been used.
+	UnusedDays int `json:"unusedDays"`
+
+	// ExpiresInDays is a number of days left before the token expires.
+	// It's nil for tokens without expiration.
+	ExpiresInDays *int `json:"expiresInDays,omitempty"`
+
+	// MissingRegistryIDs is a list of scoped registries that don't exist.
+	MissingRegistryIDs []string `json:"missingRegistryIds,omitempty"`
+}
+
+// Has reports whether the entry contains the finding.
+func (e *Entry) Has(finding Finding) bool {
+	for _, f := range e.Findings {
+		if f == finding {
+			return true
+		}
+	}
+
+	return false
+}
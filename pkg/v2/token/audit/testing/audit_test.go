@@ -0,0 +1,85 @@
+package testing
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/selectel/craas-go/pkg/testutils"
+	clientv1 "github.com/selectel/craas-go/pkg/v1/client"
+	clientv2 "github.com/selectel/craas-go/pkg/v2/client"
+	"github.com/selectel/craas-go/pkg/v2/token/audit"
+)
+
+func TestRun(t *testing.T) {
+	registriesCalled := false
+	pages := 0
+	testEnv := testutils.SetupTestEnv()
+	defer testEnv.TearDownTestEnv()
+
+	testutils.HandleReqWithoutBody(t, &testutils.HandleReqOpts{
+		Mux:         testEnv.Mux,
+		URL:         "/api/v1/registries",
+		RawResponse: testListRegistriesResponseRaw,
+		Method:      http.MethodGet,
+		Status:      http.StatusOK,
+		CallFlag:    &registriesCalled,
+	})
+	testEnv.Mux.HandleFunc("/api/v2/tokens", func(w http.ResponseWriter, r *http.Request) {
+		pages++
+		w.Header().Add("Content-Type", "application/json")
+		switch offset := r.URL.Query().Get("offset"); offset {
+		case "0":
+			fmt.Fprint(w, testListTokensFirstPageResponseRaw)
+		case "2":
+			fmt.Fprint(w, testListTokensSecondPageResponseRaw)
+		default:
+			t.Errorf("unexpected offset %s", offset)
+		}
+	})
+
+	ctx := context.Background()
+	testClientV1, err := clientv1.NewCRaaSClientV1(testutils.TokenID, testEnv.Server.URL+"/api/v1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	testClientV2, err := clientv2.NewCRaaSClientV2(testutils.TokenID, testEnv.Server.URL+"/api/v2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	report, err := audit.Run(ctx, testClientV2, testClientV1, &audit.Opts{
+		PageSize: 2,
+		Now:      testNow,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !registriesCalled {
+		t.Fatal("endpoint wasn't called")
+	}
+	if pages != 2 {
+		t.Fatalf("expected 2 pages to be requested, but got %d", pages)
+	}
+	if report.TotalTokens != 3 {
+		t.Fatalf("expected 3 audited tokens, but got %d", report.TotalTokens)
+	}
+
+	actualFindings := make(map[string][]audit.Finding)
+	for _, entry := range report.Entries {
+		actualFindings[entry.Token.Name] = entry.Findings
+	}
+	if !reflect.DeepEqual(expectedFindings, actualFindings) {
+		t.Fatalf("expected %#v, but got %#v", expectedFindings, actualFindings)
+	}
+
+	var csv bytes.Buffer
+	if err := report.Write(&csv, audit.FormatCSV); err != nil {
+		t.Fatal(err)
+	}
+	if csv.String() != expectedReportCSV {
+		t.Fatalf("expected %s, but got %s", expectedReportCSV, csv.String())
+	}
+}
@@ -0,0 +1,98 @@
+package testing
+
+import (
+	"time"
+
+	"github.com/selectel/craas-go/pkg/v2/token/audit"
+)
+
+const (
+	testExistingRegistryID = "888af692-c646-4b76-a234-81ca9b5bcafe"
+	testDeletedRegistryID  = "6303699d-c2cd-40b1-8428-9dcd6cc3d00d"
+)
+
+var testNow = time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC)
+
+const testListRegistriesResponseRaw = `[
+    {
+        "id": "888af692-c646-4b76-a234-81ca9b5bcafe",
+        "name": "test-registry",
+        "createdAt": "2022-10-25T10:25:22.556Z",
+        "status": "ACTIVE",
+        "size": 0,
+        "sizeLimit": 21474836480,
+        "used": 0
+    }
+]`
+
+// testListTokensFirstPageResponseRaw represents the first page of two tokens.
+const testListTokensFirstPageResponseRaw = `{
+  "tokens": [
+    {
+      "id": "c29e3f63-0711-4772-a415-ad79973bdaef",
+      "name": "healthy",
+      "createdAt": "2023-02-13T15:00:00Z",
+      "expiration": {
+        "isSet": true,
+        "expiresAt": "2030-01-01T00:00:00Z"
+      },
+      "scope": {
+        "modeRW": false,
+        "allRegistries": false,
+        "registryIds": ["888af692-c646-4b76-a234-81ca9b5bcafe"]
+      },
+      "lastUsedAt": "2023-05-30T15:25:10Z",
+      "status": "active"
+    },
+    {
+      "id": "0a4f5c89-24d3-4d0d-9e62-2f3b4d3d9f10",
+      "name": "ci-forgotten",
+      "createdAt": "2022-01-10T10:00:00Z",
+      "expiration": {
+        "isSet": false
+      },
+      "scope": {
+        "modeRW": true,
+        "allRegistries": false,
+        "registryIds": [
+          "888af692-c646-4b76-a234-81ca9b5bcafe",
+          "6303699d-c2cd-40b1-8428-9dcd6cc3d00d"
+        ]
+      },
+      "lastUsedAt": "2022-02-01T10:00:00Z",
+      "status": "active"
+    }
+  ],
+  "totalCount": 3
+}`
+
+// testListTokensSecondPageResponseRaw represents the last page of one token.
+const testListTokensSecondPageResponseRaw = `{
+  "tokens": [
+    {
+      "id": "5b9f2a8e-7c1d-4e6f-8a3b-9c0d1e2f3a4b",
+      "name": "fresh",
+      "createdAt": "2023-05-20T10:00:00Z",
+      "expiration": {
+        "isSet": true,
+        "expiresAt": "2023-06-05T00:00:00Z"
+      },
+      "scope": {
+        "modeRW": false,
+        "allRegistries": true
+      },
+      "status": "active"
+    }
+  ],
+  "totalCount": 3
+}`
+
+var expectedFindings = map[string][]audit.Finding{
+	"ci-forgotten": {audit.FindingUnused, audit.FindingNonExpiringRW, audit.FindingMissingRegistry},
+	"fresh":        {audit.FindingNeverUsed, audit.FindingExpiring},
+}
+
+const expectedReportCSV = `ID,NAME,STATUS,MODE,REGISTRIES,LAST USED,UNUSED DAYS,EXPIRES IN DAYS,FINDINGS
+0a4f5c89-24d3-4d0d-9e62-2f3b4d3d9f10,ci-forgotten,active,RW,"888af692-c646-4b76-a234-81ca9b5bcafe,6303699d-c2cd-40b1-8428-9dcd6cc3d00d",2022-02-01T10:00:00Z,484,never,"UNUSED,NON_EXPIRING_RW,MISSING_REGISTRY"
+5b9f2a8e-7c1d-4e6f-8a3b-9c0d1e2f3a4b,fresh,active,RO,*,never,11,4,"NEVER_USED,EXPIRING"
+`
@@ -59,6 +59,20 @@ Example of getting list of a tokens:
 	    log.Fatal(err)
 	}
 
+Example of getting active tokens sorted by creation time:
+
+	opts := tokenV2.Opts{
+		SortField: tokenV2.SortFieldCreatedAt,
+		SortType:  tokenV2.SortTypeDesc,
+	}
+	tokens, err := tokenV2.ListAll(ctx, client, opts)
+	if err != nil {
+	    log.Fatal(err)
+	}
+	for _, token := range tokenV2.ActiveOnly(tokens) {
+	    fmt.Printf("CRaaS token: %+v", token)
+	}
+
 Example of refreshing a token by its ID:
 
 	var expiresAt, _ = time.Parse("2006-01-02T15:04:05Z", "2030-01-01T00:00:00Z")
@@ -108,5 +122,48 @@ Example of patch a token by its ID:
 	if err != nil {
 	    log.Fatal(err)
 	}
+
+Example of updating only a token name:
+
+	opts := new(tokenV2.PatchOpts).SetName("token")
+	_, _, err := tokenV2.PatchWithOpts(ctx, client, tokenID, opts)
+	if err != nil {
+	    log.Fatal(err)
+	}
+
+Example of adding a registry to a token scope:
+
+	_, _, err := tokenV2.AddRegistryToScope(ctx, client, tokenID, registryID)
+	if err != nil {
+	    log.Fatal(err)
+	}
+
+Example of building a scope and checking its registries exist:
+
+	scope, err := tokenV2.NewScopeBuilder().
+	    ReadWrite().
+	    ForRegistryNames("production", "staging").
+	    Resolve(ctx, clientV1)
+	if err != nil {
+	    log.Fatal(err)
+	}
+	craasToken, _, err := tokenV2.Create(ctx, client, &tokenV2.TokenV2{Name: "ci", Scope: scope}, nil)
+	if err != nil {
+	    log.Fatal(err)
+	}
+
+Example of creating a token and merging its docker config into ~/.docker/config.json:
+
+	path, err := dockerconfig.DefaultConfigPath()
+	if err != nil {
+	    log.Fatal(err)
+	}
+	craasToken, cfg, _, err := tokenV2.CreateWithDockerConfig(ctx, client, create, &tokenV2.DockerConfigOpts{
+	    MergePath: path,
+	})
+	if err != nil {
+	    log.Fatal(err)
+	}
+	fmt.Printf("CRaaS token %s auths: %+v", craasToken.ID, cfg.Auths)
 */
 package tokenv2
@@ -0,0 +1,110 @@
+package tokenv2
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"github.com/selectel/craas-go/pkg/dockerconfig"
+	"github.com/selectel/craas-go/pkg/svc"
+	"github.com/selectel/craas-go/pkg/v2/client"
+)
+
+var ErrInvalidDockerConfig = errors.New("invalid docker config in the token response")
+
+// DockerConfigOpts represents options of the docker config returned by CreateWithDockerConfig.
+type DockerConfigOpts struct {
+	// Host is a registry host used if the API response has no docker config,
+	// dockerconfig.DefaultRegistryHost is used if not set.
+	Host string
+
+	// WritePath is a path of a file the docker config is written to.
+	// The file is replaced.
+	WritePath string
+
+	// MergePath is a path of a Docker config file the auths entries are
+	// merged into, e.g. the one returned by dockerconfig.DefaultConfigPath.
+	MergePath string
+}
+
+// CreateWithDockerConfig creates a token requesting a docker config and returns
+// the decoded auths structure along with the token. If the API response has
+// no docker config, it's built from the token secret for the options host.
+func CreateWithDockerConfig(
+	ctx context.Context,
+	client *client.ServiceClient,
+	tkn *TokenV2,
+	opts *DockerConfigOpts,
+) (*TokenV2, *dockerconfig.Config, *svc.ResponseResult, error) {
+	if opts == nil {
+		opts = &DockerConfigOpts{}
+	}
+
+	dockerCfg := true
+	token, responseResult, err := Create(ctx, client, tkn, &dockerCfg)
+	if err != nil {
+		return nil, nil, responseResult, err
+	}
+
+	cfg, err := decodeDockerConfig(token.DockerConfig)
+	if err != nil {
+		return token, nil, responseResult, err
+	}
+	if cfg == nil {
+		host := opts.Host
+		if host == "" {
+			host = dockerconfig.DefaultRegistryHost
+		}
+		if cfg, err = dockerconfig.New(host, token); err != nil {
+			return token, nil, responseResult, err
+		}
+	}
+
+	if opts.WritePath != "" {
+		if err := dockerconfig.WriteFile(opts.WritePath, cfg); err != nil {
+			return token, cfg, responseResult, err
+		}
+	}
+	if opts.MergePath != "" {
+		if err := dockerconfig.MergeFile(opts.MergePath, cfg); err != nil {
+			return token, cfg, responseResult, err
+		}
+	}
+
+	return token, cfg, responseResult, nil
+}
+
+// decodeDockerConfig decodes a docker config that is either a JSON object
+// or a string with a plain or base64 encoded JSON document.
+// Nil is returned for an empty value.
+func decodeDockerConfig(raw json.RawMessage) (*dockerconfig.Config, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil
+	}
+
+	document := []byte(raw)
+	var encoded string
+	if err := json.Unmarshal(raw, &encoded); err == nil {
+		encoded = strings.TrimSpace(encoded)
+		if encoded == "" {
+			return nil, nil
+		}
+		document = []byte(encoded)
+		if !strings.HasPrefix(encoded, "{") {
+			decoded, err := base64.StdEncoding.DecodeString(encoded)
+			if err != nil {
+				return nil, ErrInvalidDockerConfig
+			}
+			document = decoded
+		}
+	}
+
+	var cfg dockerconfig.Config
+	if err := json.Unmarshal(document, &cfg); err != nil || len(cfg.Auths) == 0 {
+		return nil, ErrInvalidDockerConfig
+	}
+
+	return &cfg, nil
+}
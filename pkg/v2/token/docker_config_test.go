@@ -0,0 +1,63 @@
+package tokenv2
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"testing"
+)
+
+func Test_decodeDockerConfig(t *testing.T) {
+	document := `{"auths":{"cr.selcloud.ru":{"auth":"dG9rZW46c2VjcmV0"}}}`
+	tests := []struct {
+		name    string
+		raw     json.RawMessage
+		wantNil bool
+		wantErr error
+	}{
+		{
+			name: "object",
+			raw:  json.RawMessage(document),
+		},
+		{
+			name: "plain string",
+			raw:  json.RawMessage(strconv.Quote(document)),
+		},
+		{
+			name: "base64 string",
+			raw:  json.RawMessage(strconv.Quote(base64.StdEncoding.EncodeToString([]byte(document)))),
+		},
+		{
+			name:    "absent",
+			raw:     nil,
+			wantNil: true,
+		},
+		{
+			name:    "invalid",
+			raw:     json.RawMessage(`"not a config"`),
+			wantErr: ErrInvalidDockerConfig,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decodeDockerConfig(tt.raw)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("decodeDockerConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			if tt.wantNil {
+				if got != nil {
+					t.Fatalf("decodeDockerConfig() = %#v, want nil", got)
+				}
+
+				return
+			}
+			if got.Auths["cr.selcloud.ru"].Auth != "dG9rZW46c2VjcmV0" {
+				t.Errorf("decodeDockerConfig() = %#v", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,30 @@
+/*
+Package `policy` provides a job that revokes or deletes CRaaS v2 tokens
+matching configurable rules.
+
+Every rule condition that is set must match for a token to be selected,
+and the first matching rule decides the action. Excluded tokens are never
+touched, and every action (or planned action in the dry-run mode) is written
+to the audit log as a JSON line.
+
+Example of revoking CI tokens unused for 90 days:
+
+	records, err := policy.Enforce(ctx, client, &policy.Opts{
+	    Rules: []policy.Rule{
+	        {
+	            Name:        "stale-ci",
+	            Action:      policy.ActionRevoke,
+	            NamePattern: "ci-*",
+	            UnusedDays:  90,
+	        },
+	    },
+	    ExcludeNames: []string{"ci-release"},
+	    DryRun:       true,
+	    AuditLog:     os.Stdout,
+	})
+	if err != nil {
+	    log.Fatal(err)
+	}
+	fmt.Printf("Matched tokens: %d", len(records))
+*/
+package policy
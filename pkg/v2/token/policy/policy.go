@@ -0,0 +1,205 @@
+package policy
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"time"
+
+	"github.com/selectel/craas-go/pkg/v2/client"
+	tokenv2 "github.com/selectel/craas-go/pkg/v2/token"
+)
+
+var (
+	ErrNoRules       = errors.New("no policy rules")
+	ErrRuleEmpty     = errors.New("policy rule has no conditions")
+	ErrInvalidAction = errors.New("invalid policy action")
+	ErrActionsFailed = errors.New("some policy actions failed")
+)
+
+// Opts represents options of a policy job.
+type Opts struct {
+	// Rules are evaluated in order, the first matching rule is applied.
+	Rules []Rule
+
+	// DryRun disables applying actions, matches are only recorded.
+	DryRun bool
+
+	// ExcludeIDs is a list of token IDs that are never touched.
+	ExcludeIDs []string
+
+	// ExcludeNames is a list of path.Match patterns of token names that are
+	// never touched.
+	ExcludeNames []string
+
+	// AuditLog receives a JSON line for every record, it's optional.
+	AuditLog io.Writer
+
+	// Now is a reference time of the job, the current time is used if not set.
+	Now time.Time
+}
+
+// Validate checks that rules are well-formed.
+func (opts *Opts) Validate() error {
+	if len(opts.Rules) == 0 {
+		return ErrNoRules
+	}
+	for i, rule := range opts.Rules {
+		switch rule.Action {
+		case ActionRevoke, ActionDelete:
+		default:
+			return fmt.Errorf("rule %d: %w: %q", i, ErrInvalidAction, rule.Action)
+		}
+		if rule.UnusedDays <= 0 && rule.NamePattern == "" && rule.AllRegistries == nil && rule.ModeRW == nil {
+			return fmt.Errorf("rule %d: %w", i, ErrRuleEmpty)
+		}
+		if rule.NamePattern != "" {
+			if _, err := path.Match(rule.NamePattern, ""); err != nil {
+				return fmt.Errorf("rule %d: %w", i, err)
+			}
+		}
+	}
+	for _, pattern := range opts.ExcludeNames {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("exclude pattern %q: %w", pattern, err)
+		}
+	}
+
+	return nil
+}
+
+// Enforce lists all tokens, applies the first matching rule to every token
+// and returns records of the actions. ErrActionsFailed is returned along
+// with the records if some actions failed.
+func Enforce(ctx context.Context, client *client.ServiceClient, opts *Opts) ([]*Record, error) {
+	if opts == nil {
+		return nil, ErrNoRules
+	}
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+
+	tokens, err := tokenv2.ListAll(ctx, client, tokenv2.Opts{})
+	if err != nil {
+		return nil, err
+	}
+
+	now := opts.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+
+	records := make([]*Record, 0)
+	failed := false
+	for i := range tokens {
+		tkn := &tokens[i]
+		if opts.excluded(tkn) {
+			continue
+		}
+		rule := opts.match(tkn, now)
+		if rule == nil {
+			continue
+		}
+
+		record := &Record{
+			Time:      time.Now().UTC(),
+			TokenID:   tkn.ID,
+			TokenName: tkn.Name,
+			Rule:      rule.Name,
+			Action:    rule.Action,
+			DryRun:    opts.DryRun,
+		}
+		if !opts.DryRun {
+			if err := apply(ctx, client, tkn, rule.Action); err != nil {
+				record.Error = err.Error()
+				failed = true
+			}
+		}
+		records = append(records, record)
+
+		if opts.AuditLog != nil {
+			if err := json.NewEncoder(opts.AuditLog).Encode(record); err != nil {
+				return records, fmt.Errorf("unable to write the audit log: %w", err)
+			}
+		}
+	}
+	if failed {
+		return records, ErrActionsFailed
+	}
+
+	return records, nil
+}
+
+// excluded reports whether the token is excluded from the job.
+func (opts *Opts) excluded(tkn *tokenv2.TokenV2) bool {
+	for _, id := range opts.ExcludeIDs {
+		if tkn.ID == id {
+			return true
+		}
+	}
+	for _, pattern := range opts.ExcludeNames {
+		if ok, _ := path.Match(pattern, tkn.Name); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// match returns the first rule matching the token.
+func (opts *Opts) match(tkn *tokenv2.TokenV2, now time.Time) *Rule {
+	for i := range opts.Rules {
+		rule := &opts.Rules[i]
+		// Revoked and expired tokens can't be revoked again.
+		if rule.Action == ActionRevoke && tkn.Status != tokenv2.StatusActive {
+			continue
+		}
+		if rule.matches(tkn, now) {
+			return rule
+		}
+	}
+
+	return nil
+}
+
+// matches reports whether all set conditions of the rule match the token.
+func (rule *Rule) matches(tkn *tokenv2.TokenV2, now time.Time) bool {
+	if rule.NamePattern != "" {
+		if ok, _ := path.Match(rule.NamePattern, tkn.Name); !ok {
+			return false
+		}
+	}
+	if rule.AllRegistries != nil && *rule.AllRegistries != tkn.Scope.AllRegistries {
+		return false
+	}
+	if rule.ModeRW != nil && *rule.ModeRW != tkn.Scope.ModeRW {
+		return false
+	}
+	if rule.UnusedDays > 0 {
+		lastUsed := tkn.LastUsedAt
+		if lastUsed == nil || lastUsed.IsZero() {
+			lastUsed = tkn.CreatedAt
+		}
+		if lastUsed == nil || now.Sub(*lastUsed) < time.Duration(rule.UnusedDays)*24*time.Hour {
+			return false
+		}
+	}
+
+	return true
+}
+
+// apply applies the action to the token.
+func apply(ctx context.Context, client *client.ServiceClient, tkn *tokenv2.TokenV2, action Action) error {
+	var err error
+	switch action {
+	case ActionRevoke:
+		_, err = tokenv2.Revoke(ctx, client, tkn.ID)
+	case ActionDelete:
+		_, err = tokenv2.Delete(ctx, client, tkn.ID)
+	}
+
+	return err
+}
@@ -0,0 +1,58 @@
+package policy
+
+import "time"
+
+// Action represents an action applied to matching tokens.
+type Action string
+
+const (
+	ActionRevoke Action = "revoke"
+	ActionDelete Action = "delete"
+)
+
+// Rule represents conditions that select tokens for an action.
+// Conditions that are not set are ignored, but at least one must be set.
+type Rule struct {
+	// Name is a rule name written to the audit log.
+	Name string `json:"name"`
+
+	// Action is applied to matching tokens.
+	Action Action `json:"action"`
+
+	// UnusedDays matches tokens that weren't used for the number of days.
+	// Tokens that have never been used are matched by their creation time.
+	UnusedDays int `json:"unusedDays,omitempty"`
+
+	// NamePattern matches token names with the path.Match syntax, e.g. "ci-*".
+	NamePattern string `json:"namePattern,omitempty"`
+
+	// AllRegistries matches tokens by their scope AllRegistries value.
+	AllRegistries *bool `json:"allRegistries,omitempty"`
+
+	// ModeRW matches tokens by their scope ModeRW value.
+	ModeRW *bool `json:"modeRW,omitempty"`
+}
+
+// Record represents an audit log entry of a single action.
+type Record struct {
+	// Time is a time the action has been taken at.
+	Time time.Time `json:"time"`
+
+	// TokenID is an ID of the matched token.
+	TokenID string `json:"tokenId"`
+
+	// TokenName is a name of the matched token.
+	TokenName string `json:"tokenName"`
+
+	// Rule is a name of the matched rule.
+	Rule string `json:"rule"`
+
+	// Action is an action applied to the token.
+	Action Action `json:"action"`
+
+	// DryRun is set if the action hasn't been applied.
+	DryRun bool `json:"dryRun"`
+
+	// Error is a message of the failed action.
+	Error string `json:"error,omitempty"`
+}
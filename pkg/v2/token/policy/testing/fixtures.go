@@ -0,0 +1,62 @@
+package testing
+
+import "time"
+
+const (
+	testStaleCITokenID   = "0a4f5c89-24d3-4d0d-9e62-2f3b4d3d9f10"
+	testReleaseTokenID   = "5b9f2a8e-7c1d-4e6f-8a3b-9c0d1e2f3a4b"
+	testWildcardTokenID  = "c29e3f63-0711-4772-a415-ad79973bdaef"
+	testFreshCITokenID   = "7d1c1c5e-3b0e-4ad4-9e6b-2a6f1f0a2c55"
+	testRevokedCITokenID = "e3b0c442-98fc-4c14-9afb-f4c8996fb924"
+)
+
+var testNow = time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC)
+
+const testListTokensResponseRaw = `{
+  "tokens": [
+    {
+      "id": "0a4f5c89-24d3-4d0d-9e62-2f3b4d3d9f10",
+      "name": "ci-stale",
+      "createdAt": "2022-01-10T10:00:00Z",
+      "expiration": {"isSet": false},
+      "scope": {"modeRW": false, "allRegistries": false, "registryIds": ["888af692-c646-4b76-a234-81ca9b5bcafe"]},
+      "lastUsedAt": "2022-02-01T10:00:00Z",
+      "status": "active"
+    },
+    {
+      "id": "5b9f2a8e-7c1d-4e6f-8a3b-9c0d1e2f3a4b",
+      "name": "ci-release",
+      "createdAt": "2022-01-10T10:00:00Z",
+      "expiration": {"isSet": false},
+      "scope": {"modeRW": true, "allRegistries": true},
+      "status": "active"
+    },
+    {
+      "id": "c29e3f63-0711-4772-a415-ad79973bdaef",
+      "name": "deploy",
+      "createdAt": "2023-05-10T10:00:00Z",
+      "expiration": {"isSet": false},
+      "scope": {"modeRW": true, "allRegistries": true},
+      "lastUsedAt": "2023-05-30T10:00:00Z",
+      "status": "active"
+    },
+    {
+      "id": "7d1c1c5e-3b0e-4ad4-9e6b-2a6f1f0a2c55",
+      "name": "ci-fresh",
+      "createdAt": "2023-05-10T10:00:00Z",
+      "expiration": {"isSet": false},
+      "scope": {"modeRW": false, "allRegistries": false, "registryIds": ["888af692-c646-4b76-a234-81ca9b5bcafe"]},
+      "lastUsedAt": "2023-05-30T10:00:00Z",
+      "status": "active"
+    },
+    {
+      "id": "e3b0c442-98fc-4c14-9afb-f4c8996fb924",
+      "name": "ci-revoked",
+      "createdAt": "2022-01-10T10:00:00Z",
+      "expiration": {"isSet": false},
+      "scope": {"modeRW": false, "allRegistries": false, "registryIds": ["888af692-c646-4b76-a234-81ca9b5bcafe"]},
+      "status": "revoked"
+    }
+  ],
+  "totalCount": 5
+}`
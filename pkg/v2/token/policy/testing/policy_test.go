@@ -0,0 +1,188 @@
+package testing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/selectel/craas-go/pkg/testutils"
+	"github.com/selectel/craas-go/pkg/v2/client"
+	"github.com/selectel/craas-go/pkg/v2/token/policy"
+)
+
+const apiV2 = "/api/v2"
+
+func testRules() []policy.Rule {
+	allRegistries := true
+	modeRW := true
+
+	return []policy.Rule{
+		{
+			Name:        "stale-ci",
+			Action:      policy.ActionRevoke,
+			NamePattern: "ci-*",
+			UnusedDays:  90,
+		},
+		{
+			Name:          "wide-rw",
+			Action:        policy.ActionDelete,
+			AllRegistries: &allRegistries,
+			ModeRW:        &modeRW,
+		},
+	}
+}
+
+func TestEnforce(t *testing.T) {
+	listCalled := false
+	revokeCalled := false
+	deleteCalled := false
+	testEnv := testutils.SetupTestEnv()
+	defer testEnv.TearDownTestEnv()
+
+	testutils.HandleReqWithoutBody(t, &testutils.HandleReqOpts{
+		Mux:         testEnv.Mux,
+		URL:         apiV2 + "/tokens",
+		RawResponse: testListTokensResponseRaw,
+		Method:      http.MethodGet,
+		Status:      http.StatusOK,
+		CallFlag:    &listCalled,
+	})
+	testutils.HandleReqWithoutBody(t, &testutils.HandleReqOpts{
+		Mux:      testEnv.Mux,
+		URL:      apiV2 + "/tokens/" + testStaleCITokenID + "/revoke",
+		Method:   http.MethodPost,
+		Status:   http.StatusNoContent,
+		CallFlag: &revokeCalled,
+	})
+	testutils.HandleReqWithoutBody(t, &testutils.HandleReqOpts{
+		Mux:      testEnv.Mux,
+		URL:      apiV2 + "/tokens/" + testWildcardTokenID,
+		Method:   http.MethodDelete,
+		Status:   http.StatusNoContent,
+		CallFlag: &deleteCalled,
+	})
+
+	testClient, err := client.NewCRaaSClientV2(testutils.TokenID, testEnv.Server.URL+apiV2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var auditLog bytes.Buffer
+	records, err := policy.Enforce(context.Background(), testClient, &policy.Opts{
+		Rules:        testRules(),
+		ExcludeNames: []string{"ci-release"},
+		AuditLog:     &auditLog,
+		Now:          testNow,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !listCalled || !revokeCalled || !deleteCalled {
+		t.Fatal("endpoint wasn't called")
+	}
+	if len(records) != 2 {
+		t.Fatalf("expected 2 records, but got %d", len(records))
+	}
+	if records[0].TokenID != testStaleCITokenID || records[0].Action != policy.ActionRevoke {
+		t.Fatalf("unexpected first record %+v", records[0])
+	}
+	if records[1].TokenID != testWildcardTokenID || records[1].Action != policy.ActionDelete {
+		t.Fatalf("unexpected second record %+v", records[1])
+	}
+
+	decoder := json.NewDecoder(&auditLog)
+	for _, expected := range records {
+		var actual policy.Record
+		if err := decoder.Decode(&actual); err != nil {
+			t.Fatal(err)
+		}
+		if actual.TokenID != expected.TokenID || actual.Rule != expected.Rule {
+			t.Fatalf("expected %+v audit log record, but got %+v", expected, actual)
+		}
+	}
+}
+
+func TestEnforceDryRun(t *testing.T) {
+	listCalled := false
+	testEnv := testutils.SetupTestEnv()
+	defer testEnv.TearDownTestEnv()
+
+	testutils.HandleReqWithoutBody(t, &testutils.HandleReqOpts{
+		Mux:         testEnv.Mux,
+		URL:         apiV2 + "/tokens",
+		RawResponse: testListTokensResponseRaw,
+		Method:      http.MethodGet,
+		Status:      http.StatusOK,
+		CallFlag:    &listCalled,
+	})
+
+	testClient, err := client.NewCRaaSClientV2(testutils.TokenID, testEnv.Server.URL+apiV2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	records, err := policy.Enforce(context.Background(), testClient, &policy.Opts{
+		Rules:      testRules(),
+		ExcludeIDs: []string{testWildcardTokenID},
+		DryRun:     true,
+		Now:        testNow,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !listCalled {
+		t.Fatal("endpoint wasn't called")
+	}
+
+	actual := make(map[string]policy.Action)
+	for _, record := range records {
+		if !record.DryRun {
+			t.Fatalf("expected dry-run record, but got %+v", record)
+		}
+		actual[record.TokenID] = record.Action
+	}
+	expected := map[string]policy.Action{
+		testStaleCITokenID: policy.ActionRevoke,
+		testReleaseTokenID: policy.ActionRevoke,
+	}
+	if len(actual) != len(expected) {
+		t.Fatalf("expected %v, but got %v", expected, actual)
+	}
+	for id, action := range expected {
+		if actual[id] != action {
+			t.Fatalf("expected %v, but got %v", expected, actual)
+		}
+	}
+}
+
+func TestOptsValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    policy.Opts
+		wantErr error
+	}{
+		{
+			name:    "no rules",
+			opts:    policy.Opts{},
+			wantErr: policy.ErrNoRules,
+		},
+		{
+			name:    "empty rule",
+			opts:    policy.Opts{Rules: []policy.Rule{{Action: policy.ActionRevoke}}},
+			wantErr: policy.ErrRuleEmpty,
+		},
+		{
+			name:    "invalid action",
+			opts:    policy.Opts{Rules: []policy.Rule{{Action: "disable", UnusedDays: 1}}},
+			wantErr: policy.ErrInvalidAction,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.opts.Validate(); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
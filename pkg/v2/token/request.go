@@ -16,6 +16,8 @@ import (
 )
 
 // Create method token.
+// The token scope is sent as is, see ScopeBuilder to build a validated scope
+// and check that its registries exist.
 func Create(ctx context.Context, client *client.ServiceClient, tkn *TokenV2, dockerCfg *bool) (*TokenV2, *svc.ResponseResult, error) {
 	val := url.Values{}
 	url := strings.Join([]string{client.Endpoint(), v2.ResourceURLToken}, "/")
@@ -28,8 +30,11 @@ func Create(ctx context.Context, client *client.ServiceClient, tkn *TokenV2, doc
 		return nil, nil, err
 	}
 	responseResult, err := client.DoRequest(ctx, http.MethodPost, url, bytes.NewReader(reqBody))
+	if err != nil {
+		return nil, nil, err
+	}
 	if responseResult.Err != nil {
-		return nil, responseResult, err
+		return nil, responseResult, responseResult.Err
 	}
 
 	// Extract token from the response body.
@@ -44,6 +49,10 @@ func Create(ctx context.Context, client *client.ServiceClient, tkn *TokenV2, doc
 
 // List returns a list tokens.
 func List(ctx context.Context, client *client.ServiceClient, opts Opts) (*TokensV2, *svc.ResponseResult, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, nil, err
+	}
+
 	url := strings.Join([]string{client.Endpoint(), v2.ResourceURLToken}, "/")
 	urlWithQuery := fmt.Sprintf("%s?%s", url, makeQueryString(opts))
 	responseResult, err := client.DoRequest(ctx, http.MethodGet, urlWithQuery, nil)
@@ -162,6 +171,8 @@ func Delete(ctx context.Context, client *client.ServiceClient, tokenID string) (
 }
 
 // Patch patch a token by its ID.
+// The scope and expiration are always sent, so zero values reset them.
+// Use PatchWithOpts to update only some of the fields.
 func Patch(ctx context.Context, client *client.ServiceClient, tokenID string, name string, sc Scope, exp Expiration) (*TokenV2, *svc.ResponseResult, error) {
 	var token TokenV2
 	if name != "" {
@@ -194,3 +205,95 @@ func Patch(ctx context.Context, client *client.ServiceClient, tokenID string, na
 
 	return &tokenResult, responseResult, nil
 }
+
+// ListAll pages through all tokens matching the options and returns them.
+// Limit of the options is used as a page size, Offset is ignored.
+func ListAll(ctx context.Context, client *client.ServiceClient, opts Opts) ([]TokenV2, error) {
+	pageSize := DefaultListPageSize
+	if opts.Limit != nil && *opts.Limit > 0 {
+		pageSize = *opts.Limit
+	}
+
+	tokens := make([]TokenV2, 0)
+	for offset := 0; ; {
+		limit, pageOffset := pageSize, offset
+		opts.Limit, opts.Offset = &limit, &pageOffset
+		page, _, err := List(ctx, client, opts)
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, page.Tokens...)
+		offset += len(page.Tokens)
+		if len(page.Tokens) == 0 || int64(offset) >= page.TotalCount {
+			return tokens, nil
+		}
+	}
+}
+
+// PatchWithOpts partially updates a token by its ID sending only the fields
+// set in the options. If registries are added or removed, the current token
+// scope is read first and the modified scope is sent.
+func PatchWithOpts(ctx context.Context, client *client.ServiceClient, tokenID string, opts *PatchOpts) (*TokenV2, *svc.ResponseResult, error) {
+	if tokenID == "" {
+		return nil, nil, ErrTokenIDEmpty
+	}
+	if opts == nil || opts.isEmpty() {
+		return nil, nil, ErrPatchOptsEmpty
+	}
+
+	request := patchRequest{
+		Scope:      opts.Scope,
+		Expiration: opts.Expiration,
+	}
+	if opts.Name != nil {
+		request.Name = *opts.Name
+	}
+	if opts.changesRegistries() {
+		scope := opts.Scope
+		if scope == nil {
+			current, responseResult, err := GetByID(ctx, client, tokenID)
+			if err != nil {
+				return nil, responseResult, err
+			}
+			scope = &current.Scope
+		}
+		modified, err := applyRegistryChanges(*scope, opts.AddRegistryIDs, opts.RemoveRegistryIDs)
+		if err != nil {
+			return nil, nil, err
+		}
+		request.Scope = &modified
+	}
+
+	reqBody, err := json.Marshal(request)
+	if err != nil {
+		return nil, nil, err
+	}
+	url := strings.Join([]string{client.Endpoint(), v2.ResourceURLToken, tokenID}, "/")
+	responseResult, err := client.DoRequest(ctx, http.MethodPatch, url, bytes.NewReader(reqBody))
+	if err != nil {
+		return nil, nil, err
+	}
+	if responseResult.Err != nil {
+		return nil, responseResult, responseResult.Err
+	}
+
+	// Extract token from the response body.
+	var token TokenV2
+	err = responseResult.ExtractResult(&token)
+	if err != nil {
+		return nil, responseResult, err
+	}
+
+	return &token, responseResult, nil
+}
+
+// AddRegistryToScope adds registries to the token scope keeping other fields intact.
+func AddRegistryToScope(ctx context.Context, client *client.ServiceClient, tokenID string, registryIDs ...string) (*TokenV2, *svc.ResponseResult, error) {
+	return PatchWithOpts(ctx, client, tokenID, new(PatchOpts).AddRegistries(registryIDs...))
+}
+
+// RemoveRegistryFromScope removes registries from the token scope keeping other fields intact.
+// ErrScopeRegistriesEmpty is returned if no registries would be left in the scope.
+func RemoveRegistryFromScope(ctx context.Context, client *client.ServiceClient, tokenID string, registryIDs ...string) (*TokenV2, *svc.ResponseResult, error) {
+	return PatchWithOpts(ctx, client, tokenID, new(PatchOpts).RemoveRegistries(registryIDs...))
+}
@@ -1,10 +1,44 @@
 package tokenv2
 
 import (
+	"errors"
+	"fmt"
 	"net/url"
 	"strconv"
 )
 
+// DefaultListPageSize is a number of tokens requested per page by ListAll.
+const DefaultListPageSize = 100
+
+// Sort fields of the Opts.SortField option.
+const (
+	SortFieldName       = "name"
+	SortFieldCreatedAt  = "createdAt"
+	SortFieldExpiresAt  = "expiresAt"
+	SortFieldLastUsedAt = "lastUsedAt"
+	SortFieldStatus     = "status"
+)
+
+// Sort directions of the Opts.SortType option.
+const (
+	SortTypeAsc  = "asc"
+	SortTypeDesc = "desc"
+)
+
+// Access modes of the Opts.ScopeMode option.
+const (
+	ScopeModeRead      = "r"
+	ScopeModeReadWrite = "rw"
+)
+
+var (
+	ErrInvalidLimit     = errors.New("limit must be positive")
+	ErrInvalidOffset    = errors.New("offset must not be negative")
+	ErrInvalidSortField = errors.New("invalid sort field")
+	ErrInvalidSortType  = errors.New("invalid sort type")
+	ErrInvalidScopeMode = errors.New("invalid scope mode")
+)
+
 type Opts struct {
 	Limit     *int
 	Offset    *int
@@ -14,6 +48,33 @@ type Opts struct {
 	ScopeMode string
 }
 
+// Validate checks the options before sending a request.
+func (o Opts) Validate() error {
+	if o.Limit != nil && *o.Limit <= 0 {
+		return fmt.Errorf("%w: %d", ErrInvalidLimit, *o.Limit)
+	}
+	if o.Offset != nil && *o.Offset < 0 {
+		return fmt.Errorf("%w: %d", ErrInvalidOffset, *o.Offset)
+	}
+	switch o.SortField {
+	case "", SortFieldName, SortFieldCreatedAt, SortFieldExpiresAt, SortFieldLastUsedAt, SortFieldStatus:
+	default:
+		return fmt.Errorf("%w: %s", ErrInvalidSortField, o.SortField)
+	}
+	switch o.SortType {
+	case "", SortTypeAsc, SortTypeDesc:
+	default:
+		return fmt.Errorf("%w: %s", ErrInvalidSortType, o.SortType)
+	}
+	switch o.ScopeMode {
+	case "", ScopeModeRead, ScopeModeReadWrite:
+	default:
+		return fmt.Errorf("%w: %s", ErrInvalidScopeMode, o.ScopeMode)
+	}
+
+	return nil
+}
+
 func makeQueryString(o Opts) string {
 	val := url.Values{}
 	if o.Limit != nil {
@@ -40,3 +101,114 @@ func makeQueryString(o Opts) string {
 
 	return query
 }
+
+var (
+	ErrTokenIDEmpty         = errors.New("token id is empty")
+	ErrPatchOptsEmpty       = errors.New("patch options have no fields set")
+	ErrScopeAllRegistries   = errors.New("token scope includes all registries")
+	ErrScopeRegistriesEmpty = errors.New("token scope has no registries")
+)
+
+// PatchOpts represents options of a partial token update.
+// Only fields that are set are sent to the API.
+type PatchOpts struct {
+	// Name is a new token name.
+	Name *string
+
+	// Scope is a new token scope replacing the current one.
+	Scope *Scope
+
+	// AddRegistryIDs are added to the current token scope.
+	AddRegistryIDs []string
+
+	// RemoveRegistryIDs are removed from the current token scope.
+	RemoveRegistryIDs []string
+
+	// Expiration is a new token expiration.
+	Expiration *Expiration
+}
+
+// SetName sets a new token name.
+func (opts *PatchOpts) SetName(name string) *PatchOpts {
+	opts.Name = &name
+
+	return opts
+}
+
+// SetScope sets a new token scope replacing the current one.
+func (opts *PatchOpts) SetScope(scope Scope) *PatchOpts {
+	opts.Scope = &scope
+
+	return opts
+}
+
+// AddRegistries adds registries to the current token scope.
+func (opts *PatchOpts) AddRegistries(registryIDs ...string) *PatchOpts {
+	opts.AddRegistryIDs = append(opts.AddRegistryIDs, registryIDs...)
+
+	return opts
+}
+
+// RemoveRegistries removes registries from the current token scope.
+func (opts *PatchOpts) RemoveRegistries(registryIDs ...string) *PatchOpts {
+	opts.RemoveRegistryIDs = append(opts.RemoveRegistryIDs, registryIDs...)
+
+	return opts
+}
+
+// SetExpiration sets a new token expiration.
+func (opts *PatchOpts) SetExpiration(exp Expiration) *PatchOpts {
+	opts.Expiration = &exp
+
+	return opts
+}
+
+// isEmpty reports whether no fields are set.
+func (opts *PatchOpts) isEmpty() bool {
+	return opts.Name == nil && opts.Scope == nil && opts.Expiration == nil &&
+		len(opts.AddRegistryIDs) == 0 && len(opts.RemoveRegistryIDs) == 0
+}
+
+// changesRegistries reports whether the current scope is needed to build the request.
+func (opts *PatchOpts) changesRegistries() bool {
+	return len(opts.AddRegistryIDs) != 0 || len(opts.RemoveRegistryIDs) != 0
+}
+
+// patchRequest represents a body of the partial update request.
+type patchRequest struct {
+	Name       string      `json:"name,omitempty"`
+	Scope      *Scope      `json:"scope,omitempty"`
+	Expiration *Expiration `json:"expiration,omitempty"`
+}
+
+// applyRegistryChanges returns a copy of the scope with registries added and removed.
+func applyRegistryChanges(scope Scope, add, remove []string) (Scope, error) {
+	if scope.AllRegistries {
+		return scope, ErrScopeAllRegistries
+	}
+
+	removed := make(map[string]struct{}, len(remove))
+	for _, id := range remove {
+		removed[id] = struct{}{}
+	}
+	seen := make(map[string]struct{}, len(scope.RegistryIDs)+len(add))
+	registryIDs := make([]string, 0, len(scope.RegistryIDs)+len(add))
+	for _, ids := range [][]string{scope.RegistryIDs, add} {
+		for _, id := range ids {
+			if _, ok := removed[id]; ok {
+				continue
+			}
+			if _, ok := seen[id]; ok {
+				continue
+			}
+			seen[id] = struct{}{}
+			registryIDs = append(registryIDs, id)
+		}
+	}
+	if len(registryIDs) == 0 {
+		return scope, ErrScopeRegistriesEmpty
+	}
+	scope.RegistryIDs = registryIDs
+
+	return scope, nil
+}
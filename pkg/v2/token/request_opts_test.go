@@ -1,6 +1,10 @@
 package tokenv2
 
-import "testing"
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
 
 func Test_makeQueryString(t *testing.T) {
 	limit := new(int)
@@ -64,3 +68,108 @@ func Test_makeQueryString(t *testing.T) {
 		})
 	}
 }
+
+func Test_applyRegistryChanges(t *testing.T) {
+	tests := []struct {
+		name    string
+		scope   Scope
+		add     []string
+		remove  []string
+		want    []string
+		wantErr error
+	}{
+		{
+			name:  "add new and duplicate",
+			scope: Scope{RegistryIDs: []string{"a", "b"}},
+			add:   []string{"b", "c"},
+			want:  []string{"a", "b", "c"},
+		},
+		{
+			name:   "remove",
+			scope:  Scope{RegistryIDs: []string{"a", "b"}},
+			remove: []string{"a"},
+			want:   []string{"b"},
+		},
+		{
+			name:    "remove last",
+			scope:   Scope{RegistryIDs: []string{"a"}},
+			remove:  []string{"a"},
+			wantErr: ErrScopeRegistriesEmpty,
+		},
+		{
+			name:    "all registries",
+			scope:   Scope{AllRegistries: true},
+			add:     []string{"a"},
+			wantErr: ErrScopeAllRegistries,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := applyRegistryChanges(tt.scope, tt.add, tt.remove)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("applyRegistryChanges() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && !reflect.DeepEqual(got.RegistryIDs, tt.want) {
+				t.Errorf("applyRegistryChanges() = %v, want %v", got.RegistryIDs, tt.want)
+			}
+		})
+	}
+}
+
+func TestOpts_Validate(t *testing.T) {
+	limit := 10
+	zero := 0
+	negative := -1
+	tests := []struct {
+		name    string
+		opts    Opts
+		wantErr error
+	}{
+		{
+			name: "valid",
+			opts: Opts{
+				Limit:     &limit,
+				Offset:    &zero,
+				SortField: SortFieldCreatedAt,
+				SortType:  SortTypeDesc,
+				ScopeMode: ScopeModeReadWrite,
+			},
+		},
+		{
+			name: "empty",
+			opts: Opts{},
+		},
+		{
+			name:    "zero limit",
+			opts:    Opts{Limit: &zero},
+			wantErr: ErrInvalidLimit,
+		},
+		{
+			name:    "negative offset",
+			opts:    Opts{Offset: &negative},
+			wantErr: ErrInvalidOffset,
+		},
+		{
+			name:    "invalid sort field",
+			opts:    Opts{SortField: "size"},
+			wantErr: ErrInvalidSortField,
+		},
+		{
+			name:    "invalid sort type",
+			opts:    Opts{SortType: "up"},
+			wantErr: ErrInvalidSortType,
+		},
+		{
+			name:    "invalid scope mode",
+			opts:    Opts{ScopeMode: "w"},
+			wantErr: ErrInvalidScopeMode,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.opts.Validate(); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
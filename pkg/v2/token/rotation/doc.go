@@ -0,0 +1,22 @@
+/*
+Package `rotation` provides a workflow for rotating CRaaS v2 tokens.
+
+A rotation creates a replacement token with the same name and scope (or
+regenerates the token secret), publishes the new secret to sinks, verifies
+the new token and only then revokes or deletes the old token. If publishing
+or verification fails, sinks are restored and the replacement token is deleted.
+
+Example of rotating a token into a file and the Docker config:
+
+	result, err := rotation.Rotate(ctx, client, tokenID, &rotation.Opts{
+	    Sinks: []rotation.Sink{
+	        &rotation.FileSink{Path: "/etc/craas/token"},
+	        &rotation.DockerConfigSink{Path: dockerConfigPath, Host: dockerconfig.DefaultRegistryHost},
+	    },
+	})
+	if err != nil {
+	    log.Fatal(err)
+	}
+	fmt.Printf("New token ID: %s", result.New.ID)
+*/
+package rotation
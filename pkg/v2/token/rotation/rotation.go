@@ -0,0 +1,259 @@
+package rotation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/selectel/craas-go/pkg/v2/client"
+	tokenv2 "github.com/selectel/craas-go/pkg/v2/token"
+)
+
+// Strategy represents a way the new token secret is obtained.
+type Strategy string
+
+const (
+	// StrategyReplace creates a new token with the same name and scope.
+	// The old token stays valid until the new one is published and verified,
+	// so the rotation can be rolled back.
+	StrategyReplace Strategy = "replace"
+
+	// StrategyRegenerate regenerates the secret of the existing token.
+	// The old secret is invalidated immediately, so the rotation can't be
+	// rolled back after the token is regenerated.
+	StrategyRegenerate Strategy = "regenerate"
+)
+
+// OldTokenAction represents what is done with the old token after a rotation.
+type OldTokenAction string
+
+const (
+	OldTokenRevoke OldTokenAction = "revoke"
+	OldTokenDelete OldTokenAction = "delete"
+	OldTokenKeep   OldTokenAction = "keep"
+)
+
+var (
+	ErrTokenIDEmpty      = errors.New("token id is empty")
+	ErrInvalidStrategy   = errors.New("invalid rotation strategy")
+	ErrInvalidOldAction  = errors.New("invalid old token action")
+	ErrTokenNotActive    = errors.New("new token is not active")
+	ErrTokenSecretAbsent = errors.New("new token has no secret")
+)
+
+// Opts represents options of a token rotation.
+type Opts struct {
+	// Strategy is a way to obtain the new secret, StrategyReplace is used if not set.
+	Strategy Strategy
+
+	// Expiration of the new token. If not set, the replacement token gets
+	// the same lifetime as the old one.
+	Expiration *tokenv2.Expiration
+
+	// Sinks receive the new token secret.
+	Sinks []Sink
+
+	// Verify is an optional function called to check the published token
+	// in addition to checking its status with the API.
+	Verify func(ctx context.Context, tkn *tokenv2.TokenV2) error
+
+	// OldTokenAction is what happens to the old token after a successful
+	// StrategyReplace rotation, OldTokenRevoke is used if not set.
+	OldTokenAction OldTokenAction
+}
+
+// Result represents a result of a successful rotation.
+type Result struct {
+	// Old is the token before the rotation.
+	Old *tokenv2.TokenV2
+
+	// New is the token after the rotation, it contains the new secret.
+	New *tokenv2.TokenV2
+}
+
+// Rotate rotates the token by its ID and publishes the new secret to sinks.
+func Rotate(ctx context.Context, client *client.ServiceClient, tokenID string, opts *Opts) (*Result, error) {
+	if tokenID == "" {
+		return nil, ErrTokenIDEmpty
+	}
+	if opts == nil {
+		opts = &Opts{}
+	}
+	strategy := opts.Strategy
+	if strategy == "" {
+		strategy = StrategyReplace
+	}
+	oldAction := opts.OldTokenAction
+	if oldAction == "" {
+		oldAction = OldTokenRevoke
+	}
+	switch oldAction {
+	case OldTokenRevoke, OldTokenDelete, OldTokenKeep:
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrInvalidOldAction, oldAction)
+	}
+
+	old, _, err := tokenv2.GetByID(ctx, client, tokenID)
+	if err != nil {
+		return nil, err
+	}
+	expiration := newExpiration(old, opts.Expiration, time.Now())
+
+	switch strategy {
+	case StrategyReplace:
+		return replace(ctx, client, old, expiration, opts, oldAction)
+	case StrategyRegenerate:
+		return regenerate(ctx, client, old, expiration, opts)
+	}
+
+	return nil, fmt.Errorf("%w: %s", ErrInvalidStrategy, strategy)
+}
+
+// replace creates a replacement token and removes the old one after the new
+// one is published and verified.
+func replace(
+	ctx context.Context,
+	client *client.ServiceClient,
+	old *tokenv2.TokenV2,
+	expiration tokenv2.Expiration,
+	opts *Opts,
+	oldAction OldTokenAction,
+) (*Result, error) {
+	created, _, err := tokenv2.Create(ctx, client, &tokenv2.TokenV2{
+		Name:       old.Name,
+		Scope:      old.Scope,
+		Expiration: expiration,
+	}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create a replacement token: %w", err)
+	}
+
+	restores, err := publish(ctx, created, opts.Sinks)
+	if err == nil {
+		err = verify(ctx, client, created, opts.Verify)
+	}
+	if err != nil {
+		rollbackErr := rollback(ctx, restores)
+		if _, deleteErr := tokenv2.Delete(ctx, client, created.ID); deleteErr != nil && rollbackErr == nil {
+			rollbackErr = fmt.Errorf("unable to delete the replacement token %s: %w", created.ID, deleteErr)
+		}
+		if rollbackErr != nil {
+			return nil, fmt.Errorf("%w; rollback failed: %v", err, rollbackErr)
+		}
+
+		return nil, err
+	}
+
+	switch oldAction {
+	case OldTokenRevoke:
+		_, err = tokenv2.Revoke(ctx, client, old.ID)
+	case OldTokenDelete:
+		_, err = tokenv2.Delete(ctx, client, old.ID)
+	case OldTokenKeep:
+	}
+	if err != nil {
+		return &Result{Old: old, New: created}, fmt.Errorf("new token is published, but the old token wasn't removed: %w", err)
+	}
+
+	return &Result{Old: old, New: created}, nil
+}
+
+// regenerate regenerates the token secret and publishes it.
+func regenerate(
+	ctx context.Context,
+	client *client.ServiceClient,
+	old *tokenv2.TokenV2,
+	expiration tokenv2.Expiration,
+	opts *Opts,
+) (*Result, error) {
+	regenerated, _, err := tokenv2.Regenerate(ctx, client, old.ID, expiration)
+	if err != nil {
+		return nil, fmt.Errorf("unable to regenerate the token: %w", err)
+	}
+
+	// The old secret is invalid now, so sinks are not restored on failure.
+	if _, err := publish(ctx, regenerated, opts.Sinks); err != nil {
+		return &Result{Old: old, New: regenerated}, err
+	}
+	if err := verify(ctx, client, regenerated, opts.Verify); err != nil {
+		return &Result{Old: old, New: regenerated}, err
+	}
+
+	return &Result{Old: old, New: regenerated}, nil
+}
+
+// publish writes the token to sinks and returns restore functions
+// of the sinks written so far, including the one that failed,
+// since it may be left partially written.
+func publish(ctx context.Context, tkn *tokenv2.TokenV2, sinks []Sink) ([]RestoreFunc, error) {
+	if tkn.Token == "" {
+		return nil, ErrTokenSecretAbsent
+	}
+
+	restores := make([]RestoreFunc, 0, len(sinks))
+	for i, sink := range sinks {
+		restore, err := sink.Write(ctx, tkn)
+		if restore != nil {
+			restores = append(restores, restore)
+		}
+		if err != nil {
+			return restores, fmt.Errorf("unable to write sink %d: %w", i, err)
+		}
+	}
+
+	return restores, nil
+}
+
+// verify checks that the new token is active and passes the custom check.
+func verify(
+	ctx context.Context,
+	client *client.ServiceClient,
+	tkn *tokenv2.TokenV2,
+	custom func(ctx context.Context, tkn *tokenv2.TokenV2) error,
+) error {
+	got, _, err := tokenv2.GetByID(ctx, client, tkn.ID)
+	if err != nil {
+		return fmt.Errorf("unable to verify the new token: %w", err)
+	}
+	if got.Status != tokenv2.StatusActive {
+		return fmt.Errorf("%w: %s", ErrTokenNotActive, got.Status)
+	}
+	if custom != nil {
+		if err := custom(ctx, tkn); err != nil {
+			return fmt.Errorf("new token verification failed: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// rollback restores sinks in the reverse order.
+func rollback(ctx context.Context, restores []RestoreFunc) error {
+	var firstErr error
+	for i := len(restores) - 1; i >= 0; i-- {
+		if err := restores[i](ctx); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	return firstErr
+}
+
+// newExpiration returns the expiration of the rotated token.
+func newExpiration(old *tokenv2.TokenV2, expiration *tokenv2.Expiration, now time.Time) tokenv2.Expiration {
+	if expiration != nil {
+		return *expiration
+	}
+	if !old.Expiration.IsSet {
+		return tokenv2.Expiration{}
+	}
+	if old.CreatedAt == nil {
+		return old.Expiration
+	}
+
+	return tokenv2.Expiration{
+		IsSet:     true,
+		ExpiresAt: now.Add(old.Expiration.ExpiresAt.Sub(*old.CreatedAt)).UTC(),
+	}
+}
@@ -0,0 +1,197 @@
+package rotation
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/selectel/craas-go/pkg/atomicfile"
+	"github.com/selectel/craas-go/pkg/dockerconfig"
+	tokenv2 "github.com/selectel/craas-go/pkg/v2/token"
+)
+
+// RestoreFunc restores a sink to the state it had before a write.
+type RestoreFunc func(ctx context.Context) error
+
+// Sink publishes a token secret to its consumers.
+type Sink interface {
+	// Write publishes the token and returns a function that restores
+	// the previous state of the sink. The returned function may be nil.
+	// It's also returned with an error if the sink may be partially written.
+	Write(ctx context.Context, tkn *tokenv2.TokenV2) (RestoreFunc, error)
+}
+
+var ErrSinkPathEmpty = errors.New("sink path is empty")
+
+// FileSink writes a raw token secret into a file.
+type FileSink struct {
+	// Path is a path of the file.
+	Path string
+
+	// Mode is a permission mode of a created file, 0600 is used if not set.
+	// An existing file keeps its mode.
+	Mode fs.FileMode
+}
+
+// Write writes the token secret into the file.
+func (s *FileSink) Write(_ context.Context, tkn *tokenv2.TokenV2) (RestoreFunc, error) {
+	if s.Path == "" {
+		return nil, ErrSinkPathEmpty
+	}
+
+	return writeFile(s.Path, s.Mode, []byte(tkn.Token))
+}
+
+// DockerConfigSink merges the token credentials into a Docker config file.
+type DockerConfigSink struct {
+	// Path is a path of the Docker config file.
+	Path string
+
+	// Host is a registry host, dockerconfig.DefaultRegistryHost is used if not set.
+	Host string
+}
+
+// Write merges the token credentials into the Docker config file.
+func (s *DockerConfigSink) Write(_ context.Context, tkn *tokenv2.TokenV2) (RestoreFunc, error) {
+	if s.Path == "" {
+		return nil, ErrSinkPathEmpty
+	}
+	host := s.Host
+	if host == "" {
+		host = dockerconfig.DefaultRegistryHost
+	}
+	cfg, err := dockerconfig.New(host, tkn)
+	if err != nil {
+		return nil, err
+	}
+
+	restore, err := snapshotFile(s.Path)
+	if err != nil {
+		return nil, err
+	}
+	if err := dockerconfig.MergeFile(s.Path, cfg); err != nil {
+		return restore, err
+	}
+
+	return restore, nil
+}
+
+// SecretSink writes a Kubernetes pull secret manifest with the token credentials.
+type SecretSink struct {
+	// Path is a path of the manifest file.
+	Path string
+
+	// Name is a name of the Secret.
+	Name string
+
+	// Namespace is an optional namespace of the Secret.
+	Namespace string
+
+	// Host is a registry host, dockerconfig.DefaultRegistryHost is used if not set.
+	Host string
+
+	// JSON switches the manifest format from YAML to JSON.
+	JSON bool
+}
+
+// Write renders the Secret manifest into the file.
+func (s *SecretSink) Write(_ context.Context, tkn *tokenv2.TokenV2) (RestoreFunc, error) {
+	if s.Path == "" {
+		return nil, ErrSinkPathEmpty
+	}
+	host := s.Host
+	if host == "" {
+		host = dockerconfig.DefaultRegistryHost
+	}
+	cfg, err := dockerconfig.New(host, tkn)
+	if err != nil {
+		return nil, err
+	}
+	secret, err := dockerconfig.NewSecret(s.Name, s.Namespace, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	var manifest []byte
+	if s.JSON {
+		manifest, err = secret.JSON()
+	} else {
+		manifest, err = secret.YAML()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return writeFile(s.Path, 0, manifest)
+}
+
+// CallbackSink passes the token to user-defined functions.
+type CallbackSink struct {
+	// OnWrite is called with the new token.
+	OnWrite func(ctx context.Context, tkn *tokenv2.TokenV2) error
+
+	// OnRestore is an optional function called when the rotation is rolled back.
+	OnRestore func(ctx context.Context) error
+}
+
+// Write calls the OnWrite function.
+func (s *CallbackSink) Write(ctx context.Context, tkn *tokenv2.TokenV2) (RestoreFunc, error) {
+	if s.OnWrite != nil {
+		if err := s.OnWrite(ctx, tkn); err != nil {
+			return s.OnRestore, err
+		}
+	}
+
+	return s.OnRestore, nil
+}
+
+// writeFile atomically replaces the file content and returns a function
+// restoring it. The mode is used for a created file, an existing file keeps its mode.
+func writeFile(path string, mode fs.FileMode, data []byte) (RestoreFunc, error) {
+	if mode == 0 {
+		mode = 0o600
+	}
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+	restore, err := snapshotFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, err
+	}
+	if err := atomicfile.WriteFile(path, data, mode); err != nil {
+		return restore, err
+	}
+
+	return restore, nil
+}
+
+// snapshotFile returns a function restoring the current file content.
+// A file that doesn't exist is removed on restore.
+func snapshotFile(path string) (RestoreFunc, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return func(context.Context) error {
+			if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+				return err
+			}
+
+			return nil
+		}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(context.Context) error {
+		return atomicfile.WriteFile(path, data, info.Mode().Perm())
+	}, nil
+}
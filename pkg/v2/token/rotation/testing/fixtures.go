@@ -0,0 +1,74 @@
+package testing
+
+const (
+	testOldTokenID = "c29e3f63-0711-4772-a415-ad79973bdaef"
+	testNewTokenID = "7d1c1c5e-3b0e-4ad4-9e6b-2a6f1f0a2c55"
+	testNewSecret  = "CRgAAAAAWiMnwN63eyASwQk8a3DBPRPirT9fWQTx"
+)
+
+const testGetOldTokenResponseRaw = `{
+  "id": "c29e3f63-0711-4772-a415-ad79973bdaef",
+  "name": "ci-token",
+  "createdAt": "2023-02-13T15:00:00Z",
+  "expiration": {
+    "isSet": false
+  },
+  "scope": {
+    "modeRW": true,
+    "allRegistries": false,
+    "registryIds": [
+      "888af692-c646-4b76-a234-81ca9b5bcafe"
+    ]
+  },
+  "status": "active"
+}`
+
+const testCreateNewTokenRequestRaw = `{
+  "name": "ci-token",
+  "expiration": {
+    "isSet": false,
+    "expiresAt": "0001-01-01T00:00:00Z"
+  },
+  "scope": {
+    "modeRW": true,
+    "allRegistries": false,
+    "registryIds": [
+      "888af692-c646-4b76-a234-81ca9b5bcafe"
+    ]
+  }
+}`
+
+const testCreateNewTokenResponseRaw = `{
+  "id": "7d1c1c5e-3b0e-4ad4-9e6b-2a6f1f0a2c55",
+  "name": "ci-token",
+  "createdAt": "2023-03-13T15:00:00Z",
+  "expiration": {
+    "isSet": false
+  },
+  "scope": {
+    "modeRW": true,
+    "allRegistries": false,
+    "registryIds": [
+      "888af692-c646-4b76-a234-81ca9b5bcafe"
+    ]
+  },
+  "status": "active",
+  "token": "CRgAAAAAWiMnwN63eyASwQk8a3DBPRPirT9fWQTx"
+}`
+
+const testGetNewTokenResponseRaw = `{
+  "id": "7d1c1c5e-3b0e-4ad4-9e6b-2a6f1f0a2c55",
+  "name": "ci-token",
+  "createdAt": "2023-03-13T15:00:00Z",
+  "expiration": {
+    "isSet": false
+  },
+  "scope": {
+    "modeRW": true,
+    "allRegistries": false,
+    "registryIds": [
+      "888af692-c646-4b76-a234-81ca9b5bcafe"
+    ]
+  },
+  "status": "active"
+}`
@@ -0,0 +1,236 @@
+package testing
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/selectel/craas-go/pkg/testutils"
+	"github.com/selectel/craas-go/pkg/v2/client"
+	tokenV2 "github.com/selectel/craas-go/pkg/v2/token"
+	"github.com/selectel/craas-go/pkg/v2/token/rotation"
+)
+
+const apiV2 = "/api/v2"
+
+// rotationCalls holds call flags of the rotation endpoints.
+type rotationCalls struct {
+	getOld, create, getNew, revokeOld, deleteNew bool
+}
+
+func setupRotationHandlers(t *testing.T, testEnv *testutils.TestEnv, calls *rotationCalls) {
+	testutils.HandleReqWithoutBody(t, &testutils.HandleReqOpts{
+		Mux:         testEnv.Mux,
+		URL:         apiV2 + "/tokens/" + testOldTokenID,
+		RawResponse: testGetOldTokenResponseRaw,
+		Method:      http.MethodGet,
+		Status:      http.StatusOK,
+		CallFlag:    &calls.getOld,
+	})
+	testutils.HandleReqWithBody(t, &testutils.HandleReqOpts{
+		Mux:         testEnv.Mux,
+		URL:         apiV2 + "/tokens",
+		RawRequest:  testCreateNewTokenRequestRaw,
+		RawResponse: testCreateNewTokenResponseRaw,
+		Method:      http.MethodPost,
+		Status:      http.StatusOK,
+		CallFlag:    &calls.create,
+	})
+	testEnv.Mux.HandleFunc(apiV2+"/tokens/"+testNewTokenID, func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			calls.getNew = true
+			w.Header().Add("Content-Type", "application/json")
+			_, _ = w.Write([]byte(testGetNewTokenResponseRaw))
+		case http.MethodDelete:
+			calls.deleteNew = true
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			t.Errorf("unexpected %s method", r.Method)
+		}
+	})
+	testutils.HandleReqWithoutBody(t, &testutils.HandleReqOpts{
+		Mux:      testEnv.Mux,
+		URL:      apiV2 + "/tokens/" + testOldTokenID + "/revoke",
+		Method:   http.MethodPost,
+		Status:   http.StatusNoContent,
+		CallFlag: &calls.revokeOld,
+	})
+}
+
+func TestRotate(t *testing.T) {
+	calls := &rotationCalls{}
+	testEnv := testutils.SetupTestEnv()
+	defer testEnv.TearDownTestEnv()
+	setupRotationHandlers(t, testEnv, calls)
+
+	dir := t.TempDir()
+	secretPath := filepath.Join(dir, "token")
+	dockerConfigPath := filepath.Join(dir, "config.json")
+	var callbackToken string
+
+	ctx := context.Background()
+	testClient, err := client.NewCRaaSClientV2(testutils.TokenID, testEnv.Server.URL+apiV2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := rotation.Rotate(ctx, testClient, testOldTokenID, &rotation.Opts{
+		Sinks: []rotation.Sink{
+			&rotation.FileSink{Path: secretPath},
+			&rotation.DockerConfigSink{Path: dockerConfigPath},
+			&rotation.CallbackSink{OnWrite: func(_ context.Context, tkn *tokenV2.TokenV2) error {
+				callbackToken = tkn.Token
+
+				return nil
+			}},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !calls.getOld || !calls.create || !calls.getNew || !calls.revokeOld {
+		t.Fatalf("expected endpoints to be called, but got %+v", calls)
+	}
+	if calls.deleteNew {
+		t.Fatal("replacement token must not be deleted")
+	}
+	if result.Old.ID != testOldTokenID || result.New.ID != testNewTokenID {
+		t.Fatalf("unexpected rotation result %+v", result)
+	}
+
+	secret, err := os.ReadFile(secretPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(secret) != testNewSecret {
+		t.Fatalf("expected %s secret in the file, but got %s", testNewSecret, secret)
+	}
+	dockerConfig, err := os.ReadFile(dockerConfigPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(dockerConfig), testNewSecret) {
+		t.Fatalf("expected docker config to contain the new secret, but got %s", dockerConfig)
+	}
+	if callbackToken != testNewSecret {
+		t.Fatalf("expected callback to receive %s, but got %s", testNewSecret, callbackToken)
+	}
+}
+
+func TestRotateRollback(t *testing.T) {
+	calls := &rotationCalls{}
+	testEnv := testutils.SetupTestEnv()
+	defer testEnv.TearDownTestEnv()
+	setupRotationHandlers(t, testEnv, calls)
+
+	secretPath := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(secretPath, []byte("old-secret"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	errVerify := errors.New("registry login failed")
+
+	ctx := context.Background()
+	testClient, err := client.NewCRaaSClientV2(testutils.TokenID, testEnv.Server.URL+apiV2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = rotation.Rotate(ctx, testClient, testOldTokenID, &rotation.Opts{
+		Sinks: []rotation.Sink{
+			&rotation.FileSink{Path: secretPath},
+		},
+		Verify: func(context.Context, *tokenV2.TokenV2) error {
+			return errVerify
+		},
+	})
+	if !errors.Is(err, errVerify) {
+		t.Fatalf("expected %v error, but got %v", errVerify, err)
+	}
+	if !calls.deleteNew {
+		t.Fatal("expected replacement token to be deleted")
+	}
+	if calls.revokeOld {
+		t.Fatal("old token must not be revoked after a failed rotation")
+	}
+
+	secret, err := os.ReadFile(secretPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(secret) != "old-secret" {
+		t.Fatalf("expected the file to be restored, but got %s", secret)
+	}
+}
+
+func TestRotateRestoresFailedSink(t *testing.T) {
+	calls := &rotationCalls{}
+	testEnv := testutils.SetupTestEnv()
+	defer testEnv.TearDownTestEnv()
+	setupRotationHandlers(t, testEnv, calls)
+
+	secretPath := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(secretPath, []byte("old-secret"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	errPublish := errors.New("secret store is unavailable")
+	restored := false
+
+	ctx := context.Background()
+	testClient, err := client.NewCRaaSClientV2(testutils.TokenID, testEnv.Server.URL+apiV2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = rotation.Rotate(ctx, testClient, testOldTokenID, &rotation.Opts{
+		Sinks: []rotation.Sink{
+			&rotation.FileSink{Path: secretPath},
+			&rotation.CallbackSink{
+				OnWrite: func(context.Context, *tokenV2.TokenV2) error {
+					return errPublish
+				},
+				OnRestore: func(context.Context) error {
+					restored = true
+
+					return nil
+				},
+			},
+		},
+	})
+	if !errors.Is(err, errPublish) {
+		t.Fatalf("expected %v error, but got %v", errPublish, err)
+	}
+	if !restored {
+		t.Fatal("expected the failed sink to be restored")
+	}
+	if !calls.deleteNew {
+		t.Fatal("expected replacement token to be deleted")
+	}
+
+	secret, err := os.ReadFile(secretPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(secret) != "old-secret" {
+		t.Fatalf("expected the file to be restored, but got %s", secret)
+	}
+}
+
+func TestRotateInvalidStrategy(t *testing.T) {
+	calls := &rotationCalls{}
+	testEnv := testutils.SetupTestEnv()
+	defer testEnv.TearDownTestEnv()
+	setupRotationHandlers(t, testEnv, calls)
+
+	testClient, err := client.NewCRaaSClientV2(testutils.TokenID, testEnv.Server.URL+apiV2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = rotation.Rotate(context.Background(), testClient, testOldTokenID, &rotation.Opts{
+		Strategy: "unknown",
+	})
+	if !errors.Is(err, rotation.ErrInvalidStrategy) {
+		t.Fatalf("expected %v error, but got %v", rotation.ErrInvalidStrategy, err)
+	}
+}
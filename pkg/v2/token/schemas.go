@@ -1,6 +1,9 @@
 package tokenv2
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
 
 type TokenV2 struct {
 	ID         string     `json:"id,omitempty"`
@@ -8,9 +11,108 @@ type TokenV2 struct {
 	CreatedAt  *time.Time `json:"createdAt,omitempty"`
 	Expiration Expiration `json:"expiration"`
 	Scope      Scope      `json:"scope"`
-	Status     string     `json:"status,omitempty"`
+	Status     Status     `json:"status,omitempty"`
 	Token      string     `json:"token,omitempty"`
 	LastUsedAt *time.Time `json:"lastUsedAt,omitempty"`
+
+	// DockerConfig is a docker config payload returned when the token is
+	// created with the docker-config flag, see CreateWithDockerConfig.
+	DockerConfig json.RawMessage `json:"dockerConfig,omitempty"`
+}
+
+// Status represents a custom type for various token statuses.
+type Status string
+
+const (
+	StatusActive  Status = "active"
+	StatusExpired Status = "expired"
+	StatusRevoked Status = "revoked"
+	StatusUnknown Status = "unknown"
+)
+
+func getSupportedStatuses() []Status {
+	return []Status{
+		StatusActive,
+		StatusExpired,
+		StatusRevoked,
+	}
+}
+
+func isStatusSupported(s Status) bool {
+	for _, v := range getSupportedStatuses() {
+		if s == v {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (result *TokenV2) UnmarshalJSON(b []byte) error {
+	type tmp TokenV2
+	var s struct {
+		tmp
+	}
+
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+
+	*result = TokenV2(s.tmp)
+
+	// Check token status, an empty status is kept for tokens in requests.
+	if s.tmp.Status != "" && !isStatusSupported(s.tmp.Status) {
+		result.Status = StatusUnknown
+	}
+
+	return nil
+}
+
+// IsExpired reports whether the token is expired at the time.
+func (result *TokenV2) IsExpired(now time.Time) bool {
+	if result.Status == StatusExpired {
+		return true
+	}
+
+	return result.Expiration.IsSet && !result.Expiration.ExpiresAt.After(now)
+}
+
+// FilterByStatus returns tokens with the status.
+func FilterByStatus(tokens []TokenV2, status Status) []TokenV2 {
+	filtered := make([]TokenV2, 0, len(tokens))
+	for _, t := range tokens {
+		if t.Status == status {
+			filtered = append(filtered, t)
+		}
+	}
+
+	return filtered
+}
+
+// ActiveOnly returns active tokens that are not expired yet.
+func ActiveOnly(tokens []TokenV2) []TokenV2 {
+	now := time.Now()
+	filtered := make([]TokenV2, 0, len(tokens))
+	for i := range tokens {
+		if tokens[i].Status == StatusActive && !tokens[i].IsExpired(now) {
+			filtered = append(filtered, tokens[i])
+		}
+	}
+
+	return filtered
+}
+
+// ExpiredOnly returns tokens that are expired by status or expiration time.
+func ExpiredOnly(tokens []TokenV2) []TokenV2 {
+	now := time.Now()
+	filtered := make([]TokenV2, 0, len(tokens))
+	for i := range tokens {
+		if tokens[i].IsExpired(now) {
+			filtered = append(filtered, tokens[i])
+		}
+	}
+
+	return filtered
 }
 
 type Scope struct {
@@ -28,3 +130,12 @@ type TokensV2 struct {
 	Tokens     []TokenV2 `json:"tokens"`
 	TotalCount int64     `json:"totalCount"`
 }
+
+// RegistryUsername is a username used to log in to a registry with a token.
+const RegistryUsername = "token"
+
+// RegistryCredentials returns username and password to log in to a registry
+// with the token.
+func (t *TokenV2) RegistryCredentials() (username, password string) {
+	return RegistryUsername, t.Token
+}
@@ -0,0 +1,60 @@
+package tokenv2
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestTokenV2_UnmarshalJSONStatus(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want Status
+	}{
+		{raw: `{"status": "active"}`, want: StatusActive},
+		{raw: `{"status": "revoked"}`, want: StatusRevoked},
+		{raw: `{"status": "suspended"}`, want: StatusUnknown},
+		{raw: `{}`, want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			var token TokenV2
+			if err := json.Unmarshal([]byte(tt.raw), &token); err != nil {
+				t.Fatal(err)
+			}
+			if token.Status != tt.want {
+				t.Errorf("Status = %v, want %v", token.Status, tt.want)
+			}
+		})
+	}
+}
+
+func TestActiveAndExpiredOnly(t *testing.T) {
+	past := time.Now().Add(-time.Hour)
+	future := time.Now().Add(time.Hour)
+	tokens := []TokenV2{
+		{ID: "active", Status: StatusActive, Expiration: Expiration{IsSet: true, ExpiresAt: future}},
+		{ID: "active-forever", Status: StatusActive},
+		{ID: "outdated", Status: StatusActive, Expiration: Expiration{IsSet: true, ExpiresAt: past}},
+		{ID: "expired", Status: StatusExpired},
+		{ID: "revoked", Status: StatusRevoked},
+	}
+
+	ids := func(tokens []TokenV2) []string {
+		result := make([]string, 0, len(tokens))
+		for _, t := range tokens {
+			result = append(result, t.ID)
+		}
+
+		return result
+	}
+	if got := ids(ActiveOnly(tokens)); len(got) != 2 || got[0] != "active" || got[1] != "active-forever" {
+		t.Errorf("ActiveOnly() = %v", got)
+	}
+	if got := ids(ExpiredOnly(tokens)); len(got) != 2 || got[0] != "outdated" || got[1] != "expired" {
+		t.Errorf("ExpiredOnly() = %v", got)
+	}
+	if got := ids(FilterByStatus(tokens, StatusRevoked)); len(got) != 1 || got[0] != "revoked" {
+		t.Errorf("FilterByStatus() = %v", got)
+	}
+}
@@ -0,0 +1,145 @@
+package tokenv2
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	clientv1 "github.com/selectel/craas-go/pkg/v1/client"
+	"github.com/selectel/craas-go/pkg/v1/registry"
+)
+
+var (
+	ErrScopeConflict        = errors.New("token scope can't include all registries and specific registries at once")
+	ErrScopeNamesUnresolved = errors.New("token scope registry names must be resolved with the registry API")
+	ErrRegistryNotFound     = errors.New("registry not found")
+)
+
+// Validate checks that the scope combination is accepted by the API.
+func (s Scope) Validate() error {
+	if s.AllRegistries && len(s.RegistryIDs) != 0 {
+		return ErrScopeConflict
+	}
+	if !s.AllRegistries && len(s.RegistryIDs) == 0 {
+		return ErrScopeRegistriesEmpty
+	}
+
+	return nil
+}
+
+// ScopeBuilder builds a token scope. Scopes are read-only unless ReadWrite is called.
+type ScopeBuilder struct {
+	modeRW        bool
+	allRegistries bool
+	registryIDs   []string
+	registryNames []string
+}
+
+// NewScopeBuilder returns a builder of a read-only scope without registries.
+func NewScopeBuilder() *ScopeBuilder {
+	return &ScopeBuilder{}
+}
+
+// ReadOnly sets the pull-only access mode.
+func (b *ScopeBuilder) ReadOnly() *ScopeBuilder {
+	b.modeRW = false
+
+	return b
+}
+
+// ReadWrite sets the pull and push access mode.
+func (b *ScopeBuilder) ReadWrite() *ScopeBuilder {
+	b.modeRW = true
+
+	return b
+}
+
+// AllRegistries grants access to all registries of the project.
+func (b *ScopeBuilder) AllRegistries() *ScopeBuilder {
+	b.allRegistries = true
+
+	return b
+}
+
+// ForRegistries grants access to registries by their IDs.
+func (b *ScopeBuilder) ForRegistries(registryIDs ...string) *ScopeBuilder {
+	b.registryIDs = append(b.registryIDs, registryIDs...)
+
+	return b
+}
+
+// ForRegistryNames grants access to registries by their names.
+// Names are resolved to IDs by Resolve.
+func (b *ScopeBuilder) ForRegistryNames(names ...string) *ScopeBuilder {
+	b.registryNames = append(b.registryNames, names...)
+
+	return b
+}
+
+// Build returns the validated scope without checking that registries exist.
+// ErrScopeNamesUnresolved is returned if registry names are used.
+func (b *ScopeBuilder) Build() (Scope, error) {
+	if len(b.registryNames) != 0 {
+		return Scope{}, ErrScopeNamesUnresolved
+	}
+
+	return b.build(b.registryIDs)
+}
+
+// Resolve returns the validated scope, resolving registry names to IDs and
+// checking that all registries exist with the v1 registry API.
+func (b *ScopeBuilder) Resolve(ctx context.Context, client *clientv1.ServiceClient) (Scope, error) {
+	if b.allRegistries || (len(b.registryIDs) == 0 && len(b.registryNames) == 0) {
+		// Validation fails or there is nothing to check.
+		return b.build(append(b.registryIDs, b.registryNames...))
+	}
+
+	registries, _, err := registry.List(ctx, client)
+	if err != nil {
+		return Scope{}, err
+	}
+	idsByName := make(map[string]string, len(registries))
+	existing := make(map[string]struct{}, len(registries))
+	for _, r := range registries {
+		idsByName[r.Name] = r.ID
+		existing[r.ID] = struct{}{}
+	}
+
+	registryIDs := make([]string, 0, len(b.registryIDs)+len(b.registryNames))
+	for _, id := range b.registryIDs {
+		if _, ok := existing[id]; !ok {
+			return Scope{}, fmt.Errorf("%w: %s", ErrRegistryNotFound, id)
+		}
+		registryIDs = append(registryIDs, id)
+	}
+	for _, name := range b.registryNames {
+		id, ok := idsByName[name]
+		if !ok {
+			return Scope{}, fmt.Errorf("%w: %s", ErrRegistryNotFound, name)
+		}
+		registryIDs = append(registryIDs, id)
+	}
+
+	return b.build(registryIDs)
+}
+
+// build returns the scope with the registry IDs after validation.
+func (b *ScopeBuilder) build(registryIDs []string) (Scope, error) {
+	scope := Scope{
+		ModeRW:        b.modeRW,
+		AllRegistries: b.allRegistries,
+	}
+	seen := make(map[string]struct{}, len(registryIDs))
+	for _, id := range registryIDs {
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		scope.RegistryIDs = append(scope.RegistryIDs, id)
+	}
+	if err := scope.Validate(); err != nil {
+		return Scope{}, err
+	}
+
+	return scope, nil
+}
@@ -0,0 +1,113 @@
+package testing
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/selectel/craas-go/pkg/dockerconfig"
+	"github.com/selectel/craas-go/pkg/testutils"
+	"github.com/selectel/craas-go/pkg/v2/client"
+	tokenV2 "github.com/selectel/craas-go/pkg/v2/token"
+)
+
+func TestCreateWithDockerConfig(t *testing.T) {
+	endpointCalled := false
+	testEnv := testutils.SetupTestEnv()
+	defer testEnv.TearDownTestEnv()
+
+	testEnv.Mux.HandleFunc("/api/v2/tokens", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Errorf("expected %s method but got %s", http.MethodPost, r.Method)
+		}
+		if r.URL.Query().Get("docker-config") != "true" {
+			t.Errorf("expected docker-config query flag, but got %s", r.URL.RawQuery)
+		}
+		endpointCalled = true
+		w.Header().Add("Content-Type", "application/json")
+		fmt.Fprint(w, testCreateTokenWithDockerConfigResponseRaw)
+	})
+
+	mergePath := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(mergePath, []byte(`{"credsStore": "desktop"}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	testClient, err := client.NewCRaaSClientV2(testutils.TokenID, testEnv.Server.URL+apiV2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, cfg, _, err := tokenV2.CreateWithDockerConfig(ctx, testClient, &tokenV2.TokenV2{
+		Name:       "my-token",
+		Expiration: Exp,
+		Scope:      tokenV2.Scope{ModeRW: true, AllRegistries: true},
+	}, &tokenV2.DockerConfigOpts{MergePath: mergePath})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !endpointCalled {
+		t.Fatal("endpoint wasn't called")
+	}
+	if token.Token != testTokenID {
+		t.Fatalf("expected %s token, but got %s", testTokenID, token.Token)
+	}
+	if !reflect.DeepEqual(expectedDockerConfig, cfg) {
+		t.Fatalf("expected %#v, but got %#v", expectedDockerConfig, cfg)
+	}
+
+	data, err := os.ReadFile(mergePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var merged struct {
+		CredsStore string `json:"credsStore"`
+		dockerconfig.Config
+	}
+	if err := json.Unmarshal(data, &merged); err != nil {
+		t.Fatal(err)
+	}
+	if merged.CredsStore != "desktop" {
+		t.Fatalf("expected credsStore to be preserved, but got %s", data)
+	}
+	if !reflect.DeepEqual(expectedDockerConfig.Auths, merged.Auths) {
+		t.Fatalf("expected %#v, but got %#v", expectedDockerConfig.Auths, merged.Auths)
+	}
+}
+
+func TestCreateWithDockerConfigWithoutPayload(t *testing.T) {
+	endpointCalled := false
+	testEnv := testutils.SetupTestEnv()
+	defer testEnv.TearDownTestEnv()
+
+	testEnv.Mux.HandleFunc("/api/v2/tokens", func(w http.ResponseWriter, r *http.Request) {
+		endpointCalled = true
+		w.Header().Add("Content-Type", "application/json")
+		fmt.Fprint(w, testCreateTokenResponseRaw)
+	})
+
+	ctx := context.Background()
+	testClient, err := client.NewCRaaSClientV2(testutils.TokenID, testEnv.Server.URL+apiV2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, cfg, _, err := tokenV2.CreateWithDockerConfig(ctx, testClient, &tokenV2.TokenV2{
+		Name:       "my-token",
+		Expiration: Exp,
+		Scope:      Scope,
+	}, &tokenV2.DockerConfigOpts{Host: "cr.example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !endpointCalled {
+		t.Fatal("endpoint wasn't called")
+	}
+	if !reflect.DeepEqual(expectedBuiltDockerConfig, cfg) {
+		t.Fatalf("expected %#v, but got %#v", expectedBuiltDockerConfig, cfg)
+	}
+}
@@ -3,6 +3,7 @@ package testing
 import (
 	"time"
 
+	"github.com/selectel/craas-go/pkg/dockerconfig"
 	tokenV2 "github.com/selectel/craas-go/pkg/v2/token"
 )
 
@@ -191,3 +192,103 @@ var expectedListTokenResponse = &tokenV2.TokensV2{
 		},
 	},
 }
+
+// testPatchTokenNameRequestRaw represents a raw request updating only a name.
+const testPatchTokenNameRequestRaw = `{
+  "name": "token"
+}`
+
+// testGetTokenSingleRegistryResponseRaw represents a raw token get response
+// with a single registry in the scope.
+const testGetTokenSingleRegistryResponseRaw = `{
+  "id": "c29e3f63-0711-4772-a415-ad79973bdaef",
+  "name": "token",
+  "createdAt": "2023-02-13T15:00:00Z",
+  "expiration": {
+    "isSet": true,
+    "expiresAt": "2030-01-01T00:00:00Z"
+  },
+  "scope": {
+    "modeRW": true,
+    "allRegistries": false,
+    "registryIds": [
+      "888af692-c646-4b76-a234-81ca9b5bcafe"
+    ]
+  },
+  "lastUsedAt": "2023-02-14T15:25:10Z",
+  "status": "active"
+}`
+
+// testPatchTokenAddRegistryRequestRaw represents a raw request adding a registry
+// to the scope of the testGetTokenSingleRegistryResponseRaw token.
+const testPatchTokenAddRegistryRequestRaw = `{
+  "scope": {
+    "modeRW": true,
+    "allRegistries": false,
+    "registryIds": [
+      "888af692-c646-4b76-a234-81ca9b5bcafe",
+      "6303699d-c2cd-40b1-8428-9dcd6cc3d00d"
+    ]
+  }
+}`
+
+// testListRegistriesResponseRaw represents a raw v1 registries list response.
+const testListRegistriesResponseRaw = `[
+    {
+        "id": "888af692-c646-4b76-a234-81ca9b5bcafe",
+        "name": "production",
+        "createdAt": "2022-10-25T10:25:22.556Z",
+        "status": "ACTIVE",
+        "size": 0,
+        "sizeLimit": 21474836480,
+        "used": 0
+    },
+    {
+        "id": "6303699d-c2cd-40b1-8428-9dcd6cc3d00d",
+        "name": "staging",
+        "createdAt": "2022-10-25T10:25:22.556Z",
+        "status": "ACTIVE",
+        "size": 0,
+        "sizeLimit": 21474836480,
+        "used": 0
+    }
+]`
+
+// testCreateTokenWithDockerConfigResponseRaw represents a raw token create
+// response with a base64 encoded docker config.
+const testCreateTokenWithDockerConfigResponseRaw = `{
+  "id": "c29e3f63-0711-4772-a415-ad79973bdaef",
+  "name": "my-token",
+  "createdAt": "2023-02-13T15:00:00Z",
+  "expiration": {
+    "isSet": true,
+    "expiresAt": "2030-01-01T00:00:00Z"
+  },
+  "scope": {
+    "modeRW": true,
+    "allRegistries": true
+  },
+  "status": "active",
+  "token": "CRgAAAAAWiMnwN63eyASwQk8a3DBPRPirT9fWQTx",
+  "dockerConfig": "eyJhdXRocyI6eyJjci5zZWxjbG91ZC5ydSI6eyJhdXRoIjoiZEc5clpXNDZRMUpuUVVGQlFVRlhhVTF1ZDA0Mk0yVjVRVk4zVVdzNFlUTkVRbEJTVUdseVZEbG1WMUZVZUE9PSJ9fX0="
+}`
+
+var expectedDockerConfig = &dockerconfig.Config{
+	Auths: map[string]dockerconfig.AuthConfig{
+		"cr.selcloud.ru": {
+			Auth: "dG9rZW46Q1JnQUFBQUFXaU1ud042M2V5QVN3UWs4YTNEQlBSUGlyVDlmV1FUeA==",
+		},
+	},
+}
+
+// expectedBuiltDockerConfig represents a docker config built from the token
+// secret when the API response has no docker config.
+var expectedBuiltDockerConfig = &dockerconfig.Config{
+	Auths: map[string]dockerconfig.AuthConfig{
+		"cr.example.com": {
+			Username: "token",
+			Password: "CRgAAAAAWiMnwN63eyASwQk8a3DBPRPirT9fWQTx",
+			Auth:     "dG9rZW46Q1JnQUFBQUFXaU1ud042M2V5QVN3UWs4YTNEQlBSUGlyVDlmV1FUeA==",
+		},
+	},
+}
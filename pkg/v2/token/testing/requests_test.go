@@ -2,6 +2,8 @@ package testing
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"reflect"
@@ -160,3 +162,103 @@ func TestPatchToken(t *testing.T) {
 		t.Fatalf("expected %#v, but got %#v", expectedPatchTokenResponse, actual)
 	}
 }
+
+func TestPatchTokenWithOptsName(t *testing.T) {
+	endpointCalled := false
+	testEnv := testutils.SetupTestEnv()
+	defer testEnv.TearDownTestEnv()
+
+	testutils.HandleReqWithBody(t, &testutils.HandleReqOpts{
+		Mux:         testEnv.Mux,
+		URL:         fmt.Sprintf("/api/v2/tokens/%s", testTokenID),
+		RawRequest:  testPatchTokenNameRequestRaw,
+		RawResponse: testPatchTokenResponseRaw,
+		Method:      http.MethodPatch,
+		Status:      http.StatusOK,
+		CallFlag:    &endpointCalled,
+	})
+
+	ctx := context.Background()
+	testClient, err := client.NewCRaaSClientV2(testutils.TokenID, testEnv.Server.URL+apiV2)
+	if err != nil {
+		t.Errorf("got error %s", err)
+	}
+	actual, response, err := tokenV2.PatchWithOpts(ctx, testClient, testTokenID, new(tokenV2.PatchOpts).SetName("token"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !endpointCalled {
+		t.Fatal("endpoint wasn't called")
+	}
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("expected %d status in the HTTP response, but got %d", http.StatusOK, response.StatusCode)
+	}
+	if !reflect.DeepEqual(expectedPatchTokenResponse, actual) {
+		t.Fatalf("expected %#v, but got %#v", expectedPatchTokenResponse, actual)
+	}
+}
+
+func TestAddRegistryToScope(t *testing.T) {
+	getCalled := false
+	patchCalled := false
+	testEnv := testutils.SetupTestEnv()
+	defer testEnv.TearDownTestEnv()
+
+	testEnv.Mux.HandleFunc(fmt.Sprintf("/api/v2/tokens/%s", testTokenID), func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			getCalled = true
+			w.Header().Add("Content-Type", "application/json")
+			fmt.Fprint(w, testGetTokenSingleRegistryResponseRaw)
+		case http.MethodPatch:
+			if !getCalled {
+				t.Error("expected the token to be read before patching")
+				w.WriteHeader(http.StatusBadRequest)
+
+				return
+			}
+			patchCalled = true
+			var actualRequest, expectedRequest interface{}
+			if err := json.NewDecoder(r.Body).Decode(&actualRequest); err != nil {
+				t.Errorf("unable to unmarshal the request body: %v", err)
+			}
+			if err := json.Unmarshal([]byte(testPatchTokenAddRegistryRequestRaw), &expectedRequest); err != nil {
+				t.Errorf("unable to unmarshal expected raw request: %v", err)
+			}
+			if !reflect.DeepEqual(expectedRequest, actualRequest) {
+				t.Errorf("expected %#v request, but got %#v", expectedRequest, actualRequest)
+			}
+			w.Header().Add("Content-Type", "application/json")
+			fmt.Fprint(w, testPatchTokenResponseRaw)
+		default:
+			t.Errorf("unexpected %s method", r.Method)
+		}
+	})
+
+	ctx := context.Background()
+	testClient, err := client.NewCRaaSClientV2(testutils.TokenID, testEnv.Server.URL+apiV2)
+	if err != nil {
+		t.Errorf("got error %s", err)
+	}
+	actual, _, err := tokenV2.AddRegistryToScope(ctx, testClient, testTokenID, "6303699d-c2cd-40b1-8428-9dcd6cc3d00d")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !getCalled || !patchCalled {
+		t.Fatal("endpoint wasn't called")
+	}
+	if !reflect.DeepEqual(expectedPatchTokenResponse, actual) {
+		t.Fatalf("expected %#v, but got %#v", expectedPatchTokenResponse, actual)
+	}
+}
+
+func TestPatchTokenWithOptsEmpty(t *testing.T) {
+	testClient, err := client.NewCRaaSClientV2(testutils.TokenID, "http://localhost"+apiV2)
+	if err != nil {
+		t.Errorf("got error %s", err)
+	}
+	_, _, err = tokenV2.PatchWithOpts(context.Background(), testClient, testTokenID, &tokenV2.PatchOpts{})
+	if !errors.Is(err, tokenV2.ErrPatchOptsEmpty) {
+		t.Fatalf("expected %v error, but got %v", tokenV2.ErrPatchOptsEmpty, err)
+	}
+}
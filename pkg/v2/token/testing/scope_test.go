@@ -0,0 +1,106 @@
+package testing
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/selectel/craas-go/pkg/testutils"
+	clientv1 "github.com/selectel/craas-go/pkg/v1/client"
+	tokenV2 "github.com/selectel/craas-go/pkg/v2/token"
+)
+
+func TestScopeBuilderResolve(t *testing.T) {
+	endpointCalled := false
+	testEnv := testutils.SetupTestEnv()
+	defer testEnv.TearDownTestEnv()
+
+	testutils.HandleReqWithoutBody(t, &testutils.HandleReqOpts{
+		Mux:         testEnv.Mux,
+		URL:         "/api/v1/registries",
+		RawResponse: testListRegistriesResponseRaw,
+		Method:      http.MethodGet,
+		Status:      http.StatusOK,
+		CallFlag:    &endpointCalled,
+	})
+
+	ctx := context.Background()
+	testClient, err := clientv1.NewCRaaSClientV1(testutils.TokenID, testEnv.Server.URL+"/api/v1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	actual, err := tokenV2.NewScopeBuilder().
+		ReadWrite().
+		ForRegistries("888af692-c646-4b76-a234-81ca9b5bcafe").
+		ForRegistryNames("staging").
+		Resolve(ctx, testClient)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !endpointCalled {
+		t.Fatal("endpoint wasn't called")
+	}
+	if !reflect.DeepEqual(Scope, actual) {
+		t.Fatalf("expected %#v, but got %#v", Scope, actual)
+	}
+
+	_, err = tokenV2.NewScopeBuilder().ForRegistryNames("missing").Resolve(ctx, testClient)
+	if !errors.Is(err, tokenV2.ErrRegistryNotFound) {
+		t.Fatalf("expected %v error, but got %v", tokenV2.ErrRegistryNotFound, err)
+	}
+}
+
+func TestScopeBuilderBuild(t *testing.T) {
+	tests := []struct {
+		name    string
+		builder *tokenV2.ScopeBuilder
+		want    tokenV2.Scope
+		wantErr error
+	}{
+		{
+			name:    "all registries read-only",
+			builder: tokenV2.NewScopeBuilder().ReadOnly().AllRegistries(),
+			want:    tokenV2.Scope{AllRegistries: true},
+		},
+		{
+			name:    "registries read-write",
+			builder: tokenV2.NewScopeBuilder().ReadWrite().ForRegistries("a", "b", "a"),
+			want:    tokenV2.Scope{ModeRW: true, RegistryIDs: []string{"a", "b"}},
+		},
+		{
+			name:    "conflict",
+			builder: tokenV2.NewScopeBuilder().AllRegistries().ForRegistries("a"),
+			wantErr: tokenV2.ErrScopeConflict,
+		},
+		{
+			name:    "empty",
+			builder: tokenV2.NewScopeBuilder(),
+			wantErr: tokenV2.ErrScopeRegistriesEmpty,
+		},
+		{
+			name:    "unresolved names",
+			builder: tokenV2.NewScopeBuilder().ForRegistryNames("production"),
+			wantErr: tokenV2.ErrScopeNamesUnresolved,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.builder.Build()
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Build() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && !reflect.DeepEqual(tt.want, got) {
+				t.Errorf("Build() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScopeValidate(t *testing.T) {
+	scope := tokenV2.Scope{AllRegistries: true, RegistryIDs: []string{"a"}}
+	if err := scope.Validate(); !errors.Is(err, tokenV2.ErrScopeConflict) {
+		t.Fatalf("expected %v error, but got %v", tokenV2.ErrScopeConflict, err)
+	}
+}
@@ -0,0 +1,31 @@
+/*
+Package `watch` provides a set of functions for turning periodic polls of
+registries, repositories, images and tokens into a stream of typed events.
+
+CRaaS doesn't send notifications, so a Watcher lists resources every interval
+and compares the result with the previous poll. The first poll without
+a saved state only records the current inventory. The state can be saved to
+a file to resume watching after a restart without losing changes.
+
+Example of printing events:
+
+	watcher, err := watch.NewWatcher(clientV1, clientV2, &watch.Opts{
+	    Interval:  time.Minute,
+	    StatePath: "/var/lib/craas/watch-state.json",
+	    OnError: func(err error) {
+	        log.Println(err)
+	    },
+	})
+	if err != nil {
+	    log.Fatal(err)
+	}
+	for event := range watcher.Watch(ctx) {
+	    switch event.Type {
+	    case watch.EventTagMoved:
+	        fmt.Printf("%s:%s moved to %s\n", event.Repository, event.Tag, event.Digest)
+	    case watch.EventTokenExpiringSoon:
+	        fmt.Printf("token %s expires at %s\n", event.TokenName, event.ExpiresAt)
+	    }
+	}
+*/
+package watch
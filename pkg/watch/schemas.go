@@ -0,0 +1,90 @@
+package watch
+
+import (
+	"time"
+
+	"github.com/selectel/craas-go/pkg/v1/registry"
+)
+
+// EventType represents a kind of change.
+type EventType string
+
+const (
+	EventRegistryCreated       EventType = "RegistryCreated"
+	EventRegistryStatusChanged EventType = "RegistryStatusChanged"
+	EventRegistryDeleted       EventType = "RegistryDeleted"
+	EventRepositoryAdded       EventType = "RepositoryAdded"
+	EventRepositoryDeleted     EventType = "RepositoryDeleted"
+	EventImagePushed           EventType = "ImagePushed"
+	EventTagMoved              EventType = "TagMoved"
+	EventImageDeleted          EventType = "ImageDeleted"
+	EventTokenExpiringSoon     EventType = "TokenExpiringSoon"
+)
+
+// Event represents a change found by comparing two polls.
+// Fields that don't apply to the event type are empty.
+type Event struct {
+	Type EventType `json:"type"`
+
+	// Time is a time of the poll that found the change.
+	Time time.Time `json:"time"`
+
+	RegistryID     string          `json:"registryId,omitempty"`
+	RegistryName   string          `json:"registryName,omitempty"`
+	Status         registry.Status `json:"status,omitempty"`
+	PreviousStatus registry.Status `json:"previousStatus,omitempty"`
+
+	Repository string `json:"repository,omitempty"`
+
+	// Digest is a digest of a pushed or deleted image or a digest a tag
+	// points to after it was moved.
+	Digest         string   `json:"digest,omitempty"`
+	PreviousDigest string   `json:"previousDigest,omitempty"`
+	Tag            string   `json:"tag,omitempty"`
+	Tags           []string `json:"tags,omitempty"`
+
+	TokenID   string     `json:"tokenId,omitempty"`
+	TokenName string     `json:"tokenName,omitempty"`
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+}
+
+// State represents the inventory seen by the last poll.
+type State struct {
+	// PolledAt is a time of the last poll.
+	PolledAt time.Time `json:"polledAt"`
+
+	// Registries are keyed by ID.
+	Registries map[string]*RegistryState `json:"registries"`
+
+	// Tokens are keyed by ID.
+	Tokens map[string]*TokenState `json:"tokens,omitempty"`
+
+	// SkipRepositories and SkipImages are options of the poll. A state saved
+	// with other options is a new baseline, since it lacks skipped resources.
+	SkipRepositories bool `json:"skipRepositories,omitempty"`
+	SkipImages       bool `json:"skipImages,omitempty"`
+}
+
+// RegistryState represents a registry seen by the last poll.
+type RegistryState struct {
+	Name   string          `json:"name"`
+	Status registry.Status `json:"status"`
+
+	// Repositories are keyed by name.
+	Repositories map[string]*RepositoryState `json:"repositories,omitempty"`
+}
+
+// RepositoryState represents a repository seen by the last poll.
+type RepositoryState struct {
+	// Images maps digests to tags.
+	Images map[string][]string `json:"images,omitempty"`
+}
+
+// TokenState represents an expiring token seen by the last poll.
+type TokenState struct {
+	Name      string    `json:"name"`
+	ExpiresAt time.Time `json:"expiresAt"`
+
+	// Notified is set once the TokenExpiringSoon event is sent.
+	Notified bool `json:"notified"`
+}
@@ -0,0 +1,39 @@
+package watch
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/selectel/craas-go/pkg/atomicfile"
+)
+
+// LoadState reads a state saved by a Watcher.
+// It returns nil without an error if the file doesn't exist.
+func LoadState(filename string) (*State, error) {
+	data, err := os.ReadFile(filename)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	state := &State{}
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, fmt.Errorf("unable to read %s: %w", filename, err)
+	}
+
+	return state, nil
+}
+
+// Save atomically writes the state to a file.
+func (s *State) Save(filename string) error {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+
+	return atomicfile.WriteFile(filename, data, 0o600)
+}
@@ -0,0 +1,121 @@
+package testing
+
+import (
+	"time"
+
+	"github.com/selectel/craas-go/pkg/watch"
+)
+
+const testRegistryID = "fc43e322-b084-4b3c-a04a-1ab2a28cd860"
+
+const testListRegistriesResponseRaw = `[
+    {
+        "id": "fc43e322-b084-4b3c-a04a-1ab2a28cd860",
+        "name": "test-registry",
+        "createdAt": "2022-10-25T10:25:22.556Z",
+        "status": "GARBAGE_COLLECTION",
+        "size": 56723502,
+        "sizeLimit": 21474836480,
+        "used": 0.26
+    }
+]`
+
+const testListRepositoriesResponseRaw = `[
+    {
+        "name": "nginx",
+        "size": 56723502,
+        "updatedAt": "2022-09-22T10:15:40.362702Z"
+    },
+    {
+        "name": "redis",
+        "size": 0,
+        "updatedAt": "2022-09-22T10:15:40.362702Z"
+    }
+]`
+
+const testListNginxImagesResponseRaw = `[
+    {
+        "createdAt": "2022-05-18T22:37:17.011072851Z",
+        "digest": "sha256:bbbb",
+        "layers": [],
+        "size": 25338790,
+        "tags": ["latest", "1.23"]
+    }
+]`
+
+const testListTokensResponseRaw = `{
+    "tokens": [
+        {
+            "id": "ae8d5a09-0d41-4c1e-a3dc-b48e3b4b1bd1",
+            "name": "ci",
+            "createdAt": "2023-01-01T00:00:00Z",
+            "expiration": {"isSet": true, "expiresAt": "2023-03-03T00:00:00Z"},
+            "scope": {"modeRW": true, "allRegistries": true},
+            "status": "active"
+        },
+        {
+            "id": "4f5b8a27-3d2c-4b44-9b42-2c0b3d1e3d60",
+            "name": "forever",
+            "createdAt": "2023-01-01T00:00:00Z",
+            "expiration": {"isSet": false},
+            "scope": {"modeRW": false, "allRegistries": true},
+            "status": "active"
+        }
+    ],
+    "totalCount": 2
+}`
+
+// testPreviousState is a state saved by a previous poll.
+const testPreviousState = `{
+    "polledAt": "2023-03-01T12:00:00Z",
+    "registries": {
+        "fc43e322-b084-4b3c-a04a-1ab2a28cd860": {
+            "name": "test-registry",
+            "status": "ACTIVE",
+            "repositories": {
+                "nginx": {"images": {"sha256:aaaa": ["1.22", "latest"]}},
+                "alpine": {"images": {"sha256:cccc": ["3.17"]}}
+            }
+        },
+        "0cb4d1cd-5e05-4a9c-9e1c-6bcb3b7ae1a1": {"name": "old-registry", "status": "ACTIVE"}
+    }
+}`
+
+var testPollTime = time.Date(2023, 3, 2, 12, 0, 0, 0, time.UTC)
+
+var testTokenExpiresAt = time.Date(2023, 3, 3, 0, 0, 0, 0, time.UTC)
+
+var expectedEvents = []*watch.Event{
+	{
+		Type: watch.EventRegistryStatusChanged, Time: testPollTime, RegistryID: testRegistryID,
+		RegistryName: "test-registry", Status: "GARBAGE_COLLECTION", PreviousStatus: "ACTIVE",
+	},
+	{
+		Type: watch.EventImagePushed, Time: testPollTime, RegistryID: testRegistryID,
+		RegistryName: "test-registry", Repository: "nginx", Digest: "sha256:bbbb", Tags: []string{"1.23", "latest"},
+	},
+	{
+		Type: watch.EventTagMoved, Time: testPollTime, RegistryID: testRegistryID, RegistryName: "test-registry",
+		Repository: "nginx", Tag: "latest", Digest: "sha256:bbbb", PreviousDigest: "sha256:aaaa",
+	},
+	{
+		Type: watch.EventImageDeleted, Time: testPollTime, RegistryID: testRegistryID,
+		RegistryName: "test-registry", Repository: "nginx", Digest: "sha256:aaaa", Tags: []string{"1.22", "latest"},
+	},
+	{
+		Type: watch.EventRepositoryAdded, Time: testPollTime, RegistryID: testRegistryID,
+		RegistryName: "test-registry", Repository: "redis",
+	},
+	{
+		Type: watch.EventRepositoryDeleted, Time: testPollTime, RegistryID: testRegistryID,
+		RegistryName: "test-registry", Repository: "alpine",
+	},
+	{
+		Type: watch.EventRegistryDeleted, Time: testPollTime,
+		RegistryID: "0cb4d1cd-5e05-4a9c-9e1c-6bcb3b7ae1a1", RegistryName: "old-registry",
+	},
+	{
+		Type: watch.EventTokenExpiringSoon, Time: testPollTime,
+		TokenID: "ae8d5a09-0d41-4c1e-a3dc-b48e3b4b1bd1", TokenName: "ci", ExpiresAt: &testTokenExpiresAt,
+	},
+}
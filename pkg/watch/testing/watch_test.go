@@ -0,0 +1,127 @@
+package testing
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/selectel/craas-go/pkg/testutils"
+	clientv1 "github.com/selectel/craas-go/pkg/v1/client"
+	clientv2 "github.com/selectel/craas-go/pkg/v2/client"
+	"github.com/selectel/craas-go/pkg/watch"
+)
+
+func setupTestHandlers(t *testing.T, testEnv *testutils.TestEnv) {
+	t.Helper()
+
+	for url, response := range map[string]string{
+		"/api/v1/registries": testListRegistriesResponseRaw,
+		"/api/v1/registries/" + testRegistryID + "/repositories":              testListRepositoriesResponseRaw,
+		"/api/v1/registries/" + testRegistryID + "/repositories/nginx/images": testListNginxImagesResponseRaw,
+		"/api/v1/registries/" + testRegistryID + "/repositories/redis/images": `[]`,
+		"/api/v2/tokens": testListTokensResponseRaw,
+	} {
+		called := false
+		testutils.HandleReqWithoutBody(t, &testutils.HandleReqOpts{
+			Mux:         testEnv.Mux,
+			URL:         url,
+			RawResponse: response,
+			Method:      http.MethodGet,
+			Status:      http.StatusOK,
+			CallFlag:    &called,
+		})
+	}
+}
+
+func newTestWatcher(t *testing.T, testEnv *testutils.TestEnv, opts watch.Opts) *watch.Watcher {
+	t.Helper()
+
+	testClientV1, err := clientv1.NewCRaaSClientV1(testutils.TokenID, testEnv.Server.URL+"/api/v1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	testClientV2, err := clientv2.NewCRaaSClientV2(testutils.TokenID, testEnv.Server.URL+"/api/v2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts.Now = func() time.Time { return testPollTime }
+	watcher, err := watch.NewWatcher(testClientV1, testClientV2, &opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return watcher
+}
+
+func TestPollResumesState(t *testing.T) {
+	testEnv := testutils.SetupTestEnv()
+	defer testEnv.TearDownTestEnv()
+	setupTestHandlers(t, testEnv)
+
+	statePath := filepath.Join(t.TempDir(), "state.json")
+	if err := os.WriteFile(statePath, []byte(testPreviousState), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	actual, err := newTestWatcher(t, testEnv, watch.Opts{StatePath: statePath}).Poll(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(expectedEvents, actual) {
+		t.Fatalf("expected %#v, but got %#v", expectedEvents, actual)
+	}
+
+	// A restarted watcher doesn't repeat events.
+	actual, err = newTestWatcher(t, testEnv, watch.Opts{StatePath: statePath}).Poll(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(actual) != 0 {
+		t.Fatalf("expected no events, but got %#v", actual)
+	}
+}
+
+func TestPollBaseline(t *testing.T) {
+	testEnv := testutils.SetupTestEnv()
+	defer testEnv.TearDownTestEnv()
+	setupTestHandlers(t, testEnv)
+
+	watcher := newTestWatcher(t, testEnv, watch.Opts{})
+	actual, err := watcher.Poll(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := expectedEvents[len(expectedEvents)-1:]
+	if !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("expected %#v, but got %#v", expected, actual)
+	}
+	if repos := watcher.State().Registries[testRegistryID].Repositories; len(repos) != 2 {
+		t.Fatalf("expected 2 repositories in the state, but got %#v", repos)
+	}
+}
+
+func TestPollOptionsChangeBaseline(t *testing.T) {
+	testEnv := testutils.SetupTestEnv()
+	defer testEnv.TearDownTestEnv()
+	setupTestHandlers(t, testEnv)
+
+	statePath := filepath.Join(t.TempDir(), "state.json")
+	ctx := context.Background()
+	if _, err := newTestWatcher(t, testEnv, watch.Opts{StatePath: statePath, SkipImages: true}).Poll(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	// Images missing from the saved state aren't reported as pushed.
+	actual, err := newTestWatcher(t, testEnv, watch.Opts{StatePath: statePath}).Poll(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(actual) != 0 {
+		t.Fatalf("expected no events, but got %#v", actual)
+	}
+}
@@ -0,0 +1,375 @@
+package watch
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	clientv1 "github.com/selectel/craas-go/pkg/v1/client"
+	"github.com/selectel/craas-go/pkg/v1/registry"
+	"github.com/selectel/craas-go/pkg/v1/repository"
+	clientv2 "github.com/selectel/craas-go/pkg/v2/client"
+	tokenv2 "github.com/selectel/craas-go/pkg/v2/token"
+)
+
+const (
+	// DefaultInterval is a delay between polls.
+	DefaultInterval = time.Minute
+
+	// DefaultExpiryWarning is a time before a token expiration when
+	// the TokenExpiringSoon event is sent.
+	DefaultExpiryWarning = 72 * time.Hour
+)
+
+// Opts represents options of the Watcher.
+type Opts struct {
+	// Interval is a delay between polls. DefaultInterval is used if not set.
+	Interval time.Duration
+
+	// StatePath is a file the state is loaded from and saved to after every
+	// poll. The state is kept in memory only if not set.
+	StatePath string
+
+	// SkipRepositories disables repository and image list requests.
+	SkipRepositories bool
+
+	// SkipImages disables image list requests.
+	SkipImages bool
+
+	// ExpiryWarning is a time before a token expiration when the
+	// TokenExpiringSoon event is sent. DefaultExpiryWarning is used if not set.
+	ExpiryWarning time.Duration
+
+	// OnError is called for every failed request.
+	OnError func(err error)
+
+	// Now returns the current time, time.Now is used by default.
+	Now func() time.Time
+}
+
+// Watcher polls resources and reports changes since the previous poll.
+type Watcher struct {
+	clientV1 *clientv1.ServiceClient
+	clientV2 *clientv2.ServiceClient
+	opts     Opts
+
+	// pollMu serializes polls, mu guards the state only, so State
+	// doesn't wait for requests of a poll in progress.
+	pollMu sync.Mutex
+	mu     sync.Mutex
+	state  *State
+}
+
+// NewWatcher returns a watcher using the v1 client for registries and the v2
+// client for tokens. Tokens aren't watched if the v2 client is nil.
+func NewWatcher(clientV1 *clientv1.ServiceClient, clientV2 *clientv2.ServiceClient, opts *Opts) (*Watcher, error) {
+	w := &Watcher{clientV1: clientV1, clientV2: clientV2}
+	if opts != nil {
+		w.opts = *opts
+	}
+	if w.opts.Interval <= 0 {
+		w.opts.Interval = DefaultInterval
+	}
+	if w.opts.ExpiryWarning <= 0 {
+		w.opts.ExpiryWarning = DefaultExpiryWarning
+	}
+	if w.opts.Now == nil {
+		w.opts.Now = time.Now
+	}
+	if w.opts.StatePath != "" {
+		state, err := LoadState(w.opts.StatePath)
+		if err != nil {
+			return nil, err
+		}
+		w.state = state
+	}
+
+	return w, nil
+}
+
+// State returns the state of the last successful poll or nil.
+func (w *Watcher) State() *State {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.state
+}
+
+// Watch polls resources every interval and sends events to the returned
+// channel. Failed polls are reported to OnError. The channel is closed when
+// the context is canceled.
+func (w *Watcher) Watch(ctx context.Context) <-chan *Event {
+	events := make(chan *Event)
+
+	go func() {
+		defer close(events)
+
+		timer := time.NewTimer(0)
+		defer timer.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-timer.C:
+			}
+
+			polled, err := w.Poll(ctx)
+			if err != nil && ctx.Err() == nil {
+				w.reportError(err)
+			}
+			for _, event := range polled {
+				select {
+				case events <- event:
+				case <-ctx.Done():
+					return
+				}
+			}
+			timer.Reset(w.opts.Interval)
+		}
+	}()
+
+	return events
+}
+
+// Poll lists resources once and returns changes since the previous poll.
+// Resources that can't be listed keep their previous state, so they don't
+// produce events, and the first such error is returned with the events.
+func (w *Watcher) Poll(ctx context.Context) ([]*Event, error) {
+	w.pollMu.Lock()
+	defer w.pollMu.Unlock()
+
+	prev := w.State()
+	p := &poll{
+		watcher: w,
+		prev:    prev,
+		next: &State{
+			PolledAt:         w.opts.Now().UTC(),
+			Registries:       make(map[string]*RegistryState),
+			Tokens:           make(map[string]*TokenState),
+			SkipRepositories: w.opts.SkipRepositories,
+			SkipImages:       w.opts.SkipImages,
+		},
+	}
+	p.baseline = prev == nil || prev.SkipRepositories != w.opts.SkipRepositories || prev.SkipImages != w.opts.SkipImages
+	if err := p.registries(ctx); err != nil {
+		return nil, err
+	}
+	p.tokens(ctx)
+
+	if w.opts.StatePath != "" {
+		if err := p.next.Save(w.opts.StatePath); err != nil {
+			return nil, err
+		}
+	}
+	w.mu.Lock()
+	w.state = p.next
+	w.mu.Unlock()
+
+	return p.events, p.err
+}
+
+func (w *Watcher) reportError(err error) {
+	if w.opts.OnError != nil {
+		w.opts.OnError(err)
+	}
+}
+
+// poll compares a new inventory with the previous state.
+type poll struct {
+	watcher *Watcher
+	prev    *State
+	next    *State
+	events  []*Event
+	err     error
+
+	// baseline is set if there is no previous state to compare with.
+	baseline bool
+}
+
+func (p *poll) emit(event *Event) {
+	// Changes aren't reported for the baseline poll.
+	if p.baseline && event.Type != EventTokenExpiringSoon {
+		return
+	}
+	event.Time = p.next.PolledAt
+	p.events = append(p.events, event)
+}
+
+func (p *poll) fail(err error) {
+	if p.err == nil {
+		p.err = err
+	}
+}
+
+func (p *poll) registries(ctx context.Context) error {
+	registries, _, err := registry.List(ctx, p.watcher.clientV1)
+	if err != nil {
+		return err
+	}
+
+	var prevRegistries map[string]*RegistryState
+	if p.prev != nil {
+		prevRegistries = p.prev.Registries
+	}
+	for _, r := range registries {
+		prev := prevRegistries[r.ID]
+		next := &RegistryState{Name: r.Name, Status: r.Status}
+		p.next.Registries[r.ID] = next
+
+		switch {
+		case prev == nil:
+			p.emit(&Event{Type: EventRegistryCreated, RegistryID: r.ID, RegistryName: r.Name, Status: r.Status})
+			prev = &RegistryState{}
+		case prev.Status != r.Status:
+			p.emit(&Event{
+				Type: EventRegistryStatusChanged, RegistryID: r.ID, RegistryName: r.Name,
+				Status: r.Status, PreviousStatus: prev.Status,
+			})
+		}
+		if !p.watcher.opts.SkipRepositories {
+			p.repositories(ctx, r, prev, next)
+		}
+	}
+	for _, id := range sortedKeys(prevRegistries) {
+		if _, ok := p.next.Registries[id]; !ok {
+			p.emit(&Event{Type: EventRegistryDeleted, RegistryID: id, RegistryName: prevRegistries[id].Name})
+		}
+	}
+
+	return nil
+}
+
+func (p *poll) repositories(ctx context.Context, r *registry.Registry, prev, next *RegistryState) {
+	repositories, _, err := repository.ListRepositories(ctx, p.watcher.clientV1, r.ID)
+	if err != nil {
+		p.fail(fmt.Errorf("registry %s: %w", r.Name, err))
+		next.Repositories = prev.Repositories
+
+		return
+	}
+
+	next.Repositories = make(map[string]*RepositoryState, len(repositories))
+	for _, repo := range repositories {
+		prevRepo := prev.Repositories[repo.Name]
+		if prevRepo == nil {
+			p.emit(&Event{Type: EventRepositoryAdded, RegistryID: r.ID, RegistryName: r.Name, Repository: repo.Name})
+			prevRepo = &RepositoryState{}
+		}
+		if p.watcher.opts.SkipImages {
+			next.Repositories[repo.Name] = &RepositoryState{}
+
+			continue
+		}
+
+		images, _, err := repository.ListImages(ctx, p.watcher.clientV1, r.ID, repo.Name)
+		if err != nil {
+			p.fail(fmt.Errorf("repository %s: %w", repo.Name, err))
+			next.Repositories[repo.Name] = prevRepo
+
+			continue
+		}
+		nextRepo := &RepositoryState{Images: make(map[string][]string, len(images))}
+		for _, image := range images {
+			tags := append([]string{}, image.Tags...)
+			sort.Strings(tags)
+			nextRepo.Images[image.Digest] = tags
+		}
+		next.Repositories[repo.Name] = nextRepo
+		p.images(r, repo.Name, prevRepo, nextRepo)
+	}
+	for _, name := range sortedKeys(prev.Repositories) {
+		if _, ok := next.Repositories[name]; !ok {
+			p.emit(&Event{Type: EventRepositoryDeleted, RegistryID: r.ID, RegistryName: r.Name, Repository: name})
+		}
+	}
+}
+
+func (p *poll) images(r *registry.Registry, repo string, prev, next *RepositoryState) {
+	for _, digest := range sortedKeys(next.Images) {
+		if _, ok := prev.Images[digest]; !ok {
+			p.emit(&Event{
+				Type: EventImagePushed, RegistryID: r.ID, RegistryName: r.Name, Repository: repo,
+				Digest: digest, Tags: next.Images[digest],
+			})
+		}
+	}
+
+	prevTags, nextTags := tagDigests(prev), tagDigests(next)
+	for _, tag := range sortedKeys(nextTags) {
+		if before, ok := prevTags[tag]; ok && before != nextTags[tag] {
+			p.emit(&Event{
+				Type: EventTagMoved, RegistryID: r.ID, RegistryName: r.Name, Repository: repo,
+				Tag: tag, Digest: nextTags[tag], PreviousDigest: before,
+			})
+		}
+	}
+
+	for _, digest := range sortedKeys(prev.Images) {
+		if _, ok := next.Images[digest]; !ok {
+			p.emit(&Event{
+				Type: EventImageDeleted, RegistryID: r.ID, RegistryName: r.Name, Repository: repo,
+				Digest: digest, Tags: prev.Images[digest],
+			})
+		}
+	}
+}
+
+func (p *poll) tokens(ctx context.Context) {
+	if p.watcher.clientV2 == nil {
+		return
+	}
+	tokens, err := tokenv2.ListAll(ctx, p.watcher.clientV2, tokenv2.Opts{})
+	if err != nil {
+		p.fail(fmt.Errorf("tokens: %w", err))
+		if p.prev != nil {
+			p.next.Tokens = p.prev.Tokens
+		}
+
+		return
+	}
+
+	now := p.next.PolledAt
+	for _, tkn := range tokenv2.FilterByStatus(tokens, tokenv2.StatusActive) {
+		if !tkn.Expiration.IsSet || tkn.IsExpired(now) {
+			continue
+		}
+		next := &TokenState{Name: tkn.Name, ExpiresAt: tkn.Expiration.ExpiresAt}
+		if p.prev != nil {
+			// A refreshed token is reported again before its new expiration.
+			if prev, ok := p.prev.Tokens[tkn.ID]; ok && prev.ExpiresAt.Equal(next.ExpiresAt) {
+				next.Notified = prev.Notified
+			}
+		}
+		if !next.Notified && next.ExpiresAt.Sub(now) <= p.watcher.opts.ExpiryWarning {
+			expiresAt := next.ExpiresAt
+			p.emit(&Event{Type: EventTokenExpiringSoon, TokenID: tkn.ID, TokenName: tkn.Name, ExpiresAt: &expiresAt})
+			next.Notified = true
+		}
+		p.next.Tokens[tkn.ID] = next
+	}
+}
+
+// tagDigests maps tags of the repository to digests.
+func tagDigests(repo *RepositoryState) map[string]string {
+	tags := make(map[string]string)
+	for digest, imageTags := range repo.Images {
+		for _, tag := range imageTags {
+			tags[tag] = digest
+		}
+	}
+
+	return tags
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
@@ -0,0 +1,43 @@
+/*
+Package `webhook` provides a set of functions for forwarding SDK events and
+reports to HTTP webhooks.
+
+A Notification is rendered as a JSON payload, as a Slack or Mattermost
+incoming-webhook message or with a custom template. Requests are signed with
+HMAC-SHA256 if a secret is set and are retried on network errors and 429 or
+5xx responses.
+
+Example of forwarding watch events to Slack:
+
+	hook := &webhook.Webhook{
+	    URL:    "https://hooks.slack.com/services/T000/B000/XXXX",
+	    Format: webhook.FormatSlack,
+	}
+	for event := range watcher.Watch(ctx) {
+	    err := hook.Send(ctx, webhook.FromEvent(event))
+	    if err != nil {
+	        log.Println(err)
+	    }
+	}
+
+Example of a signed JSON webhook with a templated body:
+
+	hook := &webhook.Webhook{
+	    URL:      "https://ci.example.com/hooks/craas",
+	    Secret:   os.Getenv("WEBHOOK_SECRET"),
+	    Template: `{"summary": {{json .Title}}, "kind": {{json .Kind}}}`,
+	}
+	err := hook.Send(ctx, webhook.GCFinished(registryAfterGC, sizeBefore, time.Now()))
+	if err != nil {
+	    log.Fatal(err)
+	}
+
+Example of verifying a signature in a receiver:
+
+	body, _ := io.ReadAll(r.Body)
+	if !webhook.Verify(secret, body, r.Header.Get(webhook.SignatureHeader)) {
+	    w.WriteHeader(http.StatusUnauthorized)
+	    return
+	}
+*/
+package webhook
@@ -0,0 +1,155 @@
+package webhook
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/selectel/craas-go/pkg/format"
+	"github.com/selectel/craas-go/pkg/v1/quota"
+	"github.com/selectel/craas-go/pkg/v1/registry"
+	tokenv2 "github.com/selectel/craas-go/pkg/v2/token"
+	"github.com/selectel/craas-go/pkg/v2/token/audit"
+	"github.com/selectel/craas-go/pkg/watch"
+)
+
+// FromEvent returns a notification of the watch event.
+func FromEvent(event *watch.Event) *Notification {
+	n := &Notification{Kind: KindEvent, Severity: SeverityInfo, Time: event.Time, Data: event}
+
+	subject := event.RegistryName
+	if event.Repository != "" {
+		subject += "/" + event.Repository
+	}
+	switch event.Type {
+	case watch.EventRegistryCreated:
+		n.Title = fmt.Sprintf("Registry %s created", subject)
+	case watch.EventRegistryStatusChanged:
+		n.Title = fmt.Sprintf("Registry %s is %s", subject, event.Status)
+		n.Text = fmt.Sprintf("Status changed from %s to %s.", event.PreviousStatus, event.Status)
+		if event.Status == registry.StatusError {
+			n.Severity = SeverityWarning
+		}
+	case watch.EventRegistryDeleted:
+		n.Title = fmt.Sprintf("Registry %s deleted", subject)
+		n.Severity = SeverityWarning
+	case watch.EventRepositoryAdded:
+		n.Title = fmt.Sprintf("Repository %s added", subject)
+	case watch.EventRepositoryDeleted:
+		n.Title = fmt.Sprintf("Repository %s deleted", subject)
+		n.Severity = SeverityWarning
+	case watch.EventImagePushed:
+		n.Title = fmt.Sprintf("Image pushed to %s", subject)
+		n.Text = imageText(event)
+	case watch.EventImageDeleted:
+		n.Title = fmt.Sprintf("Image deleted from %s", subject)
+		n.Text = imageText(event)
+	case watch.EventTagMoved:
+		n.Title = fmt.Sprintf("Tag %s:%s moved", subject, event.Tag)
+		n.Text = fmt.Sprintf("%s → %s", event.PreviousDigest, event.Digest)
+	case watch.EventTokenExpiringSoon:
+		n.Title = fmt.Sprintf("Token %s expires soon", event.TokenName)
+		n.Text = fmt.Sprintf("The token expires at %s.", event.ExpiresAt.UTC().Format(time.RFC3339))
+		n.Severity = SeverityWarning
+	default:
+		n.Title = string(event.Type)
+	}
+
+	return n
+}
+
+func imageText(event *watch.Event) string {
+	if len(event.Tags) == 0 {
+		return event.Digest
+	}
+
+	return fmt.Sprintf("%s (%s)", event.Digest, strings.Join(event.Tags, ", "))
+}
+
+// GCFinished returns a notification of a finished garbage collection.
+// The registry is expected to be requested after the collection.
+func GCFinished(r *registry.Registry, sizeBefore int64, now time.Time) *Notification {
+	data := &GCData{
+		RegistryID: r.ID,
+		Registry:   r.Name,
+		SizeBefore: sizeBefore,
+		SizeAfter:  r.Size,
+		Freed:      sizeBefore - r.Size,
+	}
+
+	return &Notification{
+		Kind:     KindGCFinished,
+		Severity: SeverityInfo,
+		Time:     now,
+		Title:    fmt.Sprintf("Garbage collection of %s finished", r.Name),
+		Text: fmt.Sprintf("Freed %s, the registry size is %s.",
+			format.HumanSize(data.Freed), format.HumanSize(data.SizeAfter)),
+		Data: data,
+	}
+}
+
+// QuotaFinding returns a notification of a quota check finding,
+// the text lists exceeded percent and headroom thresholds.
+func QuotaFinding(f *quota.Finding, now time.Time) *Notification {
+	data := &QuotaData{
+		RegistryID:  f.RegistryID,
+		Registry:    f.Registry,
+		Size:        f.Size,
+		SizeLimit:   f.SizeLimit,
+		UsedPercent: f.UsedPercent,
+		Level:       string(f.Level),
+		Reasons:     f.Reasons,
+	}
+
+	return &Notification{
+		Kind:     KindQuotaThreshold,
+		Severity: SeverityWarning,
+		Time:     now,
+		Title:    fmt.Sprintf("Registry %s is %.1f%% full", f.Registry, f.UsedPercent),
+		Text: fmt.Sprintf("%s of %s is used, %s.",
+			format.HumanSize(f.Size), format.HumanSize(f.SizeLimit), strings.Join(f.Reasons, ", ")),
+		Data: data,
+	}
+}
+
+// TokenExpiring returns a notification of a token expiring soon.
+// The token secret isn't included.
+func TokenExpiring(tkn *tokenv2.TokenV2, now time.Time) *Notification {
+	data := &TokenData{ID: tkn.ID, Name: tkn.Name, ExpiresAt: tkn.Expiration.ExpiresAt}
+
+	return &Notification{
+		Kind:     KindTokenExpiring,
+		Severity: SeverityWarning,
+		Time:     now,
+		Title:    fmt.Sprintf("Token %s expires %s", tkn.Name, format.RelativeTime(data.ExpiresAt, now)),
+		Text:     fmt.Sprintf("The token expires at %s.", data.ExpiresAt.UTC().Format(time.RFC3339)),
+		Data:     data,
+	}
+}
+
+// FromAuditReport returns a notification listing tokens with findings.
+func FromAuditReport(report *audit.Report) *Notification {
+	n := &Notification{
+		Kind:     KindTokenAudit,
+		Severity: SeverityInfo,
+		Time:     report.GeneratedAt,
+		Title:    fmt.Sprintf("%d of %d tokens have audit findings", len(report.Entries), report.TotalTokens),
+		Data:     report,
+	}
+	if len(report.Entries) == 0 {
+		return n
+	}
+
+	n.Severity = SeverityWarning
+	lines := make([]string, 0, len(report.Entries))
+	for _, entry := range report.Entries {
+		findings := make([]string, 0, len(entry.Findings))
+		for _, finding := range entry.Findings {
+			findings = append(findings, string(finding))
+		}
+		lines = append(lines, fmt.Sprintf("%s: %s", entry.Token.Name, strings.Join(findings, ", ")))
+	}
+	n.Text = strings.Join(lines, "\n")
+
+	return n
+}
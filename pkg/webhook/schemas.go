@@ -0,0 +1,69 @@
+package webhook
+
+import (
+	"time"
+)
+
+// Kind represents a kind of notification.
+type Kind string
+
+const (
+	KindEvent          Kind = "event"
+	KindGCFinished     Kind = "gc.finished"
+	KindQuotaThreshold Kind = "quota.threshold"
+	KindTokenExpiring  Kind = "token.expiring"
+	KindTokenAudit     Kind = "token.audit"
+)
+
+// Severity represents an importance of notification.
+type Severity string
+
+const (
+	SeverityInfo    Severity = "info"
+	SeverityWarning Severity = "warning"
+)
+
+// Notification represents a message sent to webhooks.
+type Notification struct {
+	Kind     Kind      `json:"kind"`
+	Severity Severity  `json:"severity"`
+	Time     time.Time `json:"time"`
+
+	// Title is a short summary of the notification.
+	Title string `json:"title"`
+
+	// Text is an optional multi-line description.
+	Text string `json:"text,omitempty"`
+
+	// Data is a source of the notification, such as a watch.Event or GCData.
+	Data interface{} `json:"data,omitempty"`
+}
+
+// GCData represents a finished garbage collection.
+type GCData struct {
+	RegistryID string `json:"registryId"`
+	Registry   string `json:"registry"`
+	SizeBefore int64  `json:"sizeBefore"`
+	SizeAfter  int64  `json:"sizeAfter"`
+	Freed      int64  `json:"freed"`
+}
+
+// QuotaData represents a registry storage usage above quota check thresholds.
+type QuotaData struct {
+	RegistryID  string  `json:"registryId"`
+	Registry    string  `json:"registry"`
+	Size        int64   `json:"size"`
+	SizeLimit   int64   `json:"sizeLimit"`
+	UsedPercent float64 `json:"usedPercent"`
+
+	// Level and Reasons describe the exceeded thresholds.
+	Level   string   `json:"level,omitempty"`
+	Reasons []string `json:"reasons,omitempty"`
+}
+
+// TokenData represents an expiring token without its secret.
+type TokenData struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
@@ -0,0 +1,39 @@
+package testing
+
+import (
+	"time"
+
+	"github.com/selectel/craas-go/pkg/v1/quota"
+	"github.com/selectel/craas-go/pkg/watch"
+)
+
+const testSecret = "s3cr3t"
+
+var testNow = time.Date(2023, 3, 2, 12, 0, 0, 0, time.UTC)
+
+var testEvent = &watch.Event{
+	Type:           watch.EventTagMoved,
+	Time:           testNow,
+	RegistryID:     "fc43e322-b084-4b3c-a04a-1ab2a28cd860",
+	RegistryName:   "test-registry",
+	Repository:     "nginx",
+	Tag:            "latest",
+	Digest:         "sha256:bbbb",
+	PreviousDigest: "sha256:aaaa",
+}
+
+const expectedSlackBody = `{"text":"*Tag test-registry/nginx:latest moved*\nsha256:aaaa → sha256:bbbb","channel":"#registry"}`
+
+var testQuotaFinding = &quota.Finding{
+	RegistryID:  "fc43e322-b084-4b3c-a04a-1ab2a28cd860",
+	Registry:    "test-registry",
+	Level:       quota.LevelCritical,
+	Reasons:     []string{"headroom 1.0 GiB is below 2.0 GiB"},
+	Size:        9 * 1024 * 1024 * 1024,
+	SizeLimit:   10 * 1024 * 1024 * 1024,
+	UsedPercent: 90,
+}
+
+const testQuotaTemplate = `{"registry": {{json .Data.Registry}}, "used": "{{printf "%.0f" .Data.UsedPercent}}%", "size": "{{humanSize .Data.Size}}"}`
+
+const expectedQuotaBody = `{"registry": "test-registry", "used": "90%", "size": "9.0 GiB"}`
@@ -0,0 +1,126 @@
+package testing
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/selectel/craas-go/pkg/v1/quota"
+	"github.com/selectel/craas-go/pkg/webhook"
+)
+
+// receiver records requests and responds with the given statuses in order.
+type receiver struct {
+	mu       sync.Mutex
+	statuses []int
+	bodies   []string
+	headers  []http.Header
+}
+
+func (r *receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, _ := io.ReadAll(req.Body)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.bodies = append(r.bodies, string(body))
+	r.headers = append(r.headers, req.Header.Clone())
+	status := http.StatusOK
+	if len(r.statuses) > 0 {
+		status, r.statuses = r.statuses[0], r.statuses[1:]
+	}
+	w.WriteHeader(status)
+}
+
+func TestSendSlackSigned(t *testing.T) {
+	recv := &receiver{}
+	server := httptest.NewServer(recv)
+	defer server.Close()
+
+	hook := &webhook.Webhook{
+		URL:     server.URL,
+		Format:  webhook.FormatSlack,
+		Secret:  testSecret,
+		Channel: "#registry",
+	}
+	if err := hook.Send(context.Background(), webhook.FromEvent(testEvent)); err != nil {
+		t.Fatal(err)
+	}
+	if len(recv.bodies) != 1 {
+		t.Fatalf("expected 1 request, but got %d", len(recv.bodies))
+	}
+	if recv.bodies[0] != expectedSlackBody {
+		t.Fatalf("expected %q, but got %q", expectedSlackBody, recv.bodies[0])
+	}
+	signature := recv.headers[0].Get(webhook.SignatureHeader)
+	if !webhook.Verify(testSecret, []byte(recv.bodies[0]), signature) {
+		t.Fatalf("invalid signature %q", signature)
+	}
+	if webhook.Verify("other", []byte(recv.bodies[0]), signature) {
+		t.Fatal("expected the signature to be invalid for another secret")
+	}
+}
+
+func TestSendTemplate(t *testing.T) {
+	recv := &receiver{}
+	server := httptest.NewServer(recv)
+	defer server.Close()
+
+	hook := &webhook.Webhook{URL: server.URL, Template: testQuotaTemplate}
+	if err := hook.Send(context.Background(), webhook.QuotaFinding(testQuotaFinding, testNow)); err != nil {
+		t.Fatal(err)
+	}
+	if recv.bodies[0] != expectedQuotaBody {
+		t.Fatalf("expected %q, but got %q", expectedQuotaBody, recv.bodies[0])
+	}
+	if kind := recv.headers[0].Get(webhook.KindHeader); kind != string(webhook.KindQuotaThreshold) {
+		t.Fatalf("expected %q kind, but got %q", webhook.KindQuotaThreshold, kind)
+	}
+}
+
+func TestQuotaFinding(t *testing.T) {
+	n := webhook.QuotaFinding(testQuotaFinding, testNow)
+
+	expected := "9.0 GiB of 10.0 GiB is used, headroom 1.0 GiB is below 2.0 GiB."
+	if n.Text != expected {
+		t.Fatalf("expected %q, but got %q", expected, n.Text)
+	}
+	data, ok := n.Data.(*webhook.QuotaData)
+	if !ok || data.Level != string(quota.LevelCritical) {
+		t.Fatalf("unexpected quota data %#v", n.Data)
+	}
+}
+
+func TestSendRetries(t *testing.T) {
+	recv := &receiver{statuses: []int{http.StatusServiceUnavailable, http.StatusTooManyRequests}}
+	server := httptest.NewServer(recv)
+	defer server.Close()
+
+	hook := &webhook.Webhook{URL: server.URL, MinBackoff: time.Millisecond}
+	if err := hook.Send(context.Background(), webhook.FromEvent(testEvent)); err != nil {
+		t.Fatal(err)
+	}
+	if len(recv.bodies) != 3 {
+		t.Fatalf("expected 3 requests, but got %d", len(recv.bodies))
+	}
+}
+
+func TestSendDoesNotRetryClientErrors(t *testing.T) {
+	recv := &receiver{statuses: []int{http.StatusBadRequest}}
+	server := httptest.NewServer(recv)
+	defer server.Close()
+
+	hook := &webhook.Webhook{URL: server.URL, MinBackoff: time.Millisecond}
+	err := hook.Send(context.Background(), webhook.FromEvent(testEvent))
+	if !errors.Is(err, webhook.ErrUnexpectedStatus) {
+		t.Fatalf("expected %v, but got %v", webhook.ErrUnexpectedStatus, err)
+	}
+	if len(recv.bodies) != 1 {
+		t.Fatalf("expected 1 request, but got %d", len(recv.bodies))
+	}
+}
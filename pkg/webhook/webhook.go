@@ -0,0 +1,274 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/selectel/craas-go/pkg/format"
+	"github.com/selectel/craas-go/pkg/svc"
+)
+
+const (
+	// SignatureHeader contains the HMAC-SHA256 signature of the body
+	// in the "sha256=<hex>" form.
+	SignatureHeader = "X-CRaaS-Signature"
+
+	// KindHeader contains the kind of the notification.
+	KindHeader = "X-CRaaS-Kind"
+
+	// DefaultMaxAttempts is a number of attempts to deliver a notification.
+	DefaultMaxAttempts = 3
+
+	// DefaultMinBackoff is a delay before the first retry.
+	DefaultMinBackoff = time.Second
+
+	// maxBackoff limits delays between retries.
+	maxBackoff = 30 * time.Second
+
+	// signaturePrefix is a prefix of the signature header value.
+	signaturePrefix = "sha256="
+)
+
+var (
+	ErrURLEmpty          = errors.New("webhook URL is empty")
+	ErrUnsupportedFormat = errors.New("unsupported webhook format")
+	ErrUnexpectedStatus  = errors.New("unexpected webhook response status")
+)
+
+// Format represents a payload format of the webhook.
+type Format string
+
+const (
+	// FormatJSON sends the Notification as JSON.
+	FormatJSON Format = "json"
+
+	// FormatSlack sends a Slack incoming-webhook message.
+	FormatSlack Format = "slack"
+
+	// FormatMattermost sends a Mattermost incoming-webhook message.
+	FormatMattermost Format = "mattermost"
+)
+
+// Formats returns supported payload formats.
+func Formats() []Format {
+	return []Format{FormatJSON, FormatSlack, FormatMattermost}
+}
+
+// Webhook represents an HTTP endpoint receiving notifications.
+type Webhook struct {
+	// URL is an address the notifications are posted to.
+	URL string
+
+	// Format is a payload format, FormatJSON is used if not set.
+	Format Format
+
+	// Template is a text/template of the body executed with the Notification.
+	// It replaces the payload of the format if set.
+	Template string
+
+	// Secret is a key of the HMAC-SHA256 signature sent in SignatureHeader.
+	// Requests aren't signed if it's empty.
+	Secret string
+
+	// Headers are added to every request.
+	Headers map[string]string
+
+	// Channel and Username override defaults of Slack and Mattermost webhooks.
+	Channel  string
+	Username string
+
+	// MaxAttempts is a number of delivery attempts, DefaultMaxAttempts is used if not set.
+	MaxAttempts int
+
+	// MinBackoff is a delay before the first retry, it's doubled for every
+	// next one. DefaultMinBackoff is used if not set.
+	MinBackoff time.Duration
+
+	// HTTPClient is used to send requests, svc.NewHTTPClient is used if not set.
+	HTTPClient *http.Client
+}
+
+// chatMessage represents a Slack or Mattermost incoming-webhook message.
+type chatMessage struct {
+	Text     string `json:"text"`
+	Channel  string `json:"channel,omitempty"`
+	Username string `json:"username,omitempty"`
+}
+
+// Body renders the payload of the notification.
+func (w *Webhook) Body(n *Notification) ([]byte, error) {
+	if w.Template != "" {
+		tmpl, err := template.New("webhook").Funcs(templateFuncs).Parse(w.Template)
+		if err != nil {
+			return nil, err
+		}
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, n); err != nil {
+			return nil, err
+		}
+
+		return buf.Bytes(), nil
+	}
+
+	switch w.Format {
+	case "", FormatJSON:
+		return json.Marshal(n)
+	case FormatSlack:
+		return json.Marshal(&chatMessage{Text: messageText(n, "*"), Channel: w.Channel, Username: w.Username})
+	case FormatMattermost:
+		return json.Marshal(&chatMessage{Text: messageText(n, "**"), Channel: w.Channel, Username: w.Username})
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedFormat, w.Format)
+	}
+}
+
+// messageText returns the title in bold followed by the text.
+func messageText(n *Notification, bold string) string {
+	text := bold + n.Title + bold
+	if n.Severity == SeverityWarning {
+		text = ":warning: " + text
+	}
+	if n.Text != "" {
+		text += "\n" + n.Text
+	}
+
+	return text
+}
+
+var templateFuncs = template.FuncMap{
+	"json": func(v interface{}) (string, error) {
+		data, err := json.Marshal(v)
+
+		return string(data), err
+	},
+	"humanSize": format.HumanSize,
+	"upper":     strings.ToUpper,
+	"lower":     strings.ToLower,
+	"rfc3339": func(t time.Time) string {
+		return t.UTC().Format(time.RFC3339)
+	},
+}
+
+// Send posts the notification and retries it on network errors and
+// 429 or 5xx responses.
+func (w *Webhook) Send(ctx context.Context, n *Notification) error {
+	if w.URL == "" {
+		return ErrURLEmpty
+	}
+	body, err := w.Body(n)
+	if err != nil {
+		return err
+	}
+
+	attempts := w.MaxAttempts
+	if attempts <= 0 {
+		attempts = DefaultMaxAttempts
+	}
+	for attempt := 1; ; attempt++ {
+		retryAfter, err := w.post(ctx, n, body)
+		if err == nil || attempt >= attempts || retryAfter < 0 || ctx.Err() != nil {
+			return err
+		}
+
+		timer := time.NewTimer(w.backoff(attempt, retryAfter))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// post sends a single request. It returns a negative delay if the request
+// shouldn't be retried and a Retry-After delay or zero otherwise.
+func (w *Webhook) post(ctx context.Context, n *Notification, body []byte) (time.Duration, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		return -1, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(KindHeader, string(n.Kind))
+	if w.Secret != "" {
+		req.Header.Set(SignatureHeader, Sign(w.Secret, body))
+	}
+	for name, value := range w.Headers {
+		req.Header.Set(name, value)
+	}
+
+	httpClient := w.HTTPClient
+	if httpClient == nil {
+		httpClient = svc.NewHTTPClient()
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		_, _ = io.Copy(io.Discard, resp.Body)
+
+		return 0, nil
+	}
+
+	message, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	err = fmt.Errorf("%w: %d %s", ErrUnexpectedStatus, resp.StatusCode, strings.TrimSpace(string(message)))
+	if resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode < 500 {
+		return -1, err
+	}
+	if seconds, parseErr := strconv.Atoi(resp.Header.Get("Retry-After")); parseErr == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second, err
+	}
+
+	return 0, err
+}
+
+// backoff returns a delay before the next attempt.
+func (w *Webhook) backoff(attempt int, retryAfter time.Duration) time.Duration {
+	delay := retryAfter
+	if delay == 0 {
+		delay = w.MinBackoff
+		if delay <= 0 {
+			delay = DefaultMinBackoff
+		}
+		for i := 1; i < attempt && delay < maxBackoff; i++ {
+			delay *= 2
+		}
+	}
+	if delay > maxBackoff {
+		delay = maxBackoff
+	}
+
+	return delay
+}
+
+// Sign returns the HMAC-SHA256 signature of the body in the "sha256=<hex>" form.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify reports whether the signature of the body is valid.
+func Verify(secret string, body []byte, signature string) bool {
+	if !strings.HasPrefix(signature, signaturePrefix) {
+		return false
+	}
+
+	return hmac.Equal([]byte(Sign(secret, body)), []byte(signature))
+}